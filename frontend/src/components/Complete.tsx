@@ -1,5 +1,5 @@
 import React, { useState } from 'react';
-import { OpenTerminal, OpenURL } from '../../wailsjs/go/main/App';
+import { ConfigureGitCredentialManager, OpenTerminal, OpenURL } from '../../wailsjs/go/main/App';
 import { Quit } from '../../wailsjs/runtime/runtime';
 import { type Locale, type SystemCheckResult, t } from '../hooks/useInstaller';
 
@@ -64,6 +64,22 @@ const translations = {
     ko: '터미널을 여는데 실패했습니다',
     en: 'Failed to open terminal',
   },
+  gitCredentialsPrompt: {
+    ko: 'Claude Code가 GitHub에 푸시하려면 Git 인증이 필요합니다',
+    en: 'Claude Code needs Git credentials to push to GitHub',
+  },
+  configureGitCredentials: {
+    ko: 'Git Credential Manager 설정',
+    en: 'Set up Git Credential Manager',
+  },
+  gitCredentialsConfigured: {
+    ko: 'Git Credential Manager가 설정되었습니다',
+    en: 'Git Credential Manager configured',
+  },
+  errorGitCredentials: {
+    ko: 'Git Credential Manager를 설정하지 못했습니다',
+    en: 'Failed to set up Git Credential Manager',
+  },
   errorOpenLink: {
     ko: '링크를 여는데 실패했습니다',
     en: 'Failed to open link',
@@ -137,6 +153,21 @@ const SoftwareVersionRow: React.FC<{
 const Complete: React.FC<CompleteProps> = ({ locale, systemCheck }) => {
   const [terminalError, setTerminalError] = useState<string>('');
   const [linkError, setLinkError] = useState<string>('');
+  const [gitCredentialsState, setGitCredentialsState] = useState<'idle' | 'configured' | 'error'>('idle');
+
+  // Offered when no credential helper was found; Git installed by this run
+  // may already have configured one, in which case this only re-asserts it
+  const offerGitCredentials = !!systemCheck && !systemCheck.gitCredentials?.helper;
+
+  const handleConfigureGitCredentials = async () => {
+    try {
+      await ConfigureGitCredentialManager();
+      setGitCredentialsState('configured');
+    } catch (err) {
+      console.error('Failed to configure Git Credential Manager:', err);
+      setGitCredentialsState('error');
+    }
+  };
 
   const handleOpenTerminal = async () => {
     try {
@@ -210,6 +241,32 @@ const Complete: React.FC<CompleteProps> = ({ locale, systemCheck }) => {
         </div>
       )}
 
+      {/* Git Credentials */}
+      {offerGitCredentials && (
+        <div
+          className="w-full max-w-md p-4 rounded-lg border border-white/5 bg-white/[0.02] mb-4 opacity-0 animate-fade-in-up"
+          style={{ animationDelay: '200ms' }}
+        >
+          <p className="text-xs text-white/50 mb-3">
+            {t(translations, 'gitCredentialsPrompt', locale)}
+          </p>
+          {gitCredentialsState === 'configured' ? (
+            <p className="text-xs text-emerald-400">
+              {t(translations, 'gitCredentialsConfigured', locale)}
+            </p>
+          ) : (
+            <button onClick={handleConfigureGitCredentials} className="btn-secondary w-full">
+              {t(translations, 'configureGitCredentials', locale)}
+            </button>
+          )}
+          {gitCredentialsState === 'error' && (
+            <p className="text-xs text-red-400 mt-2 ml-1">
+              {t(translations, 'errorGitCredentials', locale)}
+            </p>
+          )}
+        </div>
+      )}
+
       {/* Useful Links */}
       <div
         className="w-full max-w-md p-5 rounded-lg border border-white/5 bg-white/[0.02] mb-6 opacity-0 animate-fade-in-up"
@@ -257,6 +257,19 @@ const Installing: React.FC<InstallingProps> = ({
       onProgressUpdate(data);
       onAddLog(`[${data.step}] ${data.status}: ${data.message}`);
     });
+    const unsubscribeDetail = EventsOn('install:detail', (data: { step: string; message: string }) => {
+      onAddLog(`[${data.step}] detail: ${data.message}`);
+    });
+    const unsubscribeError = EventsOn('install:error', (data: { step: string; code: string; remediation: string }) => {
+      onAddLog(`[${data.step}] ${data.code}: ${data.remediation}`);
+    });
+    const unsubscribeErrorDetail = EventsOn('install:errorDetail', (data: { step: string; command: string; cause: string; output: string; logPath?: string }) => {
+      onAddLog(`[${data.step}] ${data.cause}`);
+      onAddLog(`[${data.step}] $ ${data.command}\n${data.output}`);
+      if (data.logPath) {
+        onAddLog(`[${data.step}] npm debug log: ${data.logPath}`);
+      }
+    });
 
     // Start installation
     if (!installStarted) {
@@ -270,6 +283,9 @@ const Installing: React.FC<InstallingProps> = ({
 
     return () => {
       unsubscribe();
+      unsubscribeDetail();
+      unsubscribeError();
+      unsubscribeErrorDetail();
     };
     // eslint-disable-next-line react-hooks/exhaustive-deps
   }, []);
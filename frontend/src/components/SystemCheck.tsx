@@ -1,5 +1,5 @@
 import React, { useEffect, useState } from 'react';
-import { CheckSystem } from '../../wailsjs/go/main/App';
+import { CheckSystem, GetProjectNodeRequirement } from '../../wailsjs/go/main/App';
 import {
   type Locale,
   type SystemCheckResult,
@@ -35,6 +35,22 @@ const translations = {
     ko: 'winget 사용 불가',
     en: 'winget unavailable',
   },
+  wingetNotFound: {
+    ko: '설치되어 있지 않음',
+    en: 'not installed',
+  },
+  wingetVersionFailed: {
+    ko: '실행 실패',
+    en: 'failed to run',
+  },
+  wingetPolicyBlocked: {
+    ko: '그룹 정책으로 차단됨',
+    en: 'blocked by Group Policy',
+  },
+  wingetSourcesUnavailable: {
+    ko: '사용 가능한 소스 없음',
+    en: 'no sources available',
+  },
   skipMessage: {
     ko: '이미 설치된 항목은 건너뜁니다',
     en: 'Already installed items will be skipped',
@@ -59,6 +75,30 @@ const translations = {
     ko: '다시 확인',
     en: 'Retry Check',
   },
+  projectExpectsNode: {
+    ko: '이 프로젝트는 Node.js {version} 버전을 요구합니다',
+    en: 'This project expects Node.js {version}',
+  },
+  networkLocation: {
+    ko: '네트워크 위치에서 실행 중입니다. 로컬 폴더로 복사한 후 실행하세요',
+    en: 'Running from a network location. Copy the installer to a local folder and run it from there',
+  },
+  readOnlyLocation: {
+    ko: '읽기 전용 위치에서 실행 중이어서 자동 업데이트를 할 수 없습니다',
+    en: 'Running from a read-only location, so the installer cannot update itself',
+  },
+  claudeShadowed: {
+    ko: '다른 프로그램({path})이 Claude Code보다 먼저 claude로 실행됩니다',
+    en: 'Another program ({path}) runs instead of Claude Code when you type claude',
+  },
+  claudeAliased: {
+    ko: '셸 별칭이 claude 명령을 재정의할 수 있습니다',
+    en: 'A shell alias may redefine the claude command',
+  },
+  claudePackagesConflict: {
+    ko: '여러 전역 npm 패키지가 claude 명령을 제공합니다 ({packages}). 필요 없는 패키지를 제거하세요',
+    en: 'Several global npm packages provide a claude command ({packages}). Uninstall the ones that are not needed',
+  },
   errorChecking: {
     ko: '시스템 확인 중 오류가 발생했습니다',
     en: 'Error occurred while checking system',
@@ -85,6 +125,13 @@ interface SystemCheckProps {
   onSystemCheckComplete: (result: SystemCheckResult) => void;
 }
 
+const wingetReasonKeys = {
+  'not-found': 'wingetNotFound',
+  'version-failed': 'wingetVersionFailed',
+  'policy-blocked': 'wingetPolicyBlocked',
+  'sources-unavailable': 'wingetSourcesUnavailable',
+} as const;
+
 const StatusIcon: React.FC<{ installed: boolean }> = ({ installed }) =>
   installed ? (
     <svg width="16" height="16" viewBox="0 0 16 16" fill="none" className="flex-shrink-0" role="img" aria-label="Installed">
@@ -161,6 +208,7 @@ const SystemCheck: React.FC<SystemCheckProps> = ({
   const [loading, setLoading] = useState(true);
   const [result, setResult] = useState<SystemCheckResult | null>(null);
   const [error, setError] = useState<string | null>(null);
+  const [projectNode, setProjectNode] = useState<{ requirement: string; satisfied: boolean } | null>(null);
 
   const runCheck = async () => {
     setLoading(true);
@@ -169,6 +217,10 @@ const SystemCheck: React.FC<SystemCheckProps> = ({
       const checkResult = await CheckSystem();
       setResult(checkResult);
       onSystemCheckComplete(checkResult);
+      // Informational only: a missing or unreadable project file is not an error
+      GetProjectNodeRequirement()
+        .then((requirement) => setProjectNode(requirement))
+        .catch(() => setProjectNode(null));
     } catch (err: any) {
       setError(err?.message || t(translations, 'errorChecking', locale));
     } finally {
@@ -176,6 +228,9 @@ const SystemCheck: React.FC<SystemCheckProps> = ({
     }
   };
 
+  // winget runs and can install from its sources; older results lack winget
+  const wingetUsable = !!result?.wingetAvailable && (result?.winget?.sourcesAvailable ?? true);
+
   useEffect(() => {
     runCheck();
     // eslint-disable-next-line react-hooks/exhaustive-deps
@@ -245,16 +300,84 @@ const SystemCheck: React.FC<SystemCheckProps> = ({
             >
               <div
                 className={`w-1.5 h-1.5 rounded-full ${
-                  result.wingetAvailable ? 'bg-emerald-400' : 'bg-yellow-400'
+                  wingetUsable ? 'bg-emerald-400' : 'bg-yellow-400'
                 }`}
               />
               <span className="text-xs text-white/40">
-                {result.wingetAvailable
+                {wingetUsable
                   ? t(translations, 'wingetAvailable', locale)
                   : t(translations, 'wingetUnavailable', locale)}
+                {!wingetUsable && result.winget?.reason && (
+                  <span title={result.winget.detail}>
+                    {' ('}
+                    {t(translations, wingetReasonKeys[result.winget.reason], locale)}
+                    {')'}
+                  </span>
+                )}
               </span>
             </div>
 
+            {/* Project Node.js Requirement */}
+            {projectNode && (
+              <div
+                className="flex items-center gap-2 px-4 py-2 opacity-0 animate-fade-in-up"
+                style={{ animationDelay: '225ms' }}
+              >
+                <div
+                  className={`w-1.5 h-1.5 rounded-full ${
+                    projectNode.satisfied ? 'bg-emerald-400' : 'bg-yellow-400'
+                  }`}
+                />
+                <span className="text-xs text-white/40">
+                  {t(translations, 'projectExpectsNode', locale).replace('{version}', projectNode.requirement)}
+                </span>
+              </div>
+            )}
+
+            {/* Installer Location Advisory */}
+            {result.executableLocation?.warning && (
+              <div
+                className="flex items-center gap-2 px-4 py-2 opacity-0 animate-fade-in-up"
+                style={{ animationDelay: '235ms' }}
+              >
+                <div className="w-1.5 h-1.5 rounded-full bg-yellow-400" />
+                <span className="text-xs text-white/40" title={result.executableLocation.path}>
+                  {t(translations, result.executableLocation.network ? 'networkLocation' : 'readOnlyLocation', locale)}
+                </span>
+              </div>
+            )}
+
+            {/* Claude Shadowing Advisory */}
+            {(result.claudeShadowing?.path || result.claudeShadowing?.alias) && (
+              <div
+                className="flex items-center gap-2 px-4 py-2 opacity-0 animate-fade-in-up"
+                style={{ animationDelay: '240ms' }}
+              >
+                <div className="w-1.5 h-1.5 rounded-full bg-yellow-400" />
+                <span className="text-xs text-white/40" title={result.claudeShadowing.alias}>
+                  {result.claudeShadowing.path
+                    ? t(translations, 'claudeShadowed', locale).replace('{path}', result.claudeShadowing.path)
+                    : t(translations, 'claudeAliased', locale)}
+                </span>
+              </div>
+            )}
+
+            {/* Conflicting claude Packages Advisory */}
+            {result.claudeShadowing?.packages && result.claudeShadowing.packages.length > 1 && (
+              <div
+                className="flex items-center gap-2 px-4 py-2 opacity-0 animate-fade-in-up"
+                style={{ animationDelay: '245ms' }}
+              >
+                <div className="w-1.5 h-1.5 rounded-full bg-yellow-400" />
+                <span className="text-xs text-white/40" title={result.claudeShadowing.winner}>
+                  {t(translations, 'claudePackagesConflict', locale).replace(
+                    '{packages}',
+                    result.claudeShadowing.packages.join(', '),
+                  )}
+                </span>
+              </div>
+            )}
+
             {/* Skip / Info Message */}
             <div
               className="mt-4 opacity-0 animate-fade-in-up"
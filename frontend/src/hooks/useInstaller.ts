@@ -9,11 +9,45 @@ export interface SoftwareStatus {
   required: boolean;
 }
 
+export interface WingetStatus {
+  available: boolean;
+  version?: string;
+  sourcesAvailable: boolean;
+  reason?: 'not-found' | 'version-failed' | 'policy-blocked' | 'sources-unavailable';
+  detail?: string;
+}
+
 export interface SystemCheckResult {
   nodejs: SoftwareStatus;
   git: SoftwareStatus;
   claudeCode: SoftwareStatus;
   wingetAvailable: boolean;
+  winget?: WingetStatus;
+  executableLocation?: ExecutableLocation;
+  gitCredentials?: GitCredentialStatus;
+  claudeShadowing?: ClaudeShadowing;
+}
+
+export interface ClaudeShadowing {
+  path?: string;
+  claudeCodePath?: string;
+  alias?: string;
+  packages?: string[];
+  winner?: string;
+}
+
+export interface GitCredentialStatus {
+  helper?: string;
+  usesManager: boolean;
+  managerInstalled: boolean;
+  managerVersion?: string;
+}
+
+export interface ExecutableLocation {
+  path: string;
+  writable: boolean;
+  network: boolean;
+  warning?: string;
 }
 
 export interface InstallProgress {
@@ -3,12 +3,14 @@ module claude-code-installer
 go 1.22.0
 
 require (
+	github.com/ProtonMail/go-crypto v1.3.0
 	github.com/wailsapp/wails/v2 v2.11.0
 	golang.org/x/sys v0.30.0
 )
 
 require (
 	github.com/bep/debounce v1.2.1 // indirect
+	github.com/cloudflare/circl v1.6.0 // indirect
 	github.com/go-ole/go-ole v1.3.0 // indirect
 	github.com/godbus/dbus/v5 v5.1.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
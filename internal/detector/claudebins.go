@@ -0,0 +1,112 @@
+package detector
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// maxShimSize bounds how much of an npm shim script is read to find the
+// package it runs.
+const maxShimSize = 64 * 1024
+
+// findClaudeBinPackages returns the global npm packages that declare a claude
+// binary and, of those, the package the claude shim currently runs. Nothing is
+// reported unless more than one package declares it, since npm then
+// overwrites one package's shim with another's.
+func findClaudeBinPackages() ([]string, string) {
+	npmPath := findNpmPath()
+	if npmPath == "" {
+		return nil, ""
+	}
+	installed, err := ListGlobalNpmPackages()
+	if err != nil {
+		return nil, ""
+	}
+	root, err := runCommand(npmPath, "root", "-g")
+	if err != nil || root == "" {
+		return nil, ""
+	}
+
+	var packages []string
+	for name := range installed {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name), "package.json"))
+		if err == nil && declaresBin(data, name, "claude") {
+			packages = append(packages, name)
+		}
+	}
+	if len(packages) < 2 {
+		return nil, ""
+	}
+	sort.Strings(packages)
+	return packages, shimPackage(readShimTarget(globalShimPath(root, "claude")), packages)
+}
+
+// declaresBin reports whether the package.json data of package name declares
+// a binary called bin. A string "bin" field is named after the package,
+// without its scope.
+func declaresBin(data []byte, name, bin string) bool {
+	var manifest struct {
+		Bin json.RawMessage `json:"bin"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil || len(manifest.Bin) == 0 {
+		return false
+	}
+
+	var single string
+	if json.Unmarshal(manifest.Bin, &single) == nil {
+		return single != "" && name[strings.LastIndex(name, "/")+1:] == bin
+	}
+	var bins map[string]string
+	if json.Unmarshal(manifest.Bin, &bins) == nil {
+		_, ok := bins[bin]
+		return ok
+	}
+	return false
+}
+
+// globalShimPath returns where npm writes the shim for a global binary, given
+// the global node_modules directory reported by `npm root -g`.
+func globalShimPath(root, bin string) string {
+	if runtime.GOOS == "windows" {
+		// <prefix>\node_modules -> <prefix>\claude.cmd
+		return filepath.Join(filepath.Dir(root), bin+".cmd")
+	}
+	// <prefix>/lib/node_modules -> <prefix>/bin/claude
+	return filepath.Join(filepath.Dir(filepath.Dir(root)), "bin", bin)
+}
+
+// readShimTarget returns what a shim points at: the resolved path of a
+// symlink, or the contents of a Windows .cmd shim. It returns "" when the shim
+// can't be read.
+func readShimTarget(shim string) string {
+	if info, err := os.Lstat(shim); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		target, err := filepath.EvalSymlinks(shim)
+		if err != nil {
+			return ""
+		}
+		return target
+	}
+	f, err := os.Open(shim)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	data, _ := io.ReadAll(io.LimitReader(f, maxShimSize))
+	return string(data)
+}
+
+// shimPackage returns which of packages a shim target refers to, or "".
+func shimPackage(target string, packages []string) string {
+	target = strings.ReplaceAll(target, `\`, "/")
+	for _, name := range packages {
+		if strings.Contains(target, "node_modules/"+name+"/") {
+			return name
+		}
+	}
+	return ""
+}
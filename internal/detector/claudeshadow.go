@@ -0,0 +1,170 @@
+package detector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// ClaudeShadowing reports whether the `claude` a terminal runs is something
+// other than Claude Code: an unrelated tool earlier on PATH, a shell alias, or
+// another global npm package providing a claude binary.
+type ClaudeShadowing struct {
+	// Path is the claude executable PATH resolves to when it is not Claude Code.
+	Path string `json:"path,omitempty"`
+	// ClaudeCodePath is where Claude Code was found instead, if anywhere.
+	ClaudeCodePath string `json:"claudeCodePath,omitempty"`
+	// Alias is the shell startup file and line defining a claude alias or function.
+	Alias string `json:"alias,omitempty"`
+	// Packages lists the global npm packages that each declare a claude
+	// binary, when there is more than one.
+	Packages []string `json:"packages,omitempty"`
+	// Winner is the package in Packages whose claude shim is installed.
+	Winner string `json:"winner,omitempty"`
+}
+
+// claudeAliasPattern matches shell and PowerShell definitions that redefine
+// the claude command.
+var claudeAliasPattern = regexp.MustCompile(`(?i)^\s*(alias\s+claude=|(set|new)-alias\s+(-name\s+)?claude\b|function\s+claude\b|claude\s*\(\)\s*\{)`)
+
+// IsClaudeCodeVersion reports whether output of `claude --version` comes from
+// Claude Code, which prints its version followed by "(Claude Code)".
+func IsClaudeCodeVersion(output string) bool {
+	return strings.Contains(output, "(Claude Code)")
+}
+
+// CheckClaudeShadowing checks whether `claude` on PATH is Claude Code,
+// whether a shell startup file aliases it, and whether other global npm
+// packages provide a claude binary. A zero result means nothing shadows
+// Claude Code, including when claude is not installed at all. Listing the
+// global packages is slow, so lookups that only need the executable use
+// CheckClaudePath.
+func CheckClaudeShadowing() ClaudeShadowing {
+	result := CheckClaudePath()
+	if home, err := os.UserHomeDir(); err == nil {
+		result.Alias = findClaudeAlias(shellStartupFiles(home))
+	}
+	result.Packages, result.Winner = findClaudeBinPackages()
+	return result
+}
+
+// CheckClaudePath checks only whether `claude` on PATH is Claude Code,
+// setting Path and ClaudeCodePath of the result.
+func CheckClaudePath() ClaudeShadowing {
+	var result ClaudeShadowing
+	candidates := claudeCandidates(os.Getenv("PATH"), os.Getenv("PATHEXT"))
+	if len(candidates) > 0 {
+		resolved := candidates[0]
+		if version, err := runCommand(resolved, "--version"); err != nil || !IsClaudeCodeVersion(version) {
+			result.Path = resolved
+			result.ClaudeCodePath, _ = findClaudeCode(candidates[1:])
+		}
+	}
+	return result
+}
+
+// findClaudeCode returns the first of candidates that reports a Claude Code
+// version, along with that version.
+func findClaudeCode(candidates []string) (string, string) {
+	for _, candidate := range candidates {
+		if version, err := runCommand(candidate, "--version"); err == nil && IsClaudeCodeVersion(version) {
+			return candidate, version
+		}
+	}
+	return "", ""
+}
+
+// claudeCandidates lists the claude executables in the directories of pathEnv
+// in lookup order, followed by npm's global directories on Windows. pathExt
+// is the Windows PATHEXT list and is ignored elsewhere.
+func claudeCandidates(pathEnv, pathExt string) []string {
+	names := []string{"claude"}
+	dirs := filepath.SplitList(pathEnv)
+	if runtime.GOOS == "windows" {
+		if pathExt == "" {
+			pathExt = ".COM;.EXE;.BAT;.CMD"
+		}
+		names = nil
+		for _, ext := range strings.Split(pathExt, ";") {
+			if ext != "" {
+				names = append(names, "claude"+strings.ToLower(ext))
+			}
+		}
+		dirs = append(dirs, NpmBinDirs()...)
+	}
+
+	var candidates []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			key := path
+			if runtime.GOOS == "windows" {
+				key = strings.ToLower(path)
+			}
+			if seen[key] || !isExecutableFile(path) {
+				continue
+			}
+			seen[key] = true
+			candidates = append(candidates, path)
+		}
+	}
+	return candidates
+}
+
+// isExecutableFile reports whether path is a regular file that can be run.
+// On Windows any file matching PATHEXT is runnable.
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0o111 != 0
+}
+
+// shellStartupFiles returns the shell and PowerShell profiles that commonly
+// define aliases for the user with the given home directory.
+func shellStartupFiles(home string) []string {
+	if runtime.GOOS == "windows" {
+		docs := filepath.Join(home, "Documents")
+		return []string{
+			filepath.Join(docs, "PowerShell", "Microsoft.PowerShell_profile.ps1"),
+			filepath.Join(docs, "PowerShell", "profile.ps1"),
+			filepath.Join(docs, "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1"),
+			filepath.Join(docs, "WindowsPowerShell", "profile.ps1"),
+		}
+	}
+	files := []string{".bashrc", ".bash_profile", ".bash_aliases", ".zshrc", ".profile"}
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = filepath.Join(home, f)
+	}
+	return paths
+}
+
+// findClaudeAlias returns "file:line: text" for the first line in files that
+// redefines claude, or "" if none does.
+func findClaudeAlias(files []string) string {
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for n := 1; scanner.Scan(); n++ {
+			if line := scanner.Text(); claudeAliasPattern.MatchString(line) {
+				f.Close()
+				return fmt.Sprintf("%s:%d: %s", path, n, strings.TrimSpace(line))
+			}
+		}
+		f.Close()
+	}
+	return ""
+}
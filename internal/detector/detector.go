@@ -2,11 +2,13 @@ package detector
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -17,6 +19,23 @@ type SoftwareStatus struct {
 	Installed bool   `json:"installed"`
 	Version   string `json:"version"`
 	Required  bool   `json:"required"`
+	// Method is the version manager providing the component (e.g. "fnm", "volta").
+	Method string `json:"method,omitempty"`
+	// Versions lists all versions installed through the version manager.
+	Versions []string `json:"versions,omitempty"`
+	// BundledPath is a private copy of the component bundled with another
+	// product, such as Visual Studio's node.exe. It is not on PATH and is
+	// not reported as Installed.
+	BundledPath string `json:"bundledPath,omitempty"`
+}
+
+// NodeVersionManager describes a Node.js version manager installed for the current user.
+type NodeVersionManager struct {
+	Name     string   `json:"name"`
+	Dir      string   `json:"dir"`
+	Versions []string `json:"versions"`
+	// nodePath is the manager's default node executable, if one is configured.
+	nodePath string
 }
 
 // SystemCheckResult contains the status of all required software components.
@@ -25,8 +44,45 @@ type SystemCheckResult struct {
 	Git             SoftwareStatus `json:"git"`
 	ClaudeCode      SoftwareStatus `json:"claudeCode"`
 	WingetAvailable bool           `json:"wingetAvailable"`
+	Winget          WingetStatus   `json:"winget"`
+	BrewAvailable   bool           `json:"brewAvailable"`
+	ScoopAvailable  bool           `json:"scoopAvailable"`
+	WindowsVersion  WindowsVersion `json:"windowsVersion"`
+	// PolicyWarnings lists enterprise policies that may block installation.
+	PolicyWarnings []PolicyWarning `json:"policyWarnings"`
+	// ExecutableLocation reports whether the installer runs from a writable, local volume.
+	ExecutableLocation ExecutableLocation `json:"executableLocation"`
+	// GitCredentials reports whether Git can authenticate to remotes.
+	GitCredentials GitCredentialStatus `json:"gitCredentials"`
+	// ClaudeShadowing reports a claude command that is not Claude Code.
+	ClaudeShadowing ClaudeShadowing `json:"claudeShadowing"`
+}
+
+// PolicyWarning is an advisory about a Group Policy or MDM restriction that
+// may cause part of the installation to fail.
+type PolicyWarning struct {
+	// Policy identifies the restriction ("winget", "smartscreen",
+	// "registry-environment" or "application-control").
+	Policy  string `json:"policy"`
+	Message string `json:"message"`
+}
+
+// WindowsVersion describes the Windows release the installer is running on.
+type WindowsVersion struct {
+	Major           int  `json:"major"`
+	Build           int  `json:"build"`
+	Detected        bool `json:"detected"`
+	WingetSupported bool `json:"wingetSupported"`
+	Supported       bool `json:"supported"`
 }
 
+const (
+	// MinWingetBuild is the first Windows 10 build (1809) that supports winget.
+	MinWingetBuild = 17763
+	// minSupportedMajorVersion is the oldest Windows major version the installer supports.
+	minSupportedMajorVersion = 10
+)
+
 // commonNodePaths lists common Node.js installation directories on Windows.
 var commonNodePaths = []string{
 	`C:\Program Files\nodejs`,
@@ -40,11 +96,6 @@ var commonGitPaths = []string{
 	`C:\Program Files\Git\bin`,
 }
 
-// commonNpmPaths lists common npm global installation directories on Windows.
-var commonNpmPaths = []string{
-	filepath.Join(os.Getenv("APPDATA"), "npm"),
-}
-
 // CheckNodeJS detects whether Node.js is installed and returns its status.
 func CheckNodeJS() SoftwareStatus {
 	status := SoftwareStatus{
@@ -52,14 +103,41 @@ func CheckNodeJS() SoftwareStatus {
 		Required: true,
 	}
 
+	managers := DetectNodeVersionManagers()
+
 	// Try exec.LookPath first (searches system PATH)
 	nodePath, err := exec.LookPath("node")
+	if err == nil && isVisualStudioPath(nodePath) {
+		// A Developer Command Prompt puts Visual Studio's private copy on
+		// PATH; it has no npm, so keep looking for a real installation
+		status.BundledPath = nodePath
+		nodePath, err = "", exec.ErrNotFound
+	}
 	if err != nil && runtime.GOOS == "windows" {
 		// Fallback: check common installation directories
 		nodePath = findExecutableInPaths("node.exe", commonNodePaths)
 	}
+	if nodePath == "" && err != nil {
+		// Fallback: a version manager's default node that isn't active in this shell
+		for _, m := range managers {
+			if m.nodePath != "" {
+				nodePath = m.nodePath
+				break
+			}
+		}
+	}
+
+	if manager := managerForPath(nodePath, managers); manager != nil {
+		status.Method = manager.Name
+		status.Versions = manager.Versions
+	}
 
 	if nodePath == "" && err != nil {
+		if status.BundledPath == "" && runtime.GOOS == "windows" {
+			// Visual Studio's private copy is normally not on PATH and has no
+			// npm, so it is reported but doesn't count as installed
+			status.BundledPath = FindVisualStudioNode()
+		}
 		return status
 	}
 
@@ -78,6 +156,113 @@ func CheckNodeJS() SoftwareStatus {
 	return status
 }
 
+// DetectNodeVersionManagers returns the fnm and Volta installations found for the current user.
+func DetectNodeVersionManagers() []NodeVersionManager {
+	localAppData := os.Getenv("LOCALAPPDATA")
+	appData := os.Getenv("APPDATA")
+
+	var managers []NodeVersionManager
+
+	for _, dir := range nonEmpty(os.Getenv("FNM_DIR"), joinIfSet(localAppData, "fnm"), joinIfSet(appData, "fnm")) {
+		if !isDir(dir) {
+			continue
+		}
+		managers = append(managers, NodeVersionManager{
+			Name:     "fnm",
+			Dir:      dir,
+			Versions: listVersionDirs(filepath.Join(dir, "node-versions")),
+			nodePath: findExecutableInPaths(nodeExecutableName(), []string{
+				filepath.Join(dir, "aliases", "default"),
+				filepath.Join(dir, "aliases", "default", "bin"),
+			}),
+		})
+		break
+	}
+
+	for _, dir := range nonEmpty(os.Getenv("VOLTA_HOME"), joinIfSet(localAppData, "Volta")) {
+		if !isDir(dir) {
+			continue
+		}
+		managers = append(managers, NodeVersionManager{
+			Name:     "volta",
+			Dir:      dir,
+			Versions: listVersionDirs(filepath.Join(dir, "tools", "image", "node")),
+			nodePath: findExecutableInPaths(nodeExecutableName(), []string{filepath.Join(dir, "bin")}),
+		})
+		break
+	}
+
+	return managers
+}
+
+// managerForPath returns the version manager that provides the given node path.
+// fnm activates versions through per-shell "fnm_multishells" directories that
+// live outside its own directory, so those are matched by name.
+func managerForPath(nodePath string, managers []NodeVersionManager) *NodeVersionManager {
+	if nodePath == "" {
+		return nil
+	}
+	lowerPath := strings.ToLower(filepath.Clean(nodePath))
+	for idx := range managers {
+		m := &managers[idx]
+		if strings.HasPrefix(lowerPath, strings.ToLower(filepath.Clean(m.Dir))+string(os.PathSeparator)) {
+			return m
+		}
+		if m.Name == "fnm" && strings.Contains(lowerPath, "fnm_multishells") {
+			return m
+		}
+	}
+	return nil
+}
+
+// listVersionDirs returns the sanitized names of version directories within dir.
+func listVersionDirs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			versions = append(versions, sanitizeVersion(entry.Name()))
+		}
+	}
+	return versions
+}
+
+// nodeExecutableName returns the platform-specific node executable name.
+func nodeExecutableName() string {
+	if runtime.GOOS == "windows" {
+		return "node.exe"
+	}
+	return "node"
+}
+
+// joinIfSet joins base and elem, returning "" when base is empty.
+func joinIfSet(base, elem string) string {
+	if base == "" {
+		return ""
+	}
+	return filepath.Join(base, elem)
+}
+
+// nonEmpty returns the non-empty values in order.
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// isDir reports whether path exists and is a directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
 // CheckGit detects whether Git is installed and returns its status.
 func CheckGit() SoftwareStatus {
 	status := SoftwareStatus{
@@ -85,23 +270,12 @@ func CheckGit() SoftwareStatus {
 		Required: true,
 	}
 
-	// Try exec.LookPath first
-	gitPath, err := exec.LookPath("git")
-	if err != nil && runtime.GOOS == "windows" {
-		// Fallback: check common installation directories
-		gitPath = findExecutableInPaths("git.exe", commonGitPaths)
-	}
-
-	if gitPath == "" && err != nil {
+	gitPath := FindGit()
+	if gitPath == "" {
 		return status
 	}
 
-	cmdPath := "git"
-	if gitPath != "" {
-		cmdPath = gitPath
-	}
-
-	version, err := runCommand(cmdPath, "--version")
+	version, err := runCommand(gitPath, "--version")
 	if err != nil {
 		return status
 	}
@@ -128,9 +302,10 @@ func CheckClaudeCode() SoftwareStatus {
 	claudePath, err := exec.LookPath("claude")
 	if err != nil && runtime.GOOS == "windows" {
 		// Fallback: check npm global bin directories
-		claudePath = findExecutableInPaths("claude.cmd", commonNpmPaths)
+		npmBinDirs := NpmBinDirs()
+		claudePath = findExecutableInPaths("claude.cmd", npmBinDirs)
 		if claudePath == "" {
-			claudePath = findExecutableInPaths("claude.ps1", commonNpmPaths)
+			claudePath = findExecutableInPaths("claude.ps1", npmBinDirs)
 		}
 	}
 
@@ -144,8 +319,12 @@ func CheckClaudeCode() SoftwareStatus {
 	}
 
 	version, err := runCommand(cmdPath, "--version")
-	if err != nil {
-		return status
+	if err != nil || !IsClaudeCodeVersion(version) {
+		// An unrelated claude may come first on PATH; look for Claude Code
+		// further down, reporting the conflict through CheckClaudeShadowing
+		if _, version = findClaudeCode(claudeCandidates(os.Getenv("PATH"), os.Getenv("PATHEXT"))); version == "" {
+			return status
+		}
 	}
 
 	status.Installed = true
@@ -153,28 +332,341 @@ func CheckClaudeCode() SoftwareStatus {
 	return status
 }
 
+// IsClaudeLoggedIn reports whether Claude Code appears to be authenticated,
+// either through an API key in the environment or a stored OAuth account in
+// the Claude Code config file.
+func IsClaudeLoggedIn() bool {
+	if os.Getenv("ANTHROPIC_API_KEY") != "" {
+		return true
+	}
+
+	configPath, err := claudeConfigPath()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return false
+	}
+	return hasOAuthAccount(data)
+}
+
+// claudeConfigPath returns the path of the Claude Code global config file,
+// honouring CLAUDE_CONFIG_DIR when set.
+func claudeConfigPath() (string, error) {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, ".claude.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude.json"), nil
+}
+
+// hasOAuthAccount reports whether Claude Code config data records a logged-in account.
+func hasOAuthAccount(data []byte) bool {
+	var config struct {
+		OAuthAccount json.RawMessage `json:"oauthAccount"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return false
+	}
+	account := strings.TrimSpace(string(config.OAuthAccount))
+	return account != "" && account != "null" && account != "{}"
+}
+
+// CheckComponent checks a single component by name ("nodejs", "git" or "claudecode").
+// Names are matched case-insensitively.
+func CheckComponent(name string) (SoftwareStatus, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "nodejs", "node":
+		return CheckNodeJS(), nil
+	case "git":
+		return CheckGit(), nil
+	case "claudecode", "claude":
+		return CheckClaudeCode(), nil
+	default:
+		return SoftwareStatus{}, fmt.Errorf("unknown component %q", name)
+	}
+}
+
+// Reasons reported in WingetStatus when winget or its sources are not available.
+const (
+	// WingetReasonNotFound means winget is not on PATH.
+	WingetReasonNotFound = "not-found"
+	// WingetReasonVersionFailed means winget was found but `winget --version` failed.
+	WingetReasonVersionFailed = "version-failed"
+	// WingetReasonPolicyBlocked means Group Policy disables winget.
+	WingetReasonPolicyBlocked = "policy-blocked"
+	// WingetReasonSourcesUnavailable means winget runs but has no usable sources;
+	// Available is still true.
+	WingetReasonSourcesUnavailable = "sources-unavailable"
+)
+
+// WingetStatus describes whether winget is usable and, if not, why.
+type WingetStatus struct {
+	// Available reports that winget is on PATH and runs, as CheckWinget does.
+	Available bool   `json:"available"`
+	Version   string `json:"version,omitempty"`
+	// SourcesAvailable reports that winget has at least one usable source;
+	// without one, installs through winget fail even though it runs.
+	SourcesAvailable bool `json:"sourcesAvailable"`
+	// Reason is one of the WingetReason* constants when Available or
+	// SourcesAvailable is false.
+	Reason string `json:"reason,omitempty"`
+	// Detail is a human-readable explanation of Reason.
+	Detail string `json:"detail,omitempty"`
+}
+
 // CheckWinget checks whether the Windows Package Manager (winget) is available.
+// See CheckWingetStatus for why it is not and whether it has usable sources.
 func CheckWinget() bool {
-	_, err := exec.LookPath("winget")
+	_, status := wingetRuns()
+	return status.Available
+}
+
+// CheckWingetStatus checks that winget is on PATH and runs, then whether it
+// has at least one configured source, reporting the first check that fails.
+func CheckWingetStatus() WingetStatus {
+	wingetPath, status := wingetRuns()
+	if !status.Available {
+		return status
+	}
+
+	output, err := runCommandOutput(wingetPath, "source", "list")
+	if reason, detail := wingetSourcesProblem(output, err); reason != "" {
+		status.Reason, status.Detail = reason, detail
+		return status
+	}
+
+	status.SourcesAvailable = true
+	return status
+}
+
+// wingetRuns finds winget and runs `winget --version`, returning its path and
+// a status with Available set when it works.
+func wingetRuns() (string, WingetStatus) {
+	wingetPath, err := exec.LookPath("winget")
 	if err != nil {
-		return false
+		return "", WingetStatus{Reason: WingetReasonNotFound, Detail: "winget was not found on PATH"}
 	}
 
-	// Verify winget actually works by running it
-	_, err = runCommand("winget", "--version")
-	return err == nil
+	// winget reports policy blocks on stdout, so keep the output on failure
+	output, err := runCommandOutput(wingetPath, "--version")
+	if err != nil {
+		if isWingetPolicyBlocked(output) {
+			return wingetPath, WingetStatus{Reason: WingetReasonPolicyBlocked, Detail: "winget is disabled by Group Policy"}
+		}
+		return wingetPath, WingetStatus{Reason: WingetReasonVersionFailed, Detail: fmt.Sprintf("winget --version failed: %v", err)}
+	}
+	return wingetPath, WingetStatus{Available: true, Version: strings.TrimSpace(output)}
+}
+
+// isWingetPolicyBlocked reports whether winget output says Group Policy disables it.
+func isWingetPolicyBlocked(output string) bool {
+	return strings.Contains(strings.ToLower(output), "disabled by group policy")
+}
+
+// wingetSourcesProblem inspects `winget source list` output and returns a
+// reason and detail when no source is usable, or empty strings otherwise.
+func wingetSourcesProblem(output string, err error) (string, string) {
+	if isWingetPolicyBlocked(output) {
+		return WingetReasonPolicyBlocked, "winget sources are disabled by Group Policy"
+	}
+	if err != nil {
+		return WingetReasonSourcesUnavailable, fmt.Sprintf("winget source list failed: %v", err)
+	}
+
+	// Sources are listed as "<name> <url>" rows below a dashed separator
+	inTable := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "---") {
+			inTable = true
+			continue
+		}
+		if inTable && line != "" {
+			return "", ""
+		}
+	}
+	return WingetReasonSourcesUnavailable, "winget has no sources configured"
+}
+
+// CheckScoop checks whether the Scoop package manager is available (Windows only).
+func CheckScoop() bool {
+	return runtime.GOOS == "windows" && FindScoop() != ""
+}
+
+// ScoopShimsDir returns the directory where Scoop places command shims. Scoop
+// installs per-user under %USERPROFILE%\scoop unless SCOOP relocates it.
+func ScoopShimsDir() string {
+	root := os.Getenv("SCOOP")
+	if root == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		root = filepath.Join(home, "scoop")
+	}
+	return filepath.Join(root, "shims")
+}
+
+// FindScoop returns the path to the Scoop executable shim, or "" when Scoop
+// is not installed.
+func FindScoop() string {
+	if scoopPath, err := exec.LookPath("scoop"); err == nil {
+		return scoopPath
+	}
+	// The shims directory may not be on this process's PATH yet
+	dir := ScoopShimsDir()
+	if dir == "" {
+		return ""
+	}
+	for _, name := range []string{"scoop.cmd", "scoop.exe"} {
+		if scoopPath := findExecutableInPaths(name, []string{dir}); scoopPath != "" {
+			return scoopPath
+		}
+	}
+	return ""
+}
+
+// commonBrewPaths lists Homebrew locations on Apple Silicon and Intel Macs.
+var commonBrewPaths = []string{
+	"/opt/homebrew/bin",
+	"/usr/local/bin",
+}
+
+// CheckBrew checks whether Homebrew is available (macOS only).
+func CheckBrew() bool {
+	return runtime.GOOS == "darwin" && FindBrew() != ""
+}
+
+// FindBrew returns the path to the Homebrew executable, or "" when Homebrew
+// is not installed.
+func FindBrew() string {
+	if brewPath, err := exec.LookPath("brew"); err == nil {
+		return brewPath
+	}
+	// GUI apps don't inherit the shell PATH, so check the standard prefixes
+	return findExecutableInPaths("brew", commonBrewPaths)
 }
 
 // CheckAll performs a comprehensive check of all required software components.
 func CheckAll() SystemCheckResult {
+	winget := CheckWingetStatus()
 	return SystemCheckResult{
-		NodeJS:          CheckNodeJS(),
-		Git:             CheckGit(),
-		ClaudeCode:      CheckClaudeCode(),
-		WingetAvailable: CheckWinget(),
+		NodeJS:             CheckNodeJS(),
+		Git:                CheckGit(),
+		ClaudeCode:         CheckClaudeCode(),
+		WingetAvailable:    winget.Available,
+		Winget:             winget,
+		BrewAvailable:      CheckBrew(),
+		ScoopAvailable:     CheckScoop(),
+		WindowsVersion:     CheckWindowsVersion(),
+		PolicyWarnings:     CheckPolicyRestrictions(),
+		ExecutableLocation: CheckExecutableLocation(),
+		GitCredentials:     CheckGitCredentials(),
+		ClaudeShadowing:    CheckClaudeShadowing(),
 	}
 }
 
+// CheckPolicyRestrictions probes for enterprise policies (Group Policy, MDM,
+// AppLocker/WDAC) that commonly break installation, so they can be surfaced
+// before installing rather than as confusing failures. Only Windows policies
+// are detected; other platforms return no warnings.
+func CheckPolicyRestrictions() []PolicyWarning {
+	return detectPolicyRestrictions()
+}
+
+// CheckWindowsVersion detects the Windows version and evaluates feature support for it.
+func CheckWindowsVersion() WindowsVersion {
+	major, build, err := GetWindowsVersion()
+	if err != nil {
+		return WindowsVersion{}
+	}
+	return WindowsVersion{
+		Major:           major,
+		Build:           build,
+		Detected:        true,
+		WingetSupported: WingetSupportedOnBuild(major, build),
+		Supported:       major >= minSupportedMajorVersion,
+	}
+}
+
+// WingetSupportedOnBuild reports whether winget is supported on the given Windows version.
+func WingetSupportedOnBuild(major, build int) bool {
+	return major >= 10 && build >= MinWingetBuild
+}
+
+// parseRegistryNumber parses a numeric registry value stored as a string.
+// Older builds store some version values as REG_SZ (e.g. "6.3" or "19045"),
+// so only the leading integer component is used.
+func parseRegistryNumber(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if idx := strings.Index(value, "."); idx != -1 {
+		value = value[:idx]
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid numeric registry value %q: %w", value, err)
+	}
+	return n, nil
+}
+
+// ListGlobalNpmPackages returns the globally-installed npm packages mapped to their versions.
+func ListGlobalNpmPackages() (map[string]string, error) {
+	npmPath := findNpmPath()
+	if npmPath == "" {
+		return nil, fmt.Errorf("npm not found in PATH")
+	}
+	return ListGlobalNpmPackagesFor(npmPath, nil)
+}
+
+// ListGlobalNpmPackagesFor is ListGlobalNpmPackages for a specific npm
+// executable, with prefixArgs (such as --prefix) appended to `npm ls -g`.
+func ListGlobalNpmPackagesFor(npmPath string, prefixArgs []string) (map[string]string, error) {
+	// npm ls exits non-zero when the tree has problems (extraneous, missing or
+	// invalid packages) but still prints usable JSON, so parse whatever stdout holds.
+	args := append([]string{"ls", "-g", "--depth=0", "--json"}, prefixArgs...)
+	output, runErr := runCommandOutput(npmPath, args...)
+	packages, err := parseNpmListJSON(output)
+	if err != nil {
+		if runErr != nil {
+			return nil, runErr
+		}
+		return nil, err
+	}
+	return packages, nil
+}
+
+// npmListOutput mirrors the subset of `npm ls --json` output we consume.
+type npmListOutput struct {
+	Dependencies map[string]struct {
+		Version string `json:"version"`
+	} `json:"dependencies"`
+}
+
+// parseNpmListJSON parses `npm ls --json` output into a package-to-version map.
+// Any non-JSON noise before the opening brace (e.g. warnings) is ignored.
+func parseNpmListJSON(output string) (map[string]string, error) {
+	start := strings.Index(output, "{")
+	if start == -1 {
+		return nil, fmt.Errorf("npm ls returned no JSON output")
+	}
+
+	var parsed npmListOutput
+	if err := json.Unmarshal([]byte(output[start:]), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse npm ls output: %w", err)
+	}
+
+	packages := make(map[string]string, len(parsed.Dependencies))
+	for name, dep := range parsed.Dependencies {
+		packages[name] = dep.Version
+	}
+	return packages, nil
+}
+
 // runCommand executes a command with a timeout and returns its trimmed stdout output.
 func runCommand(name string, args ...string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -193,6 +685,32 @@ func runCommand(name string, args ...string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// runCommandOutput executes a command with a timeout and returns its stdout even
+// when the command exits with an error.
+func runCommandOutput(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	hideConsoleWindow(cmd)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return string(output), fmt.Errorf("failed to run %s: %w", name, err)
+	}
+	return string(output), nil
+}
+
+// findNpmPath locates npm, checking common Node.js directories on Windows.
+// It returns an empty string when npm is not found.
+func findNpmPath() string {
+	npmPath, err := exec.LookPath("npm")
+	if err != nil && runtime.GOOS == "windows" {
+		npmPath = findExecutableInPaths("npm.cmd", commonNodePaths)
+	}
+	return npmPath
+}
+
 // findExecutableInPaths searches for an executable in a list of directories.
 func findExecutableInPaths(executable string, paths []string) string {
 	for _, dir := range paths {
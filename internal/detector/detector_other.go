@@ -2,9 +2,33 @@
 
 package detector
 
-import "os/exec"
+import (
+	"fmt"
+	"os/exec"
+)
 
 // hideConsoleWindow is a no-op on non-Windows platforms.
 func hideConsoleWindow(cmd *exec.Cmd) {
 	// No-op: console window hiding is only needed on Windows
 }
+
+// GetWindowsVersion is not supported on non-Windows platforms.
+func GetWindowsVersion() (major, build int, err error) {
+	return 0, 0, fmt.Errorf("GetWindowsVersion is only supported on Windows")
+}
+
+// detectPolicyRestrictions reports no restrictions on non-Windows platforms.
+func detectPolicyRestrictions() []PolicyWarning {
+	return nil
+}
+
+// nodeInstallerCachePackages is unsupported; the Windows Installer cache only
+// exists on Windows.
+func nodeInstallerCachePackages() ([]InstallerCachePackage, error) {
+	return nil, fmt.Errorf("the Windows Installer cache only exists on Windows")
+}
+
+// registeredMSIPackages is empty; Windows Installer only exists on Windows.
+func registeredMSIPackages() map[string]InstallerCachePackage {
+	return nil
+}
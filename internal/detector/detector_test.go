@@ -1,6 +1,12 @@
 package detector
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -27,3 +33,688 @@ func TestSanitizeVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRegistryNumber(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  int
+		expectErr bool
+	}{
+		{"19045", 19045, false},
+		{"6.3", 6, false},
+		{" 10 ", 10, false},
+		{"", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := parseRegistryNumber(tt.input)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("parseRegistryNumber(%q) expected error", tt.input)
+				}
+				return
+			}
+			if err != nil || result != tt.expected {
+				t.Errorf("parseRegistryNumber(%q) = %d, %v, want %d", tt.input, result, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWingetSupportedOnBuild(t *testing.T) {
+	tests := []struct {
+		major, build int
+		expected     bool
+	}{
+		{10, 17763, true},
+		{10, 22631, true},
+		{10, 17134, false},
+		{6, 9600, false},
+	}
+
+	for _, tt := range tests {
+		if got := WingetSupportedOnBuild(tt.major, tt.build); got != tt.expected {
+			t.Errorf("WingetSupportedOnBuild(%d, %d) = %v, want %v", tt.major, tt.build, got, tt.expected)
+		}
+	}
+}
+
+func TestParseNpmListJSON(t *testing.T) {
+	output := `npm WARN config global --global, --local are deprecated
+{
+  "name": "lib",
+  "dependencies": {
+    "@anthropic-ai/claude-code": {"version": "1.0.3"},
+    "npm": {"version": "10.9.2"}
+  }
+}`
+
+	packages, err := parseNpmListJSON(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if packages["@anthropic-ai/claude-code"] != "1.0.3" || packages["npm"] != "10.9.2" {
+		t.Errorf("unexpected packages: %v", packages)
+	}
+
+	if _, err := parseNpmListJSON("npm ERR! something went wrong"); err == nil {
+		t.Error("expected error for output without JSON")
+	}
+}
+
+func TestCheckComponent_Unknown(t *testing.T) {
+	if _, err := CheckComponent("python"); err == nil {
+		t.Error("expected error for unknown component")
+	}
+}
+
+func TestDetectNodeVersionManagers(t *testing.T) {
+	fnmDir := t.TempDir()
+	voltaDir := t.TempDir()
+	for _, dir := range []string{
+		filepath.Join(fnmDir, "node-versions", "v20.11.0"),
+		filepath.Join(fnmDir, "node-versions", "v22.13.1"),
+		filepath.Join(voltaDir, "tools", "image", "node", "18.19.0"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+
+	t.Setenv("FNM_DIR", fnmDir)
+	t.Setenv("VOLTA_HOME", voltaDir)
+	t.Setenv("LOCALAPPDATA", "")
+	t.Setenv("APPDATA", "")
+
+	managers := DetectNodeVersionManagers()
+	if len(managers) != 2 {
+		t.Fatalf("got %d managers, want 2", len(managers))
+	}
+	if managers[0].Name != "fnm" || len(managers[0].Versions) != 2 || managers[0].Versions[1] != "22.13.1" {
+		t.Errorf("unexpected fnm manager: %+v", managers[0])
+	}
+	if managers[1].Name != "volta" || len(managers[1].Versions) != 1 || managers[1].Versions[0] != "18.19.0" {
+		t.Errorf("unexpected volta manager: %+v", managers[1])
+	}
+
+	nodePath := filepath.Join(voltaDir, "bin", "node")
+	if m := managerForPath(nodePath, managers); m == nil || m.Name != "volta" {
+		t.Errorf("managerForPath(%q) = %v, want volta", nodePath, m)
+	}
+	if m := managerForPath(filepath.Join(t.TempDir(), "node"), managers); m != nil {
+		t.Errorf("managerForPath for unrelated path = %v, want nil", m)
+	}
+}
+
+func TestHasOAuthAccount(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"logged in", `{"oauthAccount":{"emailAddress":"user@example.com"}}`, true},
+		{"no account", `{"numStartups":3}`, false},
+		{"null account", `{"oauthAccount":null}`, false},
+		{"empty account", `{"oauthAccount":{}}`, false},
+		{"invalid json", `not json`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasOAuthAccount([]byte(tt.data)); got != tt.want {
+				t.Errorf("hasOAuthAccount(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	baseline := &EnvSnapshot{
+		OS:          "windows",
+		Arch:        "amd64",
+		OSBuild:     22631,
+		Components:  map[string]string{"nodejs": "22.13.1", "git": "2.47.1", "claudecode": "1.0.0"},
+		PathEntries: []string{"/usr/bin", "/opt/node/bin"},
+		NpmRegistry: "https://registry.npmjs.org/",
+	}
+	current := &EnvSnapshot{
+		OS:          "windows",
+		Arch:        "amd64",
+		OSBuild:     22631,
+		Components:  map[string]string{"nodejs": "22.13.1", "git": "", "claudecode": "1.0.0"},
+		PathEntries: []string{"/usr/bin/", "/opt/other/bin"},
+		NpmRegistry: "https://registry.npmjs.org/",
+	}
+
+	diffs := CompareSnapshots(baseline, current)
+	want := []Difference{
+		{Field: "components.git", Baseline: "2.47.1", Current: ""},
+		{Field: "path", Baseline: "/opt/node/bin"},
+		{Field: "path", Current: "/opt/other/bin"},
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("got %d differences %+v, want %d", len(diffs), diffs, len(want))
+	}
+	for idx := range want {
+		if diffs[idx] != want[idx] {
+			t.Errorf("difference %d = %+v, want %+v", idx, diffs[idx], want[idx])
+		}
+	}
+
+	if diffs := CompareSnapshots(baseline, baseline); len(diffs) != 0 {
+		t.Errorf("identical snapshots reported differences: %+v", diffs)
+	}
+}
+
+func TestIsClaudeProcess(t *testing.T) {
+	tests := []struct {
+		cmdline string
+		want    bool
+	}{
+		{"claude", true},
+		{"/usr/local/bin/claude --resume", true},
+		{`"C:\Users\me\.local\bin\claude.exe"`, true},
+		{`"C:\Program Files\Claude\claude.exe" --continue`, true},
+		{"node /usr/lib/node_modules/@anthropic-ai/claude-code/cli.js", true},
+		{`"C:\Program Files\nodejs\node.exe" C:\Users\me\AppData\Roaming\npm\node_modules\@anthropic-ai\claude-code\cli.js`, true},
+		{"/opt/claude-code-installer/claude-code-installer", false},
+		{"node server.js", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isClaudeProcess(tt.cmdline); got != tt.want {
+			t.Errorf("isClaudeProcess(%q) = %v, want %v", tt.cmdline, got, tt.want)
+		}
+	}
+}
+
+func TestDetectProjectNodeRequirement(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"no project files", nil, "", false},
+		{"nvmrc version", map[string]string{".nvmrc": "v20.11.1\n"}, "20.11.1", false},
+		{"nvmrc alias with comment", map[string]string{".nvmrc": "# pinned\nlts/iron # LTS\n"}, "lts/iron", false},
+		{"engines range", map[string]string{"package.json": `{"name":"app","engines":{"node":">=18 <23"}}`}, ">=18 <23", false},
+		{"nvmrc takes precedence", map[string]string{
+			".nvmrc":       "22",
+			"package.json": `{"engines":{"node":">=18"}}`,
+		}, "22", false},
+		{"package.json without engines", map[string]string{"package.json": `{"name":"app"}`}, "", false},
+		{"invalid package.json", map[string]string{"package.json": `{`}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := DetectProjectNodeRequirement(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DetectProjectNodeRequirement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DetectProjectNodeRequirement() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWingetSourcesProblem(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		err        error
+		wantReason string
+	}{
+		{
+			name:   "sources listed",
+			output: "Name    Argument\n-----------------------------------------------\nmsstore https://storeedgefd.dsx.mp.microsoft.com/v9.0\nwinget  https://cdn.winget.microsoft.com/cache\n",
+		},
+		{
+			name:       "no sources",
+			output:     "There are no sources configured.\n",
+			wantReason: WingetReasonSourcesUnavailable,
+		},
+		{
+			name:       "empty table",
+			output:     "Name    Argument\n--------------------\n",
+			wantReason: WingetReasonSourcesUnavailable,
+		},
+		{
+			name:       "command failed",
+			err:        errors.New("exit status 1"),
+			wantReason: WingetReasonSourcesUnavailable,
+		},
+		{
+			name:       "policy",
+			output:     "This operation is disabled by Group Policy : Enable Windows Package Manager\n",
+			err:        errors.New("exit status 1"),
+			wantReason: WingetReasonPolicyBlocked,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, detail := wingetSourcesProblem(tt.output, tt.err)
+			if reason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", reason, tt.wantReason)
+			}
+			if (reason == "") != (detail == "") {
+				t.Errorf("detail %q inconsistent with reason %q", detail, reason)
+			}
+		})
+	}
+}
+
+func TestCheckLocation(t *testing.T) {
+	dir := t.TempDir()
+
+	location := checkLocation(filepath.Join(dir, "installer.exe"))
+	if !location.Writable {
+		t.Error("temp directory reported as not writable")
+	}
+	if location.Warning != "" && !location.Network {
+		t.Errorf("unexpected warning for writable local directory: %q", location.Warning)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("write probe left %d file(s) behind", len(entries))
+	}
+
+	location = checkLocation(filepath.Join(dir, "missing", "installer.exe"))
+	if location.Writable {
+		t.Error("missing directory reported as writable")
+	}
+	if location.Warning == "" {
+		t.Error("no warning for an unwritable location")
+	}
+}
+
+func TestEffectiveCredentialHelper(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "none", output: ""},
+		{name: "single", output: "manager\n", want: "manager"},
+		{name: "first of several", output: "manager\nstore\n", want: "manager"},
+		{name: "reset by empty value", output: "manager\n\nosxkeychain\n", want: "osxkeychain"},
+		{name: "reset last", output: "manager\n\n"},
+		{name: "crlf", output: "manager\r\n", want: "manager"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := effectiveCredentialHelper(tt.output); got != tt.want {
+				t.Errorf("effectiveCredentialHelper(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCredentialManagerHelper(t *testing.T) {
+	tests := []struct {
+		helper string
+		want   bool
+	}{
+		{"manager", true},
+		{"manager-core", true},
+		{`"C:/Program Files/Git/mingw64/bin/git-credential-manager.exe"`, true},
+		{"/usr/local/share/gcm-core/git-credential-manager", true},
+		{"store", false},
+		{"osxkeychain", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isCredentialManagerHelper(tt.helper); got != tt.want {
+			t.Errorf("isCredentialManagerHelper(%q) = %v, want %v", tt.helper, got, tt.want)
+		}
+	}
+}
+
+func TestIsClaudeCodeVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"1.0.35 (Claude Code)", true},
+		{"2.0.14 (Claude Code)\n", true},
+		{"claude 0.4.2", false},
+		{"Usage: claude [options]", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsClaudeCodeVersion(tt.output); got != tt.want {
+			t.Errorf("IsClaudeCodeVersion(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestClaudeCandidates(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executable bits are not used on Windows")
+	}
+
+	first := t.TempDir()
+	second := t.TempDir()
+	notExecutable := t.TempDir()
+	for _, dir := range []string{first, second} {
+		if err := os.WriteFile(filepath.Join(dir, "claude"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(notExecutable, "claude"), []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pathEnv := strings.Join([]string{notExecutable, first, "", first, second}, string(os.PathListSeparator))
+	got := claudeCandidates(pathEnv, "")
+	want := []string{filepath.Join(first, "claude"), filepath.Join(second, "claude")}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("claudeCandidates() = %v, want %v", got, want)
+	}
+}
+
+func TestFindClaudeAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"bash alias", "export PATH=$HOME/bin:$PATH\nalias claude='other-tool'\n", "alias claude='other-tool'"},
+		{"shell function", "claude() { echo hi; }\n", "claude() { echo hi; }"},
+		{"powershell alias", "Set-Alias -Name claude -Value other.exe\n", "Set-Alias -Name claude -Value other.exe"},
+		{"commented out", "# alias claude='other-tool'\n", ""},
+		{"other alias", "alias claudeai='x'\nalias ll='ls -l'\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".bashrc")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got := findClaudeAlias([]string{filepath.Join(t.TempDir(), "missing"), path})
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("findClaudeAlias() = %q, want none", got)
+				}
+				return
+			}
+			if !strings.HasPrefix(got, path+":") || !strings.HasSuffix(got, tt.want) {
+				t.Errorf("findClaudeAlias() = %q, want %s:<line>: %s", got, path, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeReadiness(t *testing.T) {
+	installed := SoftwareStatus{Installed: true}
+	allOnPath := func(string) bool { return true }
+
+	tests := []struct {
+		name      string
+		result    SystemCheckResult
+		onPath    func(string) bool
+		loggedIn  bool
+		wantScore float64
+		wantSteps []string
+	}{
+		{
+			name:      "nothing installed",
+			onPath:    func(string) bool { return false },
+			wantScore: 0,
+			wantSteps: []string{"Install Node.js", "Install Git", "Install Claude Code", "Log in to Claude Code"},
+		},
+		{
+			name:      "ready",
+			result:    SystemCheckResult{NodeJS: installed, Git: installed, ClaudeCode: installed},
+			onPath:    allOnPath,
+			loggedIn:  true,
+			wantScore: 100,
+			wantSteps: []string{},
+		},
+		{
+			name:      "git not on PATH",
+			result:    SystemCheckResult{NodeJS: installed, Git: installed, ClaudeCode: installed},
+			onPath:    func(name string) bool { return name != "git" },
+			loggedIn:  true,
+			wantScore: 95,
+			wantSteps: []string{"Add Git to PATH, then open a new terminal"},
+		},
+		{
+			name: "claude is another tool",
+			result: SystemCheckResult{NodeJS: installed, Git: installed, ClaudeCode: installed,
+				ClaudeShadowing: ClaudeShadowing{Path: "/usr/bin/claude"}},
+			onPath:    allOnPath,
+			wantScore: 55,
+			wantSteps: []string{"Install Claude Code", "Log in to Claude Code"},
+		},
+		{
+			name: "claude aliased",
+			result: SystemCheckResult{NodeJS: installed, Git: installed, ClaudeCode: installed,
+				ClaudeShadowing: ClaudeShadowing{Alias: "/home/u/.bashrc:3: alias claude=x"}},
+			onPath:    allOnPath,
+			loggedIn:  true,
+			wantScore: 95,
+			wantSteps: []string{"Remove the claude alias at /home/u/.bashrc:3: alias claude=x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeReadiness(tt.result, tt.onPath, tt.loggedIn)
+			if got.Score != tt.wantScore {
+				t.Errorf("Score = %v, want %v", got.Score, tt.wantScore)
+			}
+			if fmt.Sprint(got.MissingSteps) != fmt.Sprint(tt.wantSteps) {
+				t.Errorf("MissingSteps = %q, want %q", got.MissingSteps, tt.wantSteps)
+			}
+		})
+	}
+}
+
+func TestDeclaresBin(t *testing.T) {
+	tests := []struct {
+		name     string
+		pkg      string
+		manifest string
+		want     bool
+	}{
+		{"bin map", "@anthropic-ai/claude-code", `{"bin":{"claude":"cli.js"}}`, true},
+		{"other bins", "typescript", `{"bin":{"tsc":"bin/tsc","tsserver":"bin/tsserver"}}`, false},
+		{"string bin named after package", "claude", `{"bin":"index.js"}`, true},
+		{"string bin named after scoped package", "@someone/claude", `{"bin":"index.js"}`, true},
+		{"string bin of other package", "claude-tools", `{"bin":"index.js"}`, false},
+		{"no bin", "claude", `{"main":"index.js"}`, false},
+		{"invalid", "claude", `{`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := declaresBin([]byte(tt.manifest), tt.pkg, "claude"); got != tt.want {
+				t.Errorf("declaresBin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShimPackage(t *testing.T) {
+	packages := []string{"@anthropic-ai/claude-code", "claude"}
+
+	windowsShim := "@ECHO off\r\n\"%_prog%\"  \"%dp0%\\node_modules\\@anthropic-ai\\claude-code\\cli.js\" %*\r\n"
+	if got := shimPackage(windowsShim, packages); got != "@anthropic-ai/claude-code" {
+		t.Errorf("shimPackage(windows shim) = %q", got)
+	}
+	if got := shimPackage("/usr/local/lib/node_modules/claude/bin/claude.js", packages); got != "claude" {
+		t.Errorf("shimPackage(symlink target) = %q", got)
+	}
+	if got := shimPackage("", packages); got != "" {
+		t.Errorf("shimPackage(\"\") = %q, want empty", got)
+	}
+}
+
+func TestReadShimTarget(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on Windows")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "lib", "node_modules", "claude", "cli.js")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("#!/usr/bin/env node\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	shim := globalShimPath(filepath.Join(dir, "lib", "node_modules"), "claude")
+	if err := os.MkdirAll(filepath.Dir(shim), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../lib/node_modules/claude/cli.js", shim); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := shimPackage(readShimTarget(shim), []string{"@anthropic-ai/claude-code", "claude"}); got != "claude" {
+		t.Errorf("shim resolves to package %q, want claude", got)
+	}
+}
+
+func TestVisualStudioNodeDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		filepath.Join("Microsoft Visual Studio", "2019", "Community", "MSBuild", "Microsoft", "VisualStudio", "NodeJs"),
+		filepath.Join("Microsoft Visual Studio", "2022", "Enterprise", "MSBuild", "Microsoft", "VisualStudio", "NodeJs"),
+		filepath.Join("Microsoft Visual Studio 14.0", "Web", "External"),
+		filepath.Join("Microsoft Visual Studio", "2022", "Enterprise", "Common7"),
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dirs := visualStudioNodeDirs([]string{root})
+	want := []string{
+		filepath.Join(root, "Microsoft Visual Studio", "2022", "Enterprise", "MSBuild", "Microsoft", "VisualStudio", "NodeJs"),
+		filepath.Join(root, "Microsoft Visual Studio", "2019", "Community", "MSBuild", "Microsoft", "VisualStudio", "NodeJs"),
+		filepath.Join(root, "Microsoft Visual Studio 14.0", "Web", "External"),
+	}
+	if strings.Join(dirs, "\n") != strings.Join(want, "\n") {
+		t.Errorf("visualStudioNodeDirs() =\n%s\nwant\n%s", strings.Join(dirs, "\n"), strings.Join(want, "\n"))
+	}
+
+	if !isVisualStudioPath(`C:\Program Files\Microsoft Visual Studio\2022\Community\MSBuild\Microsoft\VisualStudio\NodeJs\node.exe`) {
+		t.Error("isVisualStudioPath() = false for a Visual Studio node.exe")
+	}
+	if isVisualStudioPath(`C:\Program Files\nodejs\node.exe`) {
+		t.Error("isVisualStudioPath() = true for a standalone node.exe")
+	}
+}
+
+func TestNpmBinDirForPrefix(t *testing.T) {
+	if got := npmBinDirForPrefix("D:\\tools\\npm-global\r\n", "windows"); got != `D:\tools\npm-global` {
+		t.Errorf("npmBinDirForPrefix() on Windows = %q, want the prefix itself", got)
+	}
+	if got, want := npmBinDirForPrefix("/home/user/.npm-global\n", "linux"), filepath.Join("/home/user/.npm-global", "bin"); got != want {
+		t.Errorf("npmBinDirForPrefix() on Linux = %q, want %q", got, want)
+	}
+
+	dirs := uniqueDirs("", filepath.Join("a", "npm"), filepath.Join("A", "NPM"), filepath.Join("b"))
+	if want := []string{filepath.Join("a", "npm"), "b"}; strings.Join(dirs, "|") != strings.Join(want, "|") {
+		t.Errorf("uniqueDirs() = %v, want %v", dirs, want)
+	}
+}
+
+func TestNpmGlobalBinDirFor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as npm")
+	}
+	dir := t.TempDir()
+	prefixFile := filepath.Join(dir, "prefix")
+	npmPath := filepath.Join(dir, "npm")
+	script := "#!/bin/sh\nif [ \"$3\" = --prefix ]; then echo \"$4\"; else cat " + prefixFile + "; fi\n"
+	if err := os.WriteFile(npmPath, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(prefixFile, []byte("/first\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(InvalidateNpmGlobalBinDir)
+
+	if got, err := NpmGlobalBinDirFor(npmPath, nil); err != nil || got != filepath.Join("/first", "bin") {
+		t.Fatalf("NpmGlobalBinDirFor() = %q, %v, want /first/bin", got, err)
+	}
+	if got, _ := NpmGlobalBinDirFor(npmPath, []string{"--prefix", "/custom"}); got != filepath.Join("/custom", "bin") {
+		t.Errorf("NpmGlobalBinDirFor() with --prefix = %q, want /custom/bin", got)
+	}
+
+	if err := os.WriteFile(prefixFile, []byte("/second\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := NpmGlobalBinDirFor(npmPath, nil); got != filepath.Join("/first", "bin") {
+		t.Errorf("NpmGlobalBinDirFor() = %q, want the cached /first/bin", got)
+	}
+	InvalidateNpmGlobalBinDir()
+	if got, _ := NpmGlobalBinDirFor(npmPath, nil); got != filepath.Join("/second", "bin") {
+		t.Errorf("NpmGlobalBinDirFor() after invalidation = %q, want /second/bin", got)
+	}
+}
+
+func TestIsNodeMSIProduct(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Node.js":               true,
+		"node.js foundation":    true,
+		" Node.js (x64) ":       true,
+		"Git":                   false,
+		"Microsoft Visual C++":  false,
+		"Installation Database": false,
+		"":                      false,
+	} {
+		if got := isNodeMSIProduct(name); got != want {
+			t.Errorf("isNodeMSIProduct(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	if runtime.GOOS != "windows" {
+		if _, err := CheckInstallerCache(); err == nil {
+			t.Error("CheckInstallerCache() should fail outside Windows")
+		}
+	}
+}
+
+func TestListGlobalNpmPackagesFor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as npm")
+	}
+	npmPath := filepath.Join(t.TempDir(), "npm")
+	// Lists the package only under the redirected prefix, and exits non-zero
+	// the way npm ls does for a problem tree
+	script := "#!/bin/sh\nif [ \"$5\" = --prefix ]; then echo '{\"dependencies\":{\"@anthropic-ai/claude-code\":{\"version\":\"1.0.0\"}}}'; exit 1; fi\necho '{}'\n"
+	if err := os.WriteFile(npmPath, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	packages, err := ListGlobalNpmPackagesFor(npmPath, []string{"--prefix", "/home/user/.npm-global"})
+	if err != nil {
+		t.Fatalf("ListGlobalNpmPackagesFor() error = %v", err)
+	}
+	if packages["@anthropic-ai/claude-code"] != "1.0.0" {
+		t.Errorf("ListGlobalNpmPackagesFor() = %v, want the package listed under the prefix", packages)
+	}
+	if packages, _ := ListGlobalNpmPackagesFor(npmPath, nil); len(packages) != 0 {
+		t.Errorf("ListGlobalNpmPackagesFor() without prefix = %v, want none", packages)
+	}
+}
@@ -3,8 +3,27 @@
 package detector
 
 import (
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"syscall"
+
+	"claude-code-installer/internal/pathutil"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	// currentVersionKeyPath is the registry path holding Windows version information.
+	currentVersionKeyPath = `SOFTWARE\Microsoft\Windows NT\CurrentVersion`
+
+	// appInstallerPolicyKeyPath holds the Group Policy settings for winget.
+	appInstallerPolicyKeyPath = `SOFTWARE\Policies\Microsoft\Windows\AppInstaller`
+	// systemPolicyKeyPath holds the SmartScreen Group Policy settings.
+	systemPolicyKeyPath = `SOFTWARE\Policies\Microsoft\Windows\System`
+	// appLockerPolicyKeyPath holds AppLocker rule collections.
+	appLockerPolicyKeyPath = `SOFTWARE\Policies\Microsoft\Windows\SrpV2`
 )
 
 // hideConsoleWindow sets the SysProcAttr to hide the console window on Windows.
@@ -14,3 +33,139 @@ func hideConsoleWindow(cmd *exec.Cmd) {
 		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
 	}
 }
+
+// GetWindowsVersion reads the Windows major version and build number from the registry.
+func GetWindowsVersion() (major, build int, err error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, currentVersionKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open registry key: %w", err)
+	}
+	defer key.Close()
+
+	// CurrentMajorVersionNumber only exists on Windows 10+; older builds
+	// expose the version as a "6.3"-style CurrentVersion string.
+	major, err = readRegistryNumber(key, "CurrentMajorVersionNumber")
+	if err != nil {
+		if major, err = readRegistryNumber(key, "CurrentVersion"); err != nil {
+			return 0, 0, fmt.Errorf("failed to read Windows major version: %w", err)
+		}
+	}
+
+	build, err = readRegistryNumber(key, "CurrentBuildNumber")
+	if err != nil {
+		if build, err = readRegistryNumber(key, "CurrentBuild"); err != nil {
+			return 0, 0, fmt.Errorf("failed to read Windows build number: %w", err)
+		}
+	}
+
+	return major, build, nil
+}
+
+// readRegistryNumber reads a registry value that may be stored as either a DWORD or a string.
+func readRegistryNumber(key registry.Key, name string) (int, error) {
+	n, _, err := key.GetIntegerValue(name)
+	if err == nil {
+		return int(n), nil
+	}
+	if err != registry.ErrUnexpectedType {
+		return 0, err
+	}
+
+	s, _, err := key.GetStringValue(name)
+	if err != nil {
+		return 0, err
+	}
+	return parseRegistryNumber(s)
+}
+
+// detectPolicyRestrictions probes Windows policies that affect installation.
+func detectPolicyRestrictions() []PolicyWarning {
+	var warnings []PolicyWarning
+
+	if policyDWORD(appInstallerPolicyKeyPath, "EnableAppInstaller") == 0 {
+		warnings = append(warnings, PolicyWarning{
+			Policy:  "winget",
+			Message: "winget is disabled by Group Policy; installers will be downloaded directly instead",
+		})
+	}
+
+	if policyDWORD(systemPolicyKeyPath, "EnableSmartScreen") == 1 && policyString(systemPolicyKeyPath, "ShellSmartScreenLevel") == "Block" {
+		warnings = append(warnings, PolicyWarning{
+			Policy:  "smartscreen",
+			Message: "SmartScreen is enforced by policy and may block downloaded installers from running",
+		})
+	}
+
+	if err := pathutil.CheckUserPathWritable(); err != nil {
+		warnings = append(warnings, PolicyWarning{
+			Policy:  "registry-environment",
+			Message: fmt.Sprintf("The user PATH cannot be modified (%v); PATH changes may need to be made manually", err),
+		})
+	}
+
+	if applicationControlEnabled() {
+		warnings = append(warnings, PolicyWarning{
+			Policy:  "application-control",
+			Message: "AppLocker or Windows Defender Application Control is active and may block installers or tools",
+		})
+	}
+
+	return warnings
+}
+
+// policyDWORD reads a DWORD policy value from HKLM, returning -1 when it is not set.
+func policyDWORD(keyPath, name string) int {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.QUERY_VALUE)
+	if err != nil {
+		return -1
+	}
+	defer key.Close()
+
+	value, _, err := key.GetIntegerValue(name)
+	if err != nil {
+		return -1
+	}
+	return int(value)
+}
+
+// policyString reads a string policy value from HKLM, returning "" when it is not set.
+func policyString(keyPath, name string) string {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.QUERY_VALUE)
+	if err != nil {
+		return ""
+	}
+	defer key.Close()
+
+	value, _, err := key.GetStringValue(name)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// applicationControlEnabled reports whether AppLocker rules or a WDAC policy are present.
+func applicationControlEnabled() bool {
+	if key, err := registry.OpenKey(registry.LOCAL_MACHINE, appLockerPolicyKeyPath, registry.ENUMERATE_SUB_KEYS); err == nil {
+		subKeys, _ := key.ReadSubKeyNames(-1)
+		key.Close()
+		if len(subKeys) > 0 {
+			return true
+		}
+	}
+
+	// WDAC policies are deployed as SiPolicy.p7b or as multiple .cip files
+	codeIntegrityDir := filepath.Join(os.Getenv("SystemRoot"), "System32", "CodeIntegrity")
+	if _, err := os.Stat(filepath.Join(codeIntegrityDir, "SiPolicy.p7b")); err == nil {
+		return true
+	}
+	entries, err := os.ReadDir(filepath.Join(codeIntegrityDir, "CiPolicies", "Active"))
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if strings.EqualFold(filepath.Ext(entry.Name()), ".cip") {
+			return true
+		}
+	}
+	return false
+}
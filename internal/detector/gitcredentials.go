@@ -0,0 +1,87 @@
+package detector
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// GitCredentialStatus describes how Git authenticates to remotes such as
+// GitHub. Without a credential helper, pushes from Claude Code prompt for a
+// password in a terminal nobody is watching, or fail outright.
+type GitCredentialStatus struct {
+	// Helper is the effective credential.helper value; empty when none is configured.
+	Helper string `json:"helper,omitempty"`
+	// UsesManager reports whether Helper is Git Credential Manager.
+	UsesManager bool `json:"usesManager"`
+	// ManagerInstalled reports whether Git Credential Manager is installed and runs.
+	ManagerInstalled bool   `json:"managerInstalled"`
+	ManagerVersion   string `json:"managerVersion,omitempty"`
+}
+
+// CheckGitCredentials reports the configured Git credential helper and
+// whether Git Credential Manager is available. It returns the zero value
+// when Git is not installed.
+func CheckGitCredentials() GitCredentialStatus {
+	var status GitCredentialStatus
+
+	gitPath := FindGit()
+	if gitPath == "" {
+		return status
+	}
+
+	// Exits non-zero when no helper is configured
+	output, _ := runCommandOutput(gitPath, "config", "--get-all", "credential.helper")
+	status.Helper = effectiveCredentialHelper(output)
+	status.UsesManager = isCredentialManagerHelper(status.Helper)
+
+	// GCM 2.x is invoked as credential-manager; older releases as credential-manager-core
+	for _, command := range []string{"credential-manager", "credential-manager-core"} {
+		if version, err := runCommand(gitPath, command, "--version"); err == nil {
+			status.ManagerInstalled = true
+			status.ManagerVersion = sanitizeVersion(version)
+			break
+		}
+	}
+	return status
+}
+
+// effectiveCredentialHelper returns the helper Git consults first, given the
+// output of `git config --get-all credential.helper`. Helpers accumulate
+// across config files in order, and an empty value clears those before it.
+func effectiveCredentialHelper(output string) string {
+	var helpers []string
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			helpers = nil
+			continue
+		}
+		helpers = append(helpers, line)
+	}
+	if len(helpers) == 0 {
+		return ""
+	}
+	return helpers[0]
+}
+
+// isCredentialManagerHelper reports whether helper refers to Git Credential
+// Manager, either by its short name or by a path to its executable.
+func isCredentialManagerHelper(helper string) bool {
+	helper = strings.ToLower(strings.Trim(strings.TrimSpace(helper), `"'`))
+	switch helper {
+	case "manager", "manager-core":
+		return true
+	}
+	return strings.Contains(helper, "git-credential-manager")
+}
+
+// FindGit locates git, checking common Git directories on Windows.
+// It returns an empty string when git is not found.
+func FindGit() string {
+	gitPath, err := exec.LookPath("git")
+	if err != nil && runtime.GOOS == "windows" {
+		gitPath = findExecutableInPaths("git.exe", commonGitPaths)
+	}
+	return gitPath
+}
@@ -0,0 +1,70 @@
+package detector
+
+import "strings"
+
+// installerCacheGuidance explains the Windows Installer cache and how to
+// reclaim space from it safely.
+const installerCacheGuidance = `Windows keeps a copy of every installed MSI package in C:\Windows\Installer so the program can later be repaired, updated or uninstalled. Do not delete files there by hand: that breaks uninstalling and updating those programs. To reclaim space, uninstall Node.js versions you no longer need from Settings > Apps. Orphaned copies left by earlier installs are no longer used, but should only be removed with a tool that checks which packages Windows still references.`
+
+// InstallerCachePackage is a Node.js MSI package cached by Windows Installer.
+type InstallerCachePackage struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// Product is the product the package installs, such as "Node.js".
+	Product string `json:"product"`
+	// Version is the installed product version; empty for orphaned packages.
+	Version string `json:"version,omitempty"`
+	// Orphaned is set when no installed product refers to the package, as
+	// happens with copies left behind by earlier installs.
+	Orphaned bool `json:"orphaned"`
+}
+
+// InstallerCacheUsage reports the space Node.js MSI packages take up in the
+// Windows Installer cache.
+type InstallerCacheUsage struct {
+	Packages  []InstallerCachePackage `json:"packages"`
+	TotalSize int64                   `json:"totalSize"`
+	// OrphanedSize is the part of TotalSize used by orphaned packages.
+	OrphanedSize int64 `json:"orphanedSize"`
+	// Guidance explains how to reclaim the space safely.
+	Guidance string `json:"guidance"`
+}
+
+// CheckInstallerCache finds the Node.js MSI packages in the Windows Installer
+// cache (%WINDIR%\Installer), both those of installed Node.js versions and
+// orphaned copies left by earlier installs. Nothing is deleted: removing
+// files from the cache by hand breaks repair and uninstall. It returns an
+// error on platforms other than Windows.
+func CheckInstallerCache() (*InstallerCacheUsage, error) {
+	packages, err := nodeInstallerCachePackages()
+	if err != nil {
+		return nil, err
+	}
+	usage := &InstallerCacheUsage{Packages: packages, Guidance: installerCacheGuidance}
+	for _, pkg := range packages {
+		usage.TotalSize += pkg.Size
+		if pkg.Orphaned {
+			usage.OrphanedSize += pkg.Size
+		}
+	}
+	return usage, nil
+}
+
+// NodeMSIInstalled reports whether Windows Installer has a Node.js product
+// registered, so callers can tell an MSI install they started from one that
+// was already present. It is always false on platforms other than Windows.
+func NodeMSIInstalled() bool {
+	for _, product := range registeredMSIPackages() {
+		if isNodeMSIProduct(product.Product) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNodeMSIProduct reports whether an MSI product name, or the subject or
+// author in its summary information ("Node.js", "Node.js Foundation"),
+// identifies a Node.js package.
+func isNodeMSIProduct(name string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(name)), "node.js")
+}
@@ -0,0 +1,146 @@
+//go:build windows
+
+package detector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// installerUserDataKeyPath lists the products Windows Installer has
+// installed, per user SID, with the cached package each was installed from.
+const installerUserDataKeyPath = `SOFTWARE\Microsoft\Windows\CurrentVersion\Installer\UserData`
+
+// Summary information property IDs (PID_SUBJECT, PID_AUTHOR).
+const (
+	msiPIDSubject = 3
+	msiPIDAuthor  = 4
+)
+
+var (
+	msiDLL                         = windows.NewLazySystemDLL("msi.dll")
+	procMsiGetSummaryInformationW  = msiDLL.NewProc("MsiGetSummaryInformationW")
+	procMsiSummaryInfoGetPropertyW = msiDLL.NewProc("MsiSummaryInfoGetPropertyW")
+	procMsiCloseHandle             = msiDLL.NewProc("MsiCloseHandle")
+)
+
+// nodeInstallerCachePackages finds Node.js packages in %WINDIR%\Installer.
+// Packages of installed products are identified by their registration;
+// the rest are orphaned copies, identified by their summary information.
+func nodeInstallerCachePackages() ([]InstallerCachePackage, error) {
+	cacheDir := filepath.Join(os.Getenv("SystemRoot"), "Installer")
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Windows Installer cache: %w", err)
+	}
+
+	registered := registeredMSIPackages()
+	var packages []InstallerCachePackage
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".msi") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(cacheDir, entry.Name())
+
+		if product, ok := registered[strings.ToLower(path)]; ok {
+			if isNodeMSIProduct(product.Product) {
+				product.Path = path
+				product.Size = info.Size()
+				packages = append(packages, product)
+			}
+			continue
+		}
+		if name := msiSummaryNodeProduct(path); name != "" {
+			packages = append(packages, InstallerCachePackage{Path: path, Size: info.Size(), Product: name, Orphaned: true})
+		}
+	}
+	return packages, nil
+}
+
+// registeredMSIPackages maps the lower-cased cached package path of every
+// installed MSI product to its name and version.
+func registeredMSIPackages() map[string]InstallerCachePackage {
+	packages := make(map[string]InstallerCachePackage)
+	root, err := registry.OpenKey(registry.LOCAL_MACHINE, installerUserDataKeyPath, registry.ENUMERATE_SUB_KEYS|registry.WOW64_64KEY)
+	if err != nil {
+		return packages
+	}
+	defer root.Close()
+
+	sids, _ := root.ReadSubKeyNames(-1)
+	for _, sid := range sids {
+		productsPath := sid + `\Products`
+		products, err := registry.OpenKey(root, productsPath, registry.ENUMERATE_SUB_KEYS|registry.WOW64_64KEY)
+		if err != nil {
+			continue
+		}
+		codes, _ := products.ReadSubKeyNames(-1)
+		products.Close()
+
+		for _, code := range codes {
+			key, err := registry.OpenKey(root, productsPath+`\`+code+`\InstallProperties`, registry.QUERY_VALUE|registry.WOW64_64KEY)
+			if err != nil {
+				continue
+			}
+			localPackage, _, _ := key.GetStringValue("LocalPackage")
+			name, _, _ := key.GetStringValue("DisplayName")
+			version, _, _ := key.GetStringValue("DisplayVersion")
+			key.Close()
+			if localPackage != "" {
+				packages[strings.ToLower(localPackage)] = InstallerCachePackage{Product: name, Version: version}
+			}
+		}
+	}
+	return packages
+}
+
+// msiSummaryNodeProduct returns the subject or author of the MSI package at
+// path when it identifies Node.js, or "".
+func msiSummaryNodeProduct(path string) string {
+	if procMsiGetSummaryInformationW.Find() != nil {
+		return ""
+	}
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return ""
+	}
+	var handle uint32
+	if r, _, _ := procMsiGetSummaryInformationW.Call(0, uintptr(unsafe.Pointer(pathPtr)), 0, uintptr(unsafe.Pointer(&handle))); r != 0 {
+		return ""
+	}
+	defer procMsiCloseHandle.Call(uintptr(handle))
+
+	for _, property := range []uint32{msiPIDSubject, msiPIDAuthor} {
+		if value := msiSummaryString(handle, property); isNodeMSIProduct(value) {
+			return value
+		}
+	}
+	return ""
+}
+
+// msiSummaryString reads a string summary information property, or "".
+func msiSummaryString(handle, property uint32) string {
+	var dataType uint32
+	var intValue int32
+	var fileTime windows.Filetime
+	buf := make([]uint16, 256)
+	size := uint32(len(buf))
+	r, _, _ := procMsiSummaryInfoGetPropertyW.Call(uintptr(handle), uintptr(property),
+		uintptr(unsafe.Pointer(&dataType)), uintptr(unsafe.Pointer(&intValue)), uintptr(unsafe.Pointer(&fileTime)),
+		uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)))
+	if r != 0 {
+		// ERROR_MORE_DATA and other failures: a Node.js subject or author is short
+		return ""
+	}
+	return windows.UTF16ToString(buf[:min(int(size), len(buf))])
+}
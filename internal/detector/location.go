@@ -0,0 +1,61 @@
+package detector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ExecutableLocation describes where the installer's own executable runs
+// from. Self-update must replace the executable in place, and running off a
+// network share makes temp extraction and process launches slow and fragile.
+type ExecutableLocation struct {
+	Path     string `json:"path"`
+	Writable bool   `json:"writable"`
+	Network  bool   `json:"network"`
+	// Warning explains why the location may cause problems; empty when it is fine.
+	Warning string `json:"warning,omitempty"`
+}
+
+// CheckExecutableLocation reports whether the running executable is on a
+// writable, local volume.
+func CheckExecutableLocation() ExecutableLocation {
+	exe, err := os.Executable()
+	if err != nil {
+		return ExecutableLocation{Warning: fmt.Sprintf("could not determine the installer's location: %v", err)}
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return checkLocation(exe)
+}
+
+// checkLocation evaluates the executable at path.
+func checkLocation(path string) ExecutableLocation {
+	dir := filepath.Dir(path)
+	location := ExecutableLocation{
+		Path:     path,
+		Writable: dirWritable(dir),
+		Network:  isNetworkPath(dir),
+	}
+
+	switch {
+	case location.Network:
+		location.Warning = "The installer is running from a network location. Copy it to a local folder, such as Downloads, and run it from there."
+	case !location.Writable:
+		location.Warning = "The installer is running from a read-only location, so it cannot update itself. Copy it to a writable local folder to enable updates."
+	}
+	return location
+}
+
+// dirWritable reports whether a file can be created in dir.
+func dirWritable(dir string) bool {
+	f, err := os.CreateTemp(dir, ".write-probe-*")
+	if err != nil {
+		return false
+	}
+	name := f.Name()
+	f.Close()
+	_ = os.Remove(name)
+	return true
+}
@@ -0,0 +1,27 @@
+//go:build darwin
+
+package detector
+
+import (
+	"bytes"
+
+	"golang.org/x/sys/unix"
+)
+
+// networkFilesystems are statfs f_fstypename values of network filesystems.
+var networkFilesystems = map[string]bool{
+	"nfs":    true,
+	"smbfs":  true,
+	"afpfs":  true,
+	"webdav": true,
+}
+
+// isNetworkPath reports whether path is on a network filesystem.
+func isNetworkPath(path string) bool {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return false
+	}
+	name := string(bytes.TrimRight(st.Fstypename[:], "\x00"))
+	return networkFilesystems[name]
+}
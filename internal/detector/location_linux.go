@@ -0,0 +1,23 @@
+//go:build linux
+
+package detector
+
+import "golang.org/x/sys/unix"
+
+// networkFilesystemMagics are statfs f_type values of network filesystems.
+var networkFilesystemMagics = map[int64]bool{
+	0x6969:     true, // NFS
+	0x517B:     true, // SMB
+	0xFF534D42: true, // CIFS
+	0xFE534D42: true, // SMB2
+	0x65735546: true, // FUSE (sshfs and similar)
+}
+
+// isNetworkPath reports whether path is on a network filesystem.
+func isNetworkPath(path string) bool {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return false
+	}
+	return networkFilesystemMagics[int64(st.Type)]
+}
@@ -0,0 +1,8 @@
+//go:build !windows && !linux && !darwin
+
+package detector
+
+// isNetworkPath is not detected on this platform.
+func isNetworkPath(path string) bool {
+	return false
+}
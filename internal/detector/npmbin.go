@@ -0,0 +1,112 @@
+package detector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// npmBinCache caches the global bin directory per npm executable and prefix
+// arguments, since `npm prefix -g` takes most of a second and the prefix
+// rarely changes.
+var npmBinCache = struct {
+	sync.Mutex
+	dirs map[string]string
+}{dirs: make(map[string]string)}
+
+// defaultNpmBinDir is where npm puts global command shims on Windows with
+// its default prefix.
+func defaultNpmBinDir() string {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		return ""
+	}
+	return filepath.Join(appData, "npm")
+}
+
+// NpmGlobalBinDir returns the directory npm writes global command shims to,
+// derived from `npm prefix -g` so a custom prefix is honoured: the prefix
+// itself on Windows and its bin subdirectory elsewhere. The result is cached
+// per npm executable. It returns "" when npm is not found or fails.
+func NpmGlobalBinDir() string {
+	npmPath := findNpmPath()
+	if npmPath == "" {
+		return ""
+	}
+	dir, err := NpmGlobalBinDirFor(npmPath, nil)
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// NpmGlobalBinDirFor is NpmGlobalBinDir for a specific npm executable, with
+// prefixArgs (such as --prefix) appended to `npm prefix -g`. The result is
+// cached per npm executable and prefixArgs until InvalidateNpmGlobalBinDir.
+func NpmGlobalBinDirFor(npmPath string, prefixArgs []string) (string, error) {
+	key := npmPath + "\x00" + strings.Join(prefixArgs, " ")
+
+	npmBinCache.Lock()
+	dir, ok := npmBinCache.dirs[key]
+	npmBinCache.Unlock()
+	if ok {
+		return dir, nil
+	}
+
+	prefix, err := runCommand(npmPath, append([]string{"prefix", "-g"}, prefixArgs...)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get npm global prefix: %w", err)
+	}
+	if strings.TrimSpace(prefix) == "" {
+		return "", fmt.Errorf("npm reported an empty global prefix")
+	}
+	dir = npmBinDirForPrefix(prefix, runtime.GOOS)
+
+	npmBinCache.Lock()
+	npmBinCache.dirs[key] = dir
+	npmBinCache.Unlock()
+	return dir, nil
+}
+
+// InvalidateNpmGlobalBinDir discards the cached global bin directories, for
+// when npm's prefix may have changed, such as after installing Node.js.
+func InvalidateNpmGlobalBinDir() {
+	npmBinCache.Lock()
+	npmBinCache.dirs = make(map[string]string)
+	npmBinCache.Unlock()
+}
+
+// npmBinDirForPrefix returns the global bin directory for an npm prefix on goos.
+func npmBinDirForPrefix(prefix, goos string) string {
+	prefix = strings.TrimSpace(prefix)
+	if goos == "windows" {
+		return prefix
+	}
+	return filepath.Join(prefix, "bin")
+}
+
+// NpmBinDirs lists the directories to look for npm global command shims in on
+// Windows: the configured global bin directory, then the default
+// %APPDATA%\npm, which may still hold shims from before the prefix changed.
+func NpmBinDirs() []string {
+	return uniqueDirs(NpmGlobalBinDir(), defaultNpmBinDir())
+}
+
+// uniqueDirs returns the non-empty dirs in order, without case-insensitive
+// duplicates.
+func uniqueDirs(dirs ...string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		key := strings.ToLower(filepath.Clean(dir))
+		if dir == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, dir)
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package detector
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// IsClaudeRunning reports whether a Claude Code CLI process is currently
+// running, either as a native claude executable or as node running the npm
+// package. Updating Claude Code while it runs can fail with "file in use".
+func IsClaudeRunning() (bool, error) {
+	commandLines, err := listProcessCommandLines()
+	if err != nil {
+		return false, err
+	}
+	for _, cmdline := range commandLines {
+		if isClaudeProcess(cmdline) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listProcessCommandLines returns the command lines of running processes.
+func listProcessCommandLines() ([]string, error) {
+	var output string
+	var err error
+	if runtime.GOOS == "windows" {
+		// tasklist only reports image names, which can't tell node running
+		// claude apart from other node processes
+		output, err = runCommand("powershell", "-NoProfile", "-NonInteractive", "-Command",
+			"Get-CimInstance Win32_Process -Filter \"Name='node.exe' or Name='claude.exe'\" | ForEach-Object { $_.CommandLine }")
+	} else {
+		output, err = runCommand("ps", "-eo", "args=")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// isClaudeProcess reports whether a process command line belongs to the Claude Code CLI.
+func isClaudeProcess(cmdline string) bool {
+	normalized := strings.ToLower(strings.ReplaceAll(cmdline, `\`, "/"))
+	if strings.Contains(normalized, "@anthropic-ai/claude-code/") {
+		return true
+	}
+
+	name := filepath.Base(processExecutable(normalized))
+	return name == "claude" || name == "claude.exe"
+}
+
+// processExecutable returns the executable of a command line, honouring a
+// quoted first token that contains spaces.
+func processExecutable(cmdline string) string {
+	cmdline = strings.TrimSpace(cmdline)
+	if strings.HasPrefix(cmdline, `"`) {
+		if end := strings.Index(cmdline[1:], `"`); end != -1 {
+			return cmdline[1 : end+1]
+		}
+	}
+	fields := strings.Fields(cmdline)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -0,0 +1,80 @@
+package detector
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxProjectFileSize bounds how much of .nvmrc or package.json is read.
+const maxProjectFileSize = 1 * 1024 * 1024
+
+// DetectProjectNodeRequirement reports the Node.js version a project in dir
+// expects: the version or alias in .nvmrc (e.g. "20.11.1" or "lts/iron"), or
+// else the engines.node range in package.json (e.g. ">=18"). It returns an
+// empty string when the project declares no requirement.
+func DetectProjectNodeRequirement(dir string) (string, error) {
+	requirement, err := readNvmrc(filepath.Join(dir, ".nvmrc"))
+	if err != nil || requirement != "" {
+		return requirement, err
+	}
+	return readEnginesNode(filepath.Join(dir, "package.json"))
+}
+
+// readNvmrc returns the version on the first meaningful line of an .nvmrc
+// file, without a leading "v". A missing file yields an empty string.
+func readNvmrc(path string) (string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read .nvmrc: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(io.LimitReader(f, maxProjectFileSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		// nvm allows trailing comments
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		return strings.TrimPrefix(line, "v"), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read .nvmrc: %w", err)
+	}
+	return "", nil
+}
+
+// readEnginesNode returns the engines.node field of a package.json file.
+// A missing file or field yields an empty string.
+func readEnginesNode(path string) (string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read package.json: %w", err)
+	}
+	defer f.Close()
+
+	var manifest struct {
+		Engines map[string]any `json:"engines"`
+	}
+	if err := json.NewDecoder(io.LimitReader(f, maxProjectFileSize)).Decode(&manifest); err != nil {
+		return "", fmt.Errorf("failed to parse package.json: %w", err)
+	}
+	node, _ := manifest.Engines["node"].(string)
+	return strings.TrimSpace(node), nil
+}
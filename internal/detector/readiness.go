@@ -0,0 +1,91 @@
+package detector
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+)
+
+// Readiness weights, in percentage points. Components count once for being
+// installed and again, through readinessWeightPath, for being reachable on
+// PATH.
+const (
+	readinessWeightNodeJS     = 25
+	readinessWeightGit        = 20
+	readinessWeightClaudeCode = 30
+	readinessWeightPath       = 15
+	readinessWeightAuth       = 10
+)
+
+// Readiness summarizes how close the system is to running Claude Code.
+type Readiness struct {
+	// Score is the weighted percentage of readiness checks that pass, 0-100.
+	Score float64 `json:"score"`
+	// MissingSteps lists what is left to do, in the order to do it.
+	MissingSteps []string `json:"missingSteps"`
+}
+
+// CheckReadiness combines the component, PATH and login checks into a single
+// readiness score.
+func CheckReadiness() Readiness {
+	result := SystemCheckResult{
+		NodeJS:          CheckNodeJS(),
+		Git:             CheckGit(),
+		ClaudeCode:      CheckClaudeCode(),
+		ClaudeShadowing: CheckClaudeShadowing(),
+	}
+	return computeReadiness(result, func(name string) bool {
+		_, err := exec.LookPath(name)
+		return err == nil
+	}, IsClaudeLoggedIn())
+}
+
+// computeReadiness scores result. onPath reports whether a command resolves
+// on PATH, and loggedIn whether Claude Code is authenticated.
+func computeReadiness(result SystemCheckResult, onPath func(string) bool, loggedIn bool) Readiness {
+	// A claude on PATH that is some other tool doesn't make Claude Code installed
+	shadowing := result.ClaudeShadowing
+	claudeInstalled := result.ClaudeCode.Installed && (shadowing.Path == "" || shadowing.ClaudeCodePath != "")
+
+	components := []struct {
+		name      string
+		command   string
+		installed bool
+		weight    float64
+	}{
+		{"Node.js", "node", result.NodeJS.Installed, readinessWeightNodeJS},
+		{"Git", "git", result.Git.Installed, readinessWeightGit},
+		{"Claude Code", "claude", claudeInstalled, readinessWeightClaudeCode},
+	}
+
+	readiness := Readiness{MissingSteps: []string{}}
+	var pathSteps []string
+	for _, c := range components {
+		if !c.installed {
+			readiness.MissingSteps = append(readiness.MissingSteps, "Install "+c.name)
+			continue
+		}
+		readiness.Score += c.weight
+
+		switch {
+		case c.command == "claude" && shadowing.Path != "":
+			pathSteps = append(pathSteps, fmt.Sprintf("Move %s ahead of %s on PATH so claude runs Claude Code", shadowing.ClaudeCodePath, shadowing.Path))
+		case c.command == "claude" && shadowing.Alias != "":
+			pathSteps = append(pathSteps, fmt.Sprintf("Remove the claude alias at %s", shadowing.Alias))
+		case !onPath(c.command):
+			pathSteps = append(pathSteps, fmt.Sprintf("Add %s to PATH, then open a new terminal", c.name))
+		default:
+			readiness.Score += readinessWeightPath / float64(len(components))
+		}
+	}
+	readiness.MissingSteps = append(readiness.MissingSteps, pathSteps...)
+
+	if loggedIn {
+		readiness.Score += readinessWeightAuth
+	} else {
+		readiness.MissingSteps = append(readiness.MissingSteps, "Log in to Claude Code")
+	}
+
+	readiness.Score = math.Round(readiness.Score*10) / 10
+	return readiness
+}
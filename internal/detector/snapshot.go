@@ -0,0 +1,165 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"claude-code-installer/internal/pathutil"
+)
+
+// EnvSnapshot is a structured description of the environment the installer
+// manages, used to compare a machine against a known-good baseline.
+type EnvSnapshot struct {
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+	OSBuild int    `json:"osBuild,omitempty"`
+	// Components maps component names to installed versions; an empty
+	// version means the component is not installed.
+	Components    map[string]string `json:"components"`
+	PathEntries   []string          `json:"pathEntries"`
+	NpmPrefix     string            `json:"npmPrefix,omitempty"`
+	NpmRegistry   string            `json:"npmRegistry,omitempty"`
+	WingetVersion string            `json:"wingetVersion,omitempty"`
+}
+
+// Difference is a single way in which two snapshots differ. For PATH entries,
+// Field is "path" and exactly one of Baseline and Current is set.
+type Difference struct {
+	Field    string `json:"field"`
+	Baseline string `json:"baseline"`
+	Current  string `json:"current"`
+}
+
+// TakeSnapshot captures the current environment. PATH entries come from the
+// registry on Windows, so they include changes made since the process
+// started, without modifying the process's own PATH.
+func TakeSnapshot() *EnvSnapshot {
+	pathEnv, err := pathutil.CurrentPath()
+	if err != nil {
+		pathEnv = os.Getenv("PATH")
+	}
+
+	result := CheckAll()
+	snapshot := &EnvSnapshot{
+		OS:   runtime.GOOS,
+		Arch: runtime.GOARCH,
+		Components: map[string]string{
+			"nodejs":     result.NodeJS.Version,
+			"git":        result.Git.Version,
+			"claudecode": result.ClaudeCode.Version,
+		},
+		PathEntries: splitPath(pathEnv),
+	}
+
+	if result.WindowsVersion.Detected {
+		snapshot.OSBuild = result.WindowsVersion.Build
+	}
+
+	if npmPath := findNpmPath(); npmPath != "" {
+		if prefix, err := runCommand(npmPath, "config", "get", "prefix"); err == nil {
+			snapshot.NpmPrefix = prefix
+		}
+		if registry, err := runCommand(npmPath, "config", "get", "registry"); err == nil {
+			snapshot.NpmRegistry = registry
+		}
+	}
+
+	snapshot.WingetVersion = result.Winget.Version
+
+	return snapshot
+}
+
+// CompareSnapshots reports how current differs from baseline.
+func CompareSnapshots(baseline, current *EnvSnapshot) []Difference {
+	var diffs []Difference
+	add := func(field, base, cur string) {
+		if base != cur {
+			diffs = append(diffs, Difference{Field: field, Baseline: base, Current: cur})
+		}
+	}
+
+	add("os", baseline.OS, current.OS)
+	add("arch", baseline.Arch, current.Arch)
+	add("osBuild", formatBuild(baseline.OSBuild), formatBuild(current.OSBuild))
+
+	for _, name := range unionKeys(baseline.Components, current.Components) {
+		add("components."+name, baseline.Components[name], current.Components[name])
+	}
+
+	add("npmPrefix", baseline.NpmPrefix, current.NpmPrefix)
+	add("npmRegistry", baseline.NpmRegistry, current.NpmRegistry)
+	add("wingetVersion", baseline.WingetVersion, current.WingetVersion)
+
+	baseEntries := pathEntrySet(baseline.PathEntries)
+	curEntries := pathEntrySet(current.PathEntries)
+	for _, entry := range baseline.PathEntries {
+		if !curEntries[normalizePathEntry(entry)] {
+			diffs = append(diffs, Difference{Field: "path", Baseline: entry})
+		}
+	}
+	for _, entry := range current.PathEntries {
+		if !baseEntries[normalizePathEntry(entry)] {
+			diffs = append(diffs, Difference{Field: "path", Current: entry})
+		}
+	}
+
+	return diffs
+}
+
+// splitPath splits a PATH value into its non-empty entries.
+func splitPath(pathValue string) []string {
+	var entries []string
+	for _, entry := range filepath.SplitList(pathValue) {
+		if strings.TrimSpace(entry) != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// normalizePathEntry normalizes a PATH entry for comparison. Windows paths
+// are case-insensitive.
+func normalizePathEntry(entry string) string {
+	entry = filepath.Clean(strings.TrimSpace(entry))
+	if runtime.GOOS == "windows" {
+		entry = strings.ToLower(entry)
+	}
+	return entry
+}
+
+// pathEntrySet returns the normalized set of PATH entries.
+func pathEntrySet(entries []string) map[string]bool {
+	set := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		set[normalizePathEntry(entry)] = true
+	}
+	return set
+}
+
+// unionKeys returns the sorted union of the keys of a and b.
+func unionKeys(a, b map[string]string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var keys []string
+	for _, m := range []map[string]string{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatBuild formats an OS build number, leaving unknown builds empty.
+func formatBuild(build int) string {
+	if build == 0 {
+		return ""
+	}
+	return strconv.Itoa(build)
+}
@@ -0,0 +1,44 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// visualStudioNodeGlobs are the directories, relative to Program Files, where
+// Visual Studio installs its bundled node.exe: per year and edition for
+// Visual Studio 2017 and later, and per version for 2015 and earlier.
+var visualStudioNodeGlobs = []string{
+	`Microsoft Visual Studio\*\*\MSBuild\Microsoft\VisualStudio\NodeJs`,
+	`Microsoft Visual Studio\*\*\Web\External`,
+	`Microsoft Visual Studio *\Web\External`,
+}
+
+// FindVisualStudioNode returns the path of a node.exe bundled with Visual
+// Studio, preferring the newest installation, or "" if there is none.
+func FindVisualStudioNode() string {
+	dirs := visualStudioNodeDirs(nonEmpty(os.Getenv("ProgramFiles"), os.Getenv("ProgramFiles(x86)")))
+	return findExecutableInPaths("node.exe", dirs)
+}
+
+// visualStudioNodeDirs expands visualStudioNodeGlobs under each Program Files
+// root. Matches are sorted in reverse so later years and versions come first.
+func visualStudioNodeDirs(programFiles []string) []string {
+	var dirs []string
+	for _, root := range programFiles {
+		for _, pattern := range visualStudioNodeGlobs {
+			matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(strings.ReplaceAll(pattern, `\`, "/"))))
+			for idx := len(matches) - 1; idx >= 0; idx-- {
+				dirs = append(dirs, matches[idx])
+			}
+		}
+	}
+	return dirs
+}
+
+// isVisualStudioPath reports whether path lies inside a Visual Studio
+// installation directory.
+func isVisualStudioPath(path string) bool {
+	return strings.Contains(strings.ToLower(strings.ReplaceAll(path, `\`, "/")), "/microsoft visual studio")
+}
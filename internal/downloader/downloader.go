@@ -0,0 +1,416 @@
+// Package downloader provides a reusable HTTP file downloader with retry logic,
+// size limits, progress reporting, and optional SHA-256 checksum verification.
+package downloader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxSize is the default maximum size of a single download.
+	DefaultMaxSize = 500 * 1024 * 1024 // 500 MB
+	// DefaultMaxRetries is the default number of download attempts.
+	DefaultMaxRetries = 3
+	// DefaultInitialBackoff is the delay before the first retry; it doubles on each attempt.
+	DefaultInitialBackoff = 1 * time.Second
+)
+
+// ErrChecksumMismatch is returned when a downloaded file does not match its expected hash.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ErrUnexpectedContent is returned when a server answers with something other
+// than the expected kind of file, typically an HTML page from a captive portal
+// or proxy that responds with status 200.
+var ErrUnexpectedContent = errors.New("server returned unexpected content")
+
+// File signatures (leading "magic" bytes) of the binary formats downloaded.
+var (
+	// MagicMSI starts an OLE compound file, the container MSI packages use.
+	MagicMSI = []byte{0xD0, 0xCF, 0x11, 0xE0}
+	// MagicPE starts a Windows executable.
+	MagicPE = []byte("MZ")
+	// MagicZip starts a zip archive.
+	MagicZip = []byte("PK\x03\x04")
+)
+
+// sniffLength is how much of a response is inspected before it is written.
+const sniffLength = 512
+
+// ErrNotModified is returned when a conditional download finds that the file
+// has not changed since the validators were issued. Nothing is written.
+var ErrNotModified = errors.New("not modified")
+
+// Validators are the cache validators a server returned with a file, used to
+// make a later download of it conditional.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// IsZero reports whether the server returned no validators.
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// Error describes a failed download attempt and whether retrying it may succeed.
+type Error struct {
+	// StatusCode is the HTTP status code, or 0 if no response was received.
+	StatusCode int
+	// Retryable reports whether the failure is transient.
+	Retryable bool
+	Err       error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether err is a transient download failure worth retrying.
+// Network errors, timeouts, 5xx and 429 responses are retryable; other 4xx
+// responses, checksum mismatches, and oversized files are not.
+func IsRetryable(err error) bool {
+	var dlErr *Error
+	if errors.As(err, &dlErr) {
+		return dlErr.Retryable
+	}
+	return false
+}
+
+// isRetryableStatus reports whether an HTTP status code indicates a transient failure.
+func isRetryableStatus(code int) bool {
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+// Result describes a completed download.
+type Result struct {
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+	Attempts int    `json:"attempts"`
+	// Validators are the server's ETag and Last-Modified for the file.
+	Validators Validators `json:"validators"`
+}
+
+// Downloader downloads files over HTTP with retries and progress reporting.
+type Downloader struct {
+	// Client is the HTTP client used for requests.
+	Client *http.Client
+	// MaxSize is the maximum number of bytes accepted for a single download.
+	MaxSize int64
+	// MaxRetries is the total number of attempts made before giving up.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// OnProgress, if set, is called as bytes are received. totalSize is -1 when unknown.
+	OnProgress func(bytesRead, totalSize int64)
+	// OnRetry, if set, is called before waiting to retry a failed attempt.
+	OnRetry func(nextAttempt, maxRetries int, backoff time.Duration, err error)
+	// Conditional, if set, sends If-None-Match and If-Modified-Since so that
+	// an unchanged file ends the download with ErrNotModified.
+	Conditional Validators
+	// ExpectedMagic, if set, lists the signatures the file may start with. A
+	// response served as HTML, or starting with none of them, is rejected
+	// with ErrUnexpectedContent before anything is written. See
+	// MagicForFilename.
+	ExpectedMagic [][]byte
+	// Checkpoint, if set, is called before each attempt and each read of the
+	// response body. It may block, for example while paused; an error stops
+	// the download without retrying and is returned as is.
+	Checkpoint func() error
+}
+
+// New creates a Downloader using the given HTTP client and progress callback.
+func New(client *http.Client, onProgress func(bytesRead, totalSize int64)) *Downloader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Downloader{
+		Client:         client,
+		MaxSize:        DefaultMaxSize,
+		MaxRetries:     DefaultMaxRetries,
+		InitialBackoff: DefaultInitialBackoff,
+		OnProgress:     onProgress,
+	}
+}
+
+// Download fetches url into destPath, retrying with exponential backoff on failure.
+// If expectedSHA256 is non-empty, the downloaded file is verified against it and
+// removed on mismatch.
+func (d *Downloader) Download(ctx context.Context, url, destPath, expectedSHA256 string) (*Result, error) {
+	maxRetries := d.MaxRetries
+	if maxRetries < 1 {
+		maxRetries = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if d.Checkpoint != nil {
+			if err := d.Checkpoint(); err != nil {
+				return nil, err
+			}
+		}
+		result, err := d.downloadOnce(ctx, url, destPath)
+		if err == nil {
+			result.Attempts = attempt + 1
+			if expectedSHA256 != "" && !strings.EqualFold(result.SHA256, expectedSHA256) {
+				os.Remove(destPath)
+				return nil, fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, expectedSHA256, result.SHA256)
+			}
+			return result, nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("download cancelled: %w", ctx.Err())
+		}
+		if !IsRetryable(err) {
+			return nil, err
+		}
+		if attempt < maxRetries-1 {
+			backoff := d.InitialBackoff * time.Duration(1<<uint(attempt))
+			if d.OnRetry != nil {
+				d.OnRetry(attempt+2, maxRetries, backoff, err)
+			}
+			select {
+			case <-time.After(backoff):
+				// continue retry
+			case <-ctx.Done():
+				return nil, fmt.Errorf("download cancelled: %w", ctx.Err())
+			}
+		}
+	}
+	return nil, fmt.Errorf("download failed after %d attempts: %w", maxRetries, lastErr)
+}
+
+// downloadOnce performs a single download attempt, hashing the content as it is written.
+func (d *Downloader) downloadOnce(ctx context.Context, url, destPath string) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+	if d.Conditional.ETag != "" {
+		req.Header.Set("If-None-Match", d.Conditional.ETag)
+	}
+	if d.Conditional.LastModified != "" {
+		req.Header.Set("If-Modified-Since", d.Conditional.LastModified)
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		// Network errors and timeouts are transient
+		return nil, &Error{Retryable: true, Err: fmt.Errorf("failed to download file: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && !d.Conditional.IsZero() {
+		return nil, ErrNotModified
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &Error{
+			StatusCode: resp.StatusCode,
+			Retryable:  isRetryableStatus(resp.StatusCode),
+			Err:        fmt.Errorf("download returned status %d", resp.StatusCode),
+		}
+	}
+
+	totalSize := resp.ContentLength
+	if d.MaxSize > 0 && totalSize > d.MaxSize {
+		return nil, &Error{
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("file too large: %d bytes exceeds limit of %d", totalSize, d.MaxSize),
+		}
+	}
+
+	var respBody io.Reader = resp.Body
+	if len(d.ExpectedMagic) > 0 {
+		buffered := bufio.NewReaderSize(resp.Body, sniffLength)
+		// A short body yields fewer bytes, which CheckContent rejects
+		head, _ := buffered.Peek(sniffLength)
+		if err := CheckContent(resp.Header.Get("Content-Type"), head, d.ExpectedMagic); err != nil {
+			return nil, &Error{StatusCode: resp.StatusCode, Err: err}
+		}
+		respBody = buffered
+	}
+
+	// Create destination file with restricted permissions (owner read/write only)
+	out, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, &Error{Err: fmt.Errorf("failed to create file %s: %w", destPath, err)}
+	}
+
+	progress := &progressReader{
+		reader:     respBody,
+		totalSize:  totalSize,
+		onProgress: d.OnProgress,
+		checkpoint: d.Checkpoint,
+	}
+	var body io.Reader = progress
+	if d.MaxSize > 0 {
+		// Read one byte past the limit so oversized bodies are detected rather than truncated
+		body = io.LimitReader(body, d.MaxSize+1)
+	}
+
+	h := sha256.New()
+	written, copyErr := io.Copy(io.MultiWriter(out, h), body)
+	closeErr := out.Close()
+
+	// Check copy error BEFORE close error to avoid treating a corrupted file as success
+	if progress.stopErr != nil {
+		os.Remove(destPath)
+		return nil, progress.stopErr
+	}
+	if copyErr != nil {
+		os.Remove(destPath) // clean up partial file
+		// An interrupted body read is usually a dropped connection
+		return nil, &Error{Retryable: true, Err: fmt.Errorf("failed to write downloaded file: %w", copyErr)}
+	}
+	if d.MaxSize > 0 && written > d.MaxSize {
+		os.Remove(destPath)
+		return nil, &Error{Err: fmt.Errorf("file too large: exceeds limit of %d bytes", d.MaxSize)}
+	}
+	if closeErr != nil {
+		os.Remove(destPath)
+		return nil, &Error{Err: fmt.Errorf("failed to finalize downloaded file: %w", closeErr)}
+	}
+
+	return &Result{
+		Path:   destPath,
+		Size:   written,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+		Validators: Validators{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		},
+	}, nil
+}
+
+// MagicForFilename returns the signatures a file named name must start with,
+// based on its extension, or nil when its format has no known signature.
+func MagicForFilename(name string) [][]byte {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".msi":
+		return [][]byte{MagicMSI}
+	case ".exe":
+		return [][]byte{MagicPE}
+	case ".zip":
+		return [][]byte{MagicZip}
+	}
+	return nil
+}
+
+// CheckContent checks that a response with the given Content-Type whose body
+// starts with head is a binary file starting with one of magic, rather than
+// an HTML page such as a captive portal's sign-in page.
+func CheckContent(contentType string, head []byte, magic [][]byte) error {
+	if isHTML(contentType, head) {
+		return fmt.Errorf("%w: the server returned an HTML page instead of a binary file, possibly a captive portal or proxy sign-in page", ErrUnexpectedContent)
+	}
+	for _, m := range magic {
+		if bytes.HasPrefix(head, m) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: the file does not start with the expected signature (got %q)", ErrUnexpectedContent, head[:min(len(head), 8)])
+}
+
+// isHTML reports whether a response is an HTML page, by its declared
+// Content-Type or, since portals don't always declare it, its content.
+func isHTML(contentType string, head []byte) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		return true
+	}
+	return strings.HasPrefix(http.DetectContentType(head), "text/html")
+}
+
+// VerifyFileChecksum computes the SHA-256 hash of a file and compares it against an expected hash.
+func VerifyFileChecksum(filePath, expectedHash string) error {
+	return VerifyFileChecksumWithProgress(filePath, expectedHash, nil)
+}
+
+// VerifyFileChecksumWithProgress is VerifyFileChecksum with progress reporting
+// while the file is hashed, for large files on slow disks.
+func VerifyFileChecksumWithProgress(filePath, expectedHash string, onProgress func(bytesRead, totalSize int64)) error {
+	actualHash, err := FileSHA256WithProgress(filePath, onProgress)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(actualHash, expectedHash) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedHash, actualHash)
+	}
+
+	return nil
+}
+
+// FileSHA256 returns the hex-encoded SHA-256 hash of a file.
+func FileSHA256(filePath string) (string, error) {
+	return FileSHA256WithProgress(filePath, nil)
+}
+
+// FileSHA256WithProgress returns the hex-encoded SHA-256 hash of a file,
+// calling onProgress as the file is read.
+func FileSHA256WithProgress(filePath string, onProgress func(bytesRead, totalSize int64)) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file for checksum verification: %w", err)
+	}
+	defer f.Close()
+
+	totalSize := int64(-1)
+	if info, err := f.Stat(); err == nil {
+		totalSize = info.Size()
+	}
+
+	h := sha256.New()
+	reader := &progressReader{reader: f, totalSize: totalSize, onProgress: onProgress}
+	if _, err := io.Copy(h, reader); err != nil {
+		return "", fmt.Errorf("failed to compute checksum: %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// progressReader wraps an io.Reader to track read progress.
+type progressReader struct {
+	reader     io.Reader
+	totalSize  int64
+	bytesRead  int64
+	onProgress func(bytesRead, totalSize int64)
+	// checkpoint is polled before each read; stopErr records its error.
+	checkpoint func() error
+	stopErr    error
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	if pr.checkpoint != nil {
+		if err := pr.checkpoint(); err != nil {
+			pr.stopErr = err
+			return 0, err
+		}
+	}
+	n, err := pr.reader.Read(p)
+	pr.bytesRead += int64(n)
+	if pr.onProgress != nil {
+		pr.onProgress(pr.bytesRead, pr.totalSize)
+	}
+	return n, err
+}
@@ -0,0 +1,290 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// SHA-256 of "hello world"
+const helloWorldHash = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+
+func newTestDownloader(server *httptest.Server) *Downloader {
+	d := New(server.Client(), nil)
+	d.InitialBackoff = time.Millisecond
+	return d
+}
+
+func TestDownload_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "file.txt")
+	var lastRead int64
+	d := newTestDownloader(server)
+	d.OnProgress = func(bytesRead, totalSize int64) {
+		lastRead = bytesRead
+	}
+
+	result, err := d.Download(context.Background(), server.URL, dest, helloWorldHash)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Size != 11 || result.SHA256 != helloWorldHash || result.Attempts != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if lastRead != 11 {
+		t.Errorf("progress reported %d bytes, want 11", lastRead)
+	}
+	if err := VerifyFileChecksum(dest, helloWorldHash); err != nil {
+		t.Errorf("downloaded file failed verification: %v", err)
+	}
+}
+
+func TestDownload_ChecksumMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "file.txt")
+	_, err := newTestDownloader(server).Download(context.Background(), server.URL, dest, "00")
+	if err == nil {
+		t.Fatal("expected checksum mismatch error")
+	}
+	if _, statErr := os.Stat(dest); !os.IsNotExist(statErr) {
+		t.Error("expected file to be removed after checksum mismatch")
+	}
+}
+
+func TestDownload_RetriesThenSucceeds(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "file.txt")
+	result, err := newTestDownloader(server).Download(context.Background(), server.URL, dest, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Attempts != 2 {
+		t.Errorf("got %d attempts, want 2", result.Attempts)
+	}
+}
+
+func TestDownload_TooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "file.txt")
+	d := newTestDownloader(server)
+	d.MaxSize = 5
+	d.MaxRetries = 1
+	if _, err := d.Download(context.Background(), server.URL, dest, ""); err == nil {
+		t.Error("expected error for oversized download")
+	}
+}
+
+func TestDownload_RetryClassification(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		expectedCalls int32
+		retryable     bool
+	}{
+		{"not found is permanent", http.StatusNotFound, 1, false},
+		{"forbidden is permanent", http.StatusForbidden, 1, false},
+		{"server error is retried", http.StatusInternalServerError, 3, true},
+		{"rate limit is retried", http.StatusTooManyRequests, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			dest := filepath.Join(t.TempDir(), "file.txt")
+			_, err := newTestDownloader(server).Download(context.Background(), server.URL, dest, "")
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.expectedCalls {
+				t.Errorf("got %d requests, want %d", got, tt.expectedCalls)
+			}
+			if IsRetryable(err) != tt.retryable {
+				t.Errorf("IsRetryable(%v) = %v, want %v", err, IsRetryable(err), tt.retryable)
+			}
+		})
+	}
+}
+
+func TestDownload_ChecksumMismatchNotRetried(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "file.txt")
+	_, err := newTestDownloader(server).Download(context.Background(), server.URL, dest, "00")
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("expected ErrChecksumMismatch, got %v", err)
+	}
+	if IsRetryable(err) {
+		t.Error("checksum mismatch should not be retryable")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+func TestVerifyFileChecksumWithProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("hello world"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	var lastRead, lastTotal int64
+	err := VerifyFileChecksumWithProgress(path, helloWorldHash, func(bytesRead, totalSize int64) {
+		lastRead, lastTotal = bytesRead, totalSize
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lastRead != 11 || lastTotal != 11 {
+		t.Errorf("progress reported %d/%d bytes, want 11/11", lastRead, lastTotal)
+	}
+}
+
+func TestDownload_Conditional(t *testing.T) {
+	const etag = `"v1"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "file.txt")
+	d := newTestDownloader(server)
+	result, err := d.Download(context.Background(), server.URL, dest, helloWorldHash)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Validators.ETag != etag || result.Validators.LastModified == "" {
+		t.Errorf("Validators = %+v, want the server's ETag and Last-Modified", result.Validators)
+	}
+
+	os.Remove(dest)
+	d.Conditional = result.Validators
+	if _, err := d.Download(context.Background(), server.URL, dest, ""); !errors.Is(err, ErrNotModified) {
+		t.Fatalf("conditional download error = %v, want ErrNotModified", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("a not-modified download wrote %s", dest)
+	}
+}
+
+func TestDownload_Checkpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	stop := errors.New("stopped")
+	var calls int32
+	d := newTestDownloader(server)
+	d.Checkpoint = func() error {
+		// Allow the attempt to start, then stop while reading the body
+		if atomic.AddInt32(&calls, 1) > 1 {
+			return stop
+		}
+		return nil
+	}
+
+	dest := filepath.Join(t.TempDir(), "file.txt")
+	if _, err := d.Download(context.Background(), server.URL, dest, ""); !errors.Is(err, stop) {
+		t.Fatalf("Download() error = %v, want the checkpoint error", err)
+	}
+	if calls != 2 {
+		t.Errorf("checkpoint called %d times, want 2 (no retry)", calls)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("partial file %s was left behind", dest)
+	}
+}
+
+func TestDownload_ExpectedMagic(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     bool
+	}{
+		{"msi", "application/octet-stream", "\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1", false},
+		{"html declared", "text/html; charset=utf-8", "\xD0\xCF\x11\xE0", true},
+		{"html sniffed", "application/octet-stream", "<!DOCTYPE html><html><body>Sign in to Wi-Fi</body></html>", true},
+		{"wrong format", "application/octet-stream", "MZ\x90\x00", true},
+		{"empty", "application/octet-stream", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			dest := filepath.Join(t.TempDir(), "node.msi")
+			d := newTestDownloader(server)
+			d.ExpectedMagic = MagicForFilename(dest)
+			_, err := d.Download(context.Background(), server.URL, dest, "")
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrUnexpectedContent) {
+				t.Fatalf("Download() = %v, want ErrUnexpectedContent", err)
+			}
+			if requests.Load() != 1 {
+				t.Errorf("server received %d requests, want 1 (not retried)", requests.Load())
+			}
+			if _, statErr := os.Stat(dest); !os.IsNotExist(statErr) {
+				t.Error("rejected content was written to disk")
+			}
+		})
+	}
+
+	if MagicForFilename("SHASUMS256.txt") != nil {
+		t.Error("MagicForFilename() returned a signature for a text file")
+	}
+}
@@ -0,0 +1,74 @@
+// Package elevation runs narrowly-scoped privileged operations by re-launching
+// the installer executable elevated with a specific sub-command, instead of
+// requiring the whole application to run as administrator.
+package elevation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"claude-code-installer/internal/pathutil"
+)
+
+const (
+	// commandFlag marks a command line as an elevated sub-operation.
+	commandFlag = "--elevated-op"
+
+	// OpAddSystemPath adds a directory to the machine-wide PATH.
+	OpAddSystemPath = "add-system-path"
+	// OpUninstallMSI uninstalls the product of an MSI package quietly.
+	OpUninstallMSI = "uninstall-msi"
+)
+
+// ErrDeclined is returned when the user declines the elevation prompt.
+var ErrDeclined = errors.New("elevation was declined")
+
+// HandleCommandLine runs an elevated sub-operation if args request one.
+// It reports whether args were handled and the process exit code to use.
+func HandleCommandLine(args []string) (bool, int) {
+	if len(args) == 0 || args[0] != commandFlag {
+		return false, 0
+	}
+	if err := runOperation(args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "elevated operation failed: %v\n", err)
+		return true, 1
+	}
+	return true, 0
+}
+
+// runOperation dispatches an allowlisted elevated sub-operation.
+func runOperation(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing operation")
+	}
+
+	switch args[0] {
+	case OpAddSystemPath:
+		if len(args) != 2 {
+			return fmt.Errorf("%s requires exactly one directory argument", OpAddSystemPath)
+		}
+		return pathutil.AddToSystemPath(args[1])
+	case OpUninstallMSI:
+		if len(args) != 2 {
+			return fmt.Errorf("%s requires exactly one package argument", OpUninstallMSI)
+		}
+		if !strings.EqualFold(filepath.Ext(args[1]), ".msi") {
+			return fmt.Errorf("%s requires an .msi package, got %q", OpUninstallMSI, args[1])
+		}
+		if _, err := os.Stat(args[1]); err != nil {
+			return err
+		}
+		return exec.Command("msiexec", "/qn", "/x", args[1]).Run()
+	default:
+		return fmt.Errorf("unknown operation %q", args[0])
+	}
+}
+
+// operationArgs builds the command line arguments for an elevated sub-operation.
+func operationArgs(op string, args ...string) []string {
+	return append([]string{commandFlag, op}, args...)
+}
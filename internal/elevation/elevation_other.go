@@ -0,0 +1,18 @@
+//go:build !windows
+
+package elevation
+
+import (
+	"fmt"
+	"time"
+)
+
+// Run is not supported on non-Windows platforms.
+func Run(op string, args ...string) error {
+	return fmt.Errorf("elevated operations are only supported on Windows")
+}
+
+// RunWithin is not supported on non-Windows platforms.
+func RunWithin(timeout time.Duration, op string, args ...string) error {
+	return Run(op, args...)
+}
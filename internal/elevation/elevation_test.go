@@ -0,0 +1,32 @@
+package elevation
+
+import "testing"
+
+func TestHandleCommandLine_NotElevatedOp(t *testing.T) {
+	if handled, _ := HandleCommandLine(nil); handled {
+		t.Error("empty args should not be handled")
+	}
+	if handled, _ := HandleCommandLine([]string{"--some-flag"}); handled {
+		t.Error("unrelated args should not be handled")
+	}
+}
+
+func TestHandleCommandLine_RejectsUnknownOperation(t *testing.T) {
+	handled, code := HandleCommandLine([]string{commandFlag, "delete-everything"})
+	if !handled || code == 0 {
+		t.Errorf("HandleCommandLine = %v, %d; want handled with non-zero exit code", handled, code)
+	}
+}
+
+func TestHandleCommandLine_ValidatesArguments(t *testing.T) {
+	for _, args := range [][]string{
+		operationArgs(OpAddSystemPath),
+		operationArgs(OpUninstallMSI),
+		operationArgs(OpUninstallMSI, `C:\Windows\System32\cmd.exe`),
+	} {
+		handled, code := HandleCommandLine(args)
+		if !handled || code == 0 {
+			t.Errorf("HandleCommandLine(%q) = %v, %d; want handled with non-zero exit code", args, handled, code)
+		}
+	}
+}
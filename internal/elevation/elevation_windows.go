@@ -0,0 +1,120 @@
+//go:build windows
+
+package elevation
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// seeMaskNoCloseProcess is SEE_MASK_NOCLOSEPROCESS, which returns the process handle.
+	seeMaskNoCloseProcess = 0x00000040
+	// swHide is SW_HIDE; the elevated sub-operation has no UI of its own.
+	swHide = 0
+	// errorCancelled is ERROR_CANCELLED, returned when the UAC prompt is dismissed.
+	errorCancelled = syscall.Errno(1223)
+	// elevatedWaitTimeout bounds how long Run waits for the elevated process.
+	elevatedWaitTimeout = 2 * time.Minute
+)
+
+// shellExecuteInfo mirrors the Win32 SHELLEXECUTEINFOW structure.
+type shellExecuteInfo struct {
+	cbSize         uint32
+	fMask          uint32
+	hwnd           uintptr
+	lpVerb         *uint16
+	lpFile         *uint16
+	lpParameters   *uint16
+	lpDirectory    *uint16
+	nShow          int32
+	hInstApp       uintptr
+	lpIDList       uintptr
+	lpClass        *uint16
+	hkeyClass      uintptr
+	dwHotKey       uint32
+	hIconOrMonitor uintptr
+	hProcess       windows.Handle
+}
+
+// Run re-launches the current executable elevated (ShellExecute "runas") to
+// perform a single allowlisted sub-operation and waits for it to finish.
+func Run(op string, args ...string) error {
+	return RunWithin(elevatedWaitTimeout, op, args...)
+}
+
+// RunWithin is Run, waiting at most timeout for the sub-operation. On timeout
+// the elevated process is left to finish on its own.
+func RunWithin(timeout time.Duration, op string, args ...string) error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	quoted := make([]string, 0, len(args)+2)
+	for _, arg := range operationArgs(op, args...) {
+		quoted = append(quoted, windows.EscapeArg(arg))
+	}
+
+	verb, err := syscall.UTF16PtrFromString("runas")
+	if err != nil {
+		return fmt.Errorf("failed to convert string: %w", err)
+	}
+	file, err := syscall.UTF16PtrFromString(exePath)
+	if err != nil {
+		return fmt.Errorf("failed to convert string: %w", err)
+	}
+	params, err := syscall.UTF16PtrFromString(strings.Join(quoted, " "))
+	if err != nil {
+		return fmt.Errorf("failed to convert string: %w", err)
+	}
+
+	info := shellExecuteInfo{
+		fMask:        seeMaskNoCloseProcess,
+		lpVerb:       verb,
+		lpFile:       file,
+		lpParameters: params,
+		nShow:        swHide,
+	}
+	info.cbSize = uint32(unsafe.Sizeof(info))
+
+	shell32 := syscall.NewLazyDLL("shell32.dll")
+	shellExecuteEx := shell32.NewProc("ShellExecuteExW")
+
+	// Win32 ShellExecuteExW takes a pointer to SHELLEXECUTEINFOW; unsafe
+	// conversion is necessary to satisfy the syscall ABI.
+	ret, _, callErr := shellExecuteEx.Call(uintptr(unsafe.Pointer(&info)))
+	if ret == 0 {
+		if callErr == errorCancelled {
+			return ErrDeclined
+		}
+		return fmt.Errorf("ShellExecuteEx failed: %v", callErr)
+	}
+	if info.hProcess == 0 {
+		return fmt.Errorf("elevated process handle was not returned")
+	}
+	defer windows.CloseHandle(info.hProcess)
+
+	event, err := windows.WaitForSingleObject(info.hProcess, uint32(timeout.Milliseconds()))
+	if err != nil {
+		return fmt.Errorf("failed waiting for elevated process: %w", err)
+	}
+	if event != windows.WAIT_OBJECT_0 {
+		return fmt.Errorf("timed out waiting for elevated process")
+	}
+
+	var exitCode uint32
+	if err := windows.GetExitCodeProcess(info.hProcess, &exitCode); err != nil {
+		return fmt.Errorf("failed to read elevated process exit code: %w", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("elevated operation %s exited with code %d", op, exitCode)
+	}
+	return nil
+}
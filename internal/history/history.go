@@ -0,0 +1,101 @@
+// Package history records the outcome of installer operations to a JSON lines
+// file so interrupted or failed runs can be inspected after the app restarts.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// historyFileName is the name of the history file in the app config directory.
+	historyFileName = "history.jsonl"
+	// appConfigDirName is the per-user configuration directory for the installer.
+	appConfigDirName = "claude-code-installer"
+)
+
+// Status values recorded for an operation.
+const (
+	StatusCompleted   = "completed"
+	StatusError       = "error"
+	StatusInterrupted = "interrupted"
+)
+
+// Entry describes the outcome of a single installer operation.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Status    string    `json:"status"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// DefaultPath returns the history file location in the user's config directory.
+func DefaultPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, appConfigDirName, historyFileName), nil
+}
+
+// Append writes an entry to the history file, creating it if necessary.
+func Append(path string, entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+
+	_, writeErr := f.Write(append(data, '\n'))
+	closeErr := f.Close()
+	if writeErr != nil {
+		return fmt.Errorf("failed to write history entry: %w", writeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to finalize history file: %w", closeErr)
+	}
+	return nil
+}
+
+// Load reads all entries from the history file. A missing file yields no entries.
+// Malformed lines are skipped so a partially-written entry does not hide the rest.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return entries, nil
+}
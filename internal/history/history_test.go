@@ -0,0 +1,153 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", historyFileName)
+
+	if err := Append(path, Entry{Operation: "install", Status: StatusCompleted}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Append(path, Entry{Operation: "install", Status: StatusInterrupted, Message: "app closed"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[1].Status != StatusInterrupted || entries[1].Time.IsZero() {
+		t.Errorf("unexpected entry: %+v", entries[1])
+	}
+}
+
+func TestLoad_SkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), historyFileName)
+	content := "{\"operation\":\"install\",\"status\":\"error\"}\n{not json\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d entries, want 1", len(entries))
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	entries, err := Load(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if err != nil || entries != nil {
+		t.Errorf("Load on missing file = %v, %v; want nil, nil", entries, err)
+	}
+}
+
+func TestAppendAndLoadVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", versionsFileName)
+
+	if err := AppendVersion(path, VersionEntry{Component: "claudecode", Previous: "1.0.0", Installed: "1.1.0"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := AppendVersion(path, VersionEntry{Component: "nodejs", Previous: "20.0.0", Installed: "22.0.0"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := LoadVersions(path, "claudecode")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Installed != "1.1.0" || entries[0].Time.IsZero() {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	all, err := LoadVersions(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("got %d entries, want 2", len(all))
+	}
+}
+
+func TestRollbackTarget(t *testing.T) {
+	updates := []VersionEntry{
+		{Previous: "1.0.0", Installed: "1.1.0"},
+		{Previous: "1.1.0", Installed: "1.2.0"},
+	}
+	afterRollback := append(updates[:2:2], VersionEntry{Previous: "1.2.0", Installed: "1.1.0", Rollback: true})
+
+	tests := []struct {
+		name    string
+		entries []VersionEntry
+		current string
+		want    string
+		wantOK  bool
+	}{
+		{name: "no history", current: "1.0.0"},
+		{name: "latest update", entries: updates, current: "1.2.0", want: "1.1.0", wantOK: true},
+		{name: "rolls back further", entries: afterRollback, current: "1.1.0", want: "1.0.0", wantOK: true},
+		{name: "unknown current", entries: updates, current: "", want: "1.1.0", wantOK: true},
+		{name: "nothing older", entries: updates[:1], current: "1.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RollbackTarget(tt.entries, tt.current)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("RollbackTarget() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRunState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", runStateFileName)
+
+	if state, err := LoadRunState(path); err != nil || state != nil {
+		t.Fatalf("LoadRunState on missing file = %v, %v; want nil, nil", state, err)
+	}
+
+	err := SaveRunState(path, RunState{
+		Components: []string{"nodejs", "git", "claudecode"},
+		Completed:  []string{"nodejs"},
+		Current:    "git",
+	})
+	if err != nil {
+		t.Fatalf("SaveRunState() error: %v", err)
+	}
+	state, err := LoadRunState(path)
+	if err != nil || state == nil {
+		t.Fatalf("LoadRunState() = %v, %v", state, err)
+	}
+	if state.Current != "git" || state.Started.IsZero() || state.Updated.IsZero() {
+		t.Errorf("unexpected state: %+v", state)
+	}
+	if remaining := state.Remaining(); len(remaining) != 2 || remaining[0] != "git" || remaining[1] != "claudecode" {
+		t.Errorf("Remaining() = %v, want [git claudecode]", remaining)
+	}
+
+	if err := ClearRunState(path); err != nil {
+		t.Fatalf("ClearRunState() error: %v", err)
+	}
+	if err := ClearRunState(path); err != nil {
+		t.Errorf("ClearRunState() on missing file error: %v", err)
+	}
+	if state, _ := LoadRunState(path); state != nil {
+		t.Errorf("LoadRunState() after clear = %+v, want nil", state)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := LoadRunState(path); err != nil || state != nil {
+		t.Errorf("LoadRunState on malformed file = %v, %v; want nil, nil", state, err)
+	}
+}
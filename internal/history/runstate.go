@@ -0,0 +1,108 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// runStateFileName is the name of the InstallAll run state file in the app
+// config directory.
+const runStateFileName = "install-run.json"
+
+// RunState records how far an InstallAll run got, so a run that failed or
+// was cut short by a crash or closed app can be resumed at the first
+// component it did not finish. It is written as the run progresses and
+// removed once every component has been dealt with.
+type RunState struct {
+	Started time.Time `json:"started"`
+	Updated time.Time `json:"updated"`
+	// Components are the components the run set out to install, in order.
+	Components []string `json:"components"`
+	// Completed are the components that were installed, already present or
+	// skipped by the user.
+	Completed []string `json:"completed,omitempty"`
+	// Failed are the components that failed or were not attempted because a
+	// dependency failed.
+	Failed []string `json:"failed,omitempty"`
+	// Current is the component being installed when the state was saved;
+	// still set afterwards, it means the run was interrupted mid-component.
+	Current string `json:"current,omitempty"`
+}
+
+// Remaining returns the components the run did not complete, in order.
+func (s *RunState) Remaining() []string {
+	var remaining []string
+	for _, component := range s.Components {
+		if !slices.Contains(s.Completed, component) {
+			remaining = append(remaining, component)
+		}
+	}
+	return remaining
+}
+
+// DefaultRunStatePath returns the run state file location in the user's
+// config directory.
+func DefaultRunStatePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, appConfigDirName, runStateFileName), nil
+}
+
+// SaveRunState writes state to path, replacing it atomically so a crash
+// mid-write leaves the previous state intact.
+func SaveRunState(path string, state RunState) error {
+	state.Updated = time.Now()
+	if state.Started.IsZero() {
+		state.Started = state.Updated
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode run state: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write run state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace run state: %w", err)
+	}
+	return nil
+}
+
+// LoadRunState reads the run state at path. It returns nil when there is no
+// run to resume, including when the file is missing or malformed.
+func LoadRunState(path string) (*RunState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read run state: %w", err)
+	}
+
+	var state RunState
+	if err := json.Unmarshal(data, &state); err != nil || len(state.Components) == 0 {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// ClearRunState removes the run state at path; a missing file is not an error.
+func ClearRunState(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove run state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// versionsFileName is the name of the version history file in the app config directory.
+const versionsFileName = "versions.jsonl"
+
+// VersionEntry records a component moving from one version to another, so an
+// update can later be rolled back to the version it replaced.
+type VersionEntry struct {
+	Time      time.Time `json:"time"`
+	Component string    `json:"component"`
+	Previous  string    `json:"previous"`
+	Installed string    `json:"installed"`
+	// Rollback marks entries written by a rollback rather than an update.
+	Rollback bool `json:"rollback,omitempty"`
+}
+
+// DefaultVersionsPath returns the version history file location in the
+// user's config directory.
+func DefaultVersionsPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, appConfigDirName, versionsFileName), nil
+}
+
+// AppendVersion writes a version change to the version history file,
+// creating it if necessary.
+func AppendVersion(path string, entry VersionEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode version entry: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open version history: %w", err)
+	}
+
+	_, writeErr := f.Write(append(data, '\n'))
+	closeErr := f.Close()
+	if writeErr != nil {
+		return fmt.Errorf("failed to write version entry: %w", writeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to finalize version history: %w", closeErr)
+	}
+	return nil
+}
+
+// LoadVersions reads the version changes recorded for component, oldest
+// first. An empty component returns every entry. A missing file yields no
+// entries and malformed lines are skipped.
+func LoadVersions(path, component string) ([]VersionEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open version history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []VersionEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry VersionEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if component == "" || entry.Component == component {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read version history: %w", err)
+	}
+	return entries, nil
+}
+
+// RollbackTarget returns the version to roll back to from current: the
+// version replaced by the most recent update that did not start from
+// current. Rollbacks themselves are not rolled back, so repeated rollbacks
+// walk further back through the update history. ok is false when there is
+// nothing to roll back to.
+func RollbackTarget(entries []VersionEntry, current string) (version string, ok bool) {
+	for idx := len(entries) - 1; idx >= 0; idx-- {
+		entry := entries[idx]
+		if entry.Rollback || entry.Previous == "" || entry.Previous == current {
+			continue
+		}
+		return entry.Previous, true
+	}
+	return "", false
+}
@@ -12,20 +12,31 @@ const (
 )
 
 // gitHubTrustedHosts contains trusted hosts for GitHub API and download requests.
+// Release asset downloads on github.com redirect to signed URLs on GitHub's
+// storage hosts, so those are listed too; only the exact hosts GitHub uses
+// are trusted, not the storage providers' domains as a whole.
 var gitHubTrustedHosts = []string{
 	"github.com",
 	"api.github.com",
 	"objects.githubusercontent.com",
+	"release-assets.githubusercontent.com",
+	"github-releases.githubusercontent.com",
+	"github-production-release-asset-2e65be.s3.amazonaws.com",
 }
 
-// allTrustedHosts contains all trusted hosts including Node.js CDN.
-var allTrustedHosts = []string{
-	"github.com",
-	"api.github.com",
-	"objects.githubusercontent.com",
+// statusPageHosts serve the public status pages consulted when downloads from
+// GitHub or npm keep failing.
+var statusPageHosts = []string{
+	"www.githubstatus.com",
+	"status.npmjs.org",
+}
+
+// allTrustedHosts contains all trusted hosts including Node.js CDN. The status
+// pages are deliberately not included; nothing is downloaded from them.
+var allTrustedHosts = append(GitHubTrustedHosts(),
 	"nodejs.org",
 	"cdn.nodejs.org",
-}
+)
 
 // GitHubTrustedHosts returns a copy of the trusted hosts for GitHub API and download requests.
 func GitHubTrustedHosts() []string {
@@ -34,6 +45,13 @@ func GitHubTrustedHosts() []string {
 	return result
 }
 
+// StatusPageTrustedHosts returns a copy of the trusted status page hosts.
+func StatusPageTrustedHosts() []string {
+	result := make([]string, len(statusPageHosts))
+	copy(result, statusPageHosts)
+	return result
+}
+
 // AllTrustedHosts returns a copy of all trusted hosts including Node.js CDN.
 func AllTrustedHosts() []string {
 	result := make([]string, len(allTrustedHosts))
@@ -0,0 +1,164 @@
+package httputil
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRequestsPerSecond is the sustained request rate allowed per host.
+	DefaultRequestsPerSecond = 2.0
+	// DefaultBurst is the number of requests per host allowed back to back
+	// before throttling starts.
+	DefaultBurst = 5
+)
+
+// defaultTransport is shared by all clients so the limit applies per host
+// across the whole process rather than per client.
+var defaultTransport = NewRateLimitedTransport(NewTLSTransport(DefaultMinTLSVersion), DefaultRequestsPerSecond, DefaultBurst)
+
+// DefaultTransport returns the process-wide rate-limited transport that
+// outbound requests should use.
+func DefaultTransport() http.RoundTripper {
+	return defaultTransport
+}
+
+// SetDefaultRateLimit changes the per-host rate of the process-wide transport.
+func SetDefaultRateLimit(requestsPerSecond float64, burst int) error {
+	return defaultTransport.SetRate(requestsPerSecond, burst)
+}
+
+// RateLimitedTransport is an http.RoundTripper that throttles requests with a
+// token bucket per host, so bursts of API calls (for example several GitHub
+// release lookups) stay under the server's rate limits.
+type RateLimitedTransport struct {
+	base http.RoundTripper
+
+	mu      sync.Mutex
+	rate    float64
+	burst   int
+	buckets map[string]*tokenBucket
+}
+
+// tokenBucket tracks the tokens available for one host.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimitedTransport wraps base, allowing requestsPerSecond requests per
+// host with bursts of up to burst requests. A nil base uses http.DefaultTransport.
+func NewRateLimitedTransport(base http.RoundTripper, requestsPerSecond float64, burst int) *RateLimitedTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RateLimitedTransport{
+		base:    base,
+		rate:    requestsPerSecond,
+		burst:   burst,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// SetRate changes the per-host rate. Existing buckets keep their tokens,
+// capped to the new burst.
+func (t *RateLimitedTransport) SetRate(requestsPerSecond float64, burst int) error {
+	if requestsPerSecond <= 0 {
+		return fmt.Errorf("request rate must be positive, got %v", requestsPerSecond)
+	}
+	if burst < 1 {
+		return fmt.Errorf("burst must be at least 1, got %d", burst)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rate = requestsPerSecond
+	t.burst = burst
+	for _, b := range t.buckets {
+		if b.tokens > float64(burst) {
+			b.tokens = float64(burst)
+		}
+	}
+	return nil
+}
+
+// RoundTrip waits for a token for the request's host, then sends the request.
+func (t *RateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.wait(req); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// WithBase returns a RoundTripper that sends requests through base while
+// drawing tokens from t's buckets, so the per-host limit stays shared.
+func (t *RateLimitedTransport) WithBase(base http.RoundTripper) http.RoundTripper {
+	return &sharedLimitTransport{limiter: t, base: base}
+}
+
+// sharedLimitTransport rate-limits requests with another transport's buckets.
+type sharedLimitTransport struct {
+	limiter *RateLimitedTransport
+	base    http.RoundTripper
+}
+
+func (s *sharedLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := s.limiter.wait(req); err != nil {
+		return nil, err
+	}
+	return s.base.RoundTrip(req)
+}
+
+// wait blocks until a token for the request's host is available or the
+// request's context is done.
+func (t *RateLimitedTransport) wait(req *http.Request) error {
+	host := req.URL.Hostname()
+	if delay := t.reserve(host, time.Now()); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			t.release(host)
+			return req.Context().Err()
+		}
+	}
+	return nil
+}
+
+// reserve takes a token for host and returns how long the caller must wait
+// before using it.
+func (t *RateLimitedTransport) reserve(host string, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b, ok := t.buckets[host]
+	if !ok {
+		b = &tokenBucket{tokens: float64(t.burst), last: now}
+		t.buckets[host] = b
+	}
+
+	// Refill for the time elapsed since the last request
+	b.tokens += now.Sub(b.last).Seconds() * t.rate
+	if b.tokens > float64(t.burst) {
+		b.tokens = float64(t.burst)
+	}
+	b.last = now
+
+	// Tokens may go negative: each waiting request holds a reservation
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / t.rate * float64(time.Second))
+}
+
+// release returns a reserved token when the request is abandoned.
+func (t *RateLimitedTransport) release(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if b, ok := t.buckets[host]; ok {
+		b.tokens++
+	}
+}
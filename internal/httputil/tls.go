@@ -0,0 +1,138 @@
+package httputil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMinTLSVersion is the lowest TLS version outbound connections accept.
+const DefaultMinTLSVersion = tls.VersionTLS12
+
+// ErrTLSVersion is returned when a server cannot negotiate the minimum
+// required TLS version.
+var ErrTLSVersion = errors.New("server does not support the minimum required TLS version")
+
+// transportKey identifies a non-default transport configuration.
+type transportKey struct {
+	minVersion uint16
+	preferIPv4 bool
+}
+
+var (
+	tlsTransportsMu sync.Mutex
+	// tlsTransports caches one rate-limited transport per non-default
+	// configuration so connections are pooled across clients.
+	tlsTransports = map[transportKey]http.RoundTripper{}
+)
+
+// ValidateMinTLSVersion checks that v is a TLS version constant from crypto/tls
+// no older than DefaultMinTLSVersion. The minimum can be raised, never lowered.
+func ValidateMinTLSVersion(v uint16) error {
+	switch v {
+	case tls.VersionTLS12, tls.VersionTLS13:
+		return nil
+	case tls.VersionTLS10, tls.VersionTLS11:
+		return fmt.Errorf("%s is below the minimum of %s", tls.VersionName(v), tls.VersionName(DefaultMinTLSVersion))
+	}
+	return fmt.Errorf("unsupported TLS version 0x%04x", v)
+}
+
+// TransportWithMinTLS returns the process-wide rate-limited transport, or one
+// sharing its rate limit that requires at least minVersion. Zero selects
+// DefaultMinTLSVersion.
+func TransportWithMinTLS(minVersion uint16) http.RoundTripper {
+	return TransportFor(minVersion, false)
+}
+
+// TransportFor is TransportWithMinTLS that, when preferIPv4 is set, also
+// connects over IPv4 only, for networks where AAAA records resolve but IPv6
+// connectivity is broken.
+func TransportFor(minVersion uint16, preferIPv4 bool) http.RoundTripper {
+	if minVersion == 0 {
+		minVersion = DefaultMinTLSVersion
+	}
+	if minVersion == DefaultMinTLSVersion && !preferIPv4 {
+		return defaultTransport
+	}
+
+	key := transportKey{minVersion: minVersion, preferIPv4: preferIPv4}
+	tlsTransportsMu.Lock()
+	defer tlsTransportsMu.Unlock()
+	if t, ok := tlsTransports[key]; ok {
+		return t
+	}
+	t := defaultTransport.WithBase(newTransport(minVersion, preferIPv4, nil))
+	tlsTransports[key] = t
+	return t
+}
+
+// NewTLSTransport returns a transport with http.DefaultTransport's settings
+// that refuses TLS versions below minVersion. Handshake failures caused by the
+// server only offering older versions are reported as ErrTLSVersion. Requests
+// go through the proxy configured with SetProxy, or the environment's.
+func NewTLSTransport(minVersion uint16) http.RoundTripper {
+	return newTLSTransport(minVersion, nil)
+}
+
+// newTLSTransport is NewTLSTransport with custom root CAs, used by tests.
+func newTLSTransport(minVersion uint16, rootCAs *x509.CertPool) http.RoundTripper {
+	return newTransport(minVersion, false, rootCAs)
+}
+
+// newTransport is newTLSTransport that dials over IPv4 only when preferIPv4
+// is set.
+func newTransport(minVersion uint16, preferIPv4 bool, rootCAs *x509.CertPool) http.RoundTripper {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = &tls.Config{
+		MinVersion: minVersion,
+		RootCAs:    rootCAs,
+	}
+	base.Proxy = proxyForRequest
+	if preferIPv4 {
+		base.DialContext = dialIPv4(&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second})
+	}
+	return &proxyAuthTransport{base: &minTLSTransport{base: base, minVersion: minVersion}}
+}
+
+// minTLSTransport annotates protocol version handshake failures.
+type minTLSTransport struct {
+	base       http.RoundTripper
+	minVersion uint16
+}
+
+func (t *minTLSTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil && isTLSVersionError(err) {
+		return nil, fmt.Errorf("%w (%s required by %s): %v", ErrTLSVersion, tls.VersionName(t.minVersion), req.URL.Hostname(), err)
+	}
+	return resp, err
+}
+
+// dialIPv4 returns a DialContext that connects over IPv4 only, so a host
+// with AAAA records is never tried over an IPv6 route that silently drops
+// traffic. With a proxy, this applies to the connection to the proxy.
+func dialIPv4(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if network == "tcp" || network == "tcp6" {
+			network = "tcp4"
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
+// isTLSVersionError reports whether err is a handshake failure over the
+// protocol version. crypto/tls exposes no typed error for this, so the
+// alert and client error texts are matched.
+func isTLSVersionError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "protocol version not supported") ||
+		strings.Contains(msg, "server selected unsupported protocol version")
+}
@@ -0,0 +1,88 @@
+package installer
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extractZip extracts a zip archive into destDir. When stripTopLevel is true,
+// the leading directory component shared by release archives
+// (e.g. "node-v22.13.1-win-x64/") is removed from each entry.
+// Entries that would escape destDir are rejected.
+func extractZip(zipPath, destDir string, stripTopLevel bool) error {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer r.Close()
+
+	cleanDest := filepath.Clean(destDir)
+	if err := os.MkdirAll(cleanDest, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", cleanDest, err)
+	}
+
+	for _, f := range r.File {
+		name := strings.ReplaceAll(f.Name, `\`, "/")
+		if stripTopLevel {
+			idx := strings.Index(name, "/")
+			if idx == -1 {
+				continue // top-level directory entry itself
+			}
+			name = name[idx+1:]
+		}
+		if name == "" {
+			continue
+		}
+
+		target := filepath.Join(cleanDest, filepath.FromSlash(name))
+		if target != cleanDest && !strings.HasPrefix(target, cleanDest+string(os.PathSeparator)) {
+			return fmt.Errorf("archive entry %q escapes destination directory", f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", target, err)
+			}
+			continue
+		}
+
+		if err := extractZipFile(f, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractZipFile writes a single archive entry to target.
+func extractZipFile(f *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open archive entry %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", target, err)
+	}
+
+	// Guard against decompression bombs with the same cap used for downloads
+	_, copyErr := io.Copy(out, io.LimitReader(rc, maxDownloadSize))
+	closeErr := out.Close()
+	if copyErr != nil {
+		return fmt.Errorf("failed to extract %s: %w", f.Name, copyErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to finalize %s: %w", target, closeErr)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package installer
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create zip: %v", err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to finalize zip: %v", err)
+	}
+}
+
+func TestExtractZip_StripTopLevel(t *testing.T) {
+	tmpDir := t.TempDir()
+	zipPath := filepath.Join(tmpDir, "node.zip")
+	writeTestZip(t, zipPath, map[string]string{
+		"node-v22.13.1-win-x64/node.exe":              "binary",
+		"node-v22.13.1-win-x64/node_modules/npm/a.js": "js",
+	})
+
+	destDir := filepath.Join(tmpDir, "nodejs")
+	if err := extractZip(zipPath, destDir, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, rel := range []string{"node.exe", filepath.Join("node_modules", "npm", "a.js")} {
+		if _, err := os.Stat(filepath.Join(destDir, rel)); err != nil {
+			t.Errorf("expected %s to be extracted: %v", rel, err)
+		}
+	}
+}
+
+func TestExtractZip_RejectsPathTraversal(t *testing.T) {
+	tmpDir := t.TempDir()
+	zipPath := filepath.Join(tmpDir, "evil.zip")
+	writeTestZip(t, zipPath, map[string]string{
+		"../evil.txt": "pwned",
+	})
+
+	if err := extractZip(zipPath, filepath.Join(tmpDir, "out"), false); err == nil {
+		t.Error("expected error for archive entry escaping destination")
+	}
+}
+
+func TestZipEntrySHA256(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "node.zip")
+	writeTestZip(t, zipPath, map[string]string{
+		"node-v22.13.1-win-x64/node.exe": "hello world",
+	})
+
+	hash, err := zipEntrySHA256(zipPath, "node.exe")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// SHA-256 of "hello world"
+	if hash != "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9" {
+		t.Errorf("unexpected hash: %s", hash)
+	}
+
+	if _, err := zipEntrySHA256(zipPath, "npm.cmd"); err == nil {
+		t.Error("expected error for missing entry")
+	}
+}
@@ -0,0 +1,124 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// defaultBackendOrder is the order in which Windows installation backends are
+// tried: package managers first, then the direct download.
+var defaultBackendOrder = []string{StrategyWinget, StrategyScoop, StrategyDownload}
+
+// errNoBackendAvailable is returned when none of the configured backends can
+// be used on this machine.
+var errNoBackendAvailable = errors.New("no installation backend is available")
+
+// installBackend is one way of installing a component on Windows.
+type installBackend struct {
+	// strategy is reported in progress events while the backend runs.
+	strategy string
+	// install installs and verifies the component, emitting the completion event.
+	install func() error
+}
+
+// SetBackendOrder configures the order in which Windows installation backends
+// ("winget", "scoop", "download") are tried. Backends left out are not used;
+// an empty order restores the default of winget, then Scoop, then download.
+func (i *Installer) SetBackendOrder(order []string) error {
+	if err := ValidateBackendOrder(order); err != nil {
+		return err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.backendOrder = append([]string(nil), order...)
+	return nil
+}
+
+// ValidateBackendOrder rejects unknown and repeated backend names.
+func ValidateBackendOrder(order []string) error {
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		switch name {
+		case StrategyWinget, StrategyScoop, StrategyDownload:
+		default:
+			return fmt.Errorf("unknown installation backend %q", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("installation backend %q listed more than once", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// preferredBackends returns the configured backend order, or the default.
+func (i *Installer) preferredBackends() []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.backendOrder) == 0 {
+		return defaultBackendOrder
+	}
+	return i.backendOrder
+}
+
+// backendAvailable reports whether a backend can be used on this machine.
+func backendAvailable(name string) bool {
+	switch name {
+	case StrategyWinget:
+		return isWingetAvailable()
+	case StrategyScoop:
+		return isScoopAvailable()
+	default:
+		return true
+	}
+}
+
+// firstAvailableBackend returns the preferred backend that would be tried
+// first, or "" when none is available.
+func (i *Installer) firstAvailableBackend() string {
+	for _, name := range i.preferredBackends() {
+		if backendAvailable(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// runBackends tries the available backends in the preferred order, emitting a
+// fallback event each time one fails. byName maps backend names to their
+// implementation for the step.
+func (i *Installer) runBackends(stepName string, byName map[string]installBackend) error {
+	var lastErr error
+	previous := ""
+	for _, name := range i.preferredBackends() {
+		backend, ok := byName[name]
+		if !ok {
+			continue
+		}
+		if name != StrategyDownload {
+			available := backendAvailable(name)
+			i.emitDetail(stepName, "%s available: %s", name, yesNo(available))
+			if !available {
+				continue
+			}
+		}
+
+		if previous == "" {
+			i.setStrategy(stepName, backend.strategy)
+		} else {
+			i.emitFallback(stepName, previous, backend.strategy, lastErr, 20)
+		}
+		if lastErr = backend.install(); lastErr == nil {
+			return nil
+		}
+		if i.stopRequested(lastErr) {
+			return lastErr
+		}
+		previous = backend.strategy
+	}
+
+	if lastErr == nil {
+		return errNoBackendAvailable
+	}
+	return lastErr
+}
@@ -0,0 +1,74 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"claude-code-installer/internal/detector"
+	"claude-code-installer/internal/pathutil"
+)
+
+const (
+	// brewNodeFormula is the Homebrew formula for Node.js.
+	brewNodeFormula = "node"
+	// brewGitFormula is the Homebrew formula for Git.
+	brewGitFormula = "git"
+)
+
+// findBrew locates the Homebrew executable.
+func findBrew() (string, error) {
+	if brewPath := detector.FindBrew(); brewPath != "" {
+		return brewPath, nil
+	}
+	return "", fmt.Errorf("brew not found")
+}
+
+// isBrewAvailable checks if Homebrew is available on the system.
+func isBrewAvailable() bool {
+	_, err := findBrew()
+	return err == nil
+}
+
+// installViaBrew installs a Homebrew formula and verifies that command is runnable.
+func (i *Installer) installViaBrew(stepName, component, formula, command string) error {
+	brewPath, err := findBrew()
+	if err != nil {
+		i.emitProgress(stepName, "error", "Homebrew is not installed. Please install it from https://brew.sh and try again.", 0)
+		return fmt.Errorf("Homebrew is required to install %s: %w", component, err)
+	}
+
+	i.setStrategy(stepName, StrategyBrew)
+	i.emitProgress(stepName, "installing", fmt.Sprintf("Installing %s via Homebrew...", component), 10)
+
+	if _, err := i.runCommand(brewPath, "install", formula); err != nil {
+		i.emitProgress(stepName, "error", fmt.Sprintf("Failed to install %s: %v", component, err), 0)
+		return fmt.Errorf("failed to install %s via Homebrew: %w", component, err)
+	}
+
+	// Make Homebrew's bin directory visible to this process so the new
+	// command (and npm, for later steps) can be found
+	prependToProcessPath(filepath.Dir(brewPath))
+
+	if err := i.verifyExecutable(command, stepName, "--version", nil); err != nil {
+		i.emitProgress(stepName, "error",
+			fmt.Sprintf("%s was installed but verification failed. Please restart the application.", component), 0)
+		return fmt.Errorf("%s installed but verification failed: %w", component, err)
+	}
+
+	i.emitProgress(stepName, "completed", fmt.Sprintf("%s installed successfully via Homebrew", component), 100)
+	return nil
+}
+
+// prependToProcessPath adds dir to the front of the current process PATH if absent.
+func prependToProcessPath(dir string) {
+	current := os.Getenv("PATH")
+	if pathutil.PathContains(current, dir) {
+		return
+	}
+	if current == "" {
+		os.Setenv("PATH", dir)
+		return
+	}
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+current)
+}
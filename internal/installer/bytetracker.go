@@ -0,0 +1,92 @@
+package installer
+
+import "sync"
+
+// ByteTracker accumulates bytes downloaded by several installers so a
+// multi-component run can report a single byte-based percentage instead of
+// averaging per-step percentages. It is safe for concurrent use.
+type ByteTracker struct {
+	mu         sync.Mutex
+	estimates  map[string]int64
+	downloaded map[string]int64
+	onUpdate   func(downloaded, total int64)
+}
+
+// NewByteTracker creates a tracker for components with the given estimated
+// download sizes in bytes (see InstallPlan). onUpdate, if set, is called with
+// the overall totals whenever they change.
+func NewByteTracker(estimates map[string]int64, onUpdate func(downloaded, total int64)) *ByteTracker {
+	t := &ByteTracker{
+		estimates:  make(map[string]int64, len(estimates)),
+		downloaded: make(map[string]int64),
+		onUpdate:   onUpdate,
+	}
+	for component, size := range estimates {
+		t.estimates[component] = size
+	}
+	return t
+}
+
+// Add records n more bytes downloaded for a component. A negative n undoes
+// bytes discarded when a download restarts.
+func (t *ByteTracker) Add(component string, n int64) {
+	if n == 0 {
+		return
+	}
+	t.mu.Lock()
+	t.downloaded[component] = max(t.downloaded[component]+n, 0)
+	downloaded, total := t.totalsLocked()
+	t.mu.Unlock()
+
+	if t.onUpdate != nil {
+		t.onUpdate(downloaded, total)
+	}
+}
+
+// Finish marks a component's download as complete, so a component installed
+// without downloading (for example via a package manager) or skipped still
+// counts toward 100%.
+func (t *ByteTracker) Finish(component string) {
+	t.mu.Lock()
+	if t.downloaded[component] >= t.estimates[component] {
+		t.mu.Unlock()
+		return
+	}
+	t.downloaded[component] = t.estimates[component]
+	downloaded, total := t.totalsLocked()
+	t.mu.Unlock()
+
+	if t.onUpdate != nil {
+		t.onUpdate(downloaded, total)
+	}
+}
+
+// Totals returns the overall bytes downloaded and the expected total.
+func (t *ByteTracker) Totals() (downloaded, total int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.totalsLocked()
+}
+
+// totalsLocked sums the per-component counts. A component that downloads more
+// than estimated raises the total rather than pushing past 100%.
+func (t *ByteTracker) totalsLocked() (downloaded, total int64) {
+	for component, size := range t.estimates {
+		total += max(size, t.downloaded[component])
+	}
+	for component, n := range t.downloaded {
+		downloaded += n
+		if _, ok := t.estimates[component]; !ok {
+			total += n
+		}
+	}
+	return downloaded, total
+}
+
+// BytePercentage converts totals from a ByteTracker into a percentage.
+func BytePercentage(downloaded, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(downloaded) / float64(total) * 100
+}
@@ -1,36 +1,101 @@
 package installer
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+
+	"claude-code-installer/internal/detector"
+	"claude-code-installer/internal/updater"
+	"claude-code-installer/internal/version"
 )
 
 const (
 	// claudeCodePackage is the npm package name for Claude Code.
 	claudeCodePackage = "@anthropic-ai/claude-code"
+
+	// npmProgressStart and npmProgressEnd bound the percentages reported while npm runs.
+	npmProgressStart = 20
+	npmProgressEnd   = 80
+	// npmMaxFetchProgress caps how many fetched packages advance the fetch milestone.
+	npmMaxFetchProgress = 30
 )
 
+// ErrClaudeRunning is returned when Claude Code must be closed before its
+// installation can be modified.
+var ErrClaudeRunning = errors.New("Claude Code is currently running")
+
+// ErrClaudeShadowed is returned when the claude command resolves to a program
+// other than Claude Code, such as an unrelated tool earlier on PATH.
+var ErrClaudeShadowed = errors.New("claude on PATH is not Claude Code")
+
 // ClaudeCodeUpdateInfo contains information about available Claude Code updates.
 type ClaudeCodeUpdateInfo struct {
 	Available      bool   `json:"available"`
 	CurrentVersion string `json:"currentVersion"`
 	LatestVersion  string `json:"latestVersion"`
+	// UpdateType is one of the updater.Update* constants.
+	UpdateType string `json:"updateType"`
+	// UpdateMethod is how UpdateClaudeCode will apply the update (one of the
+	// UpdateMethod* constants).
+	UpdateMethod string `json:"updateMethod"`
 }
 
-// InstallClaudeCode installs the Claude Code CLI via npm.
+// Update methods reported in ClaudeCodeUpdateInfo.UpdateMethod.
+const (
+	// UpdateMethodNPM updates in place with the selected package manager,
+	// e.g. npm install -g <package>@latest.
+	UpdateMethodNPM = "npm"
+	// UpdateMethodReinstall uninstalls the package before installing a new
+	// major version, so files from the old package layout do not linger.
+	UpdateMethodReinstall = "reinstall"
+	// UpdateMethodNative runs Claude Code's own updater for installs not
+	// managed by npm (the native installer).
+	UpdateMethodNative = "native"
+)
+
+// InstallClaudeCode installs the Claude Code CLI via npm, or the package
+// manager selected with SetPackageManager.
 func (i *Installer) InstallClaudeCode() error {
+	return i.withTelemetry("claudecode", func() error {
+		return i.withPostInstallHook("claudecode", i.installClaudeCode)
+	})
+}
+
+// installClaudeCode performs the Claude Code installation; see InstallClaudeCode.
+func (i *Installer) installClaudeCode() error {
 	stepName := "claudecode"
 
+	if err := i.ensureNoPreviousInstall(stepName); err != nil {
+		return err
+	}
+
 	i.emitProgress(stepName, "installing", "Checking for existing Claude Code installation...", 0)
 
-	// Check if already installed
-	if _, err := exec.LookPath("claude"); err == nil {
+	// Check if already installed and actually runnable
+	found, working := i.isCommandWorking("claude")
+	if working {
 		i.emitProgress(stepName, "completed", "Claude Code is already installed", 100)
 		return nil
+	} else if found {
+		i.emitProgress(stepName, "installing", "Existing Claude Code installation is broken, reinstalling...", 5)
+	}
+
+	if pm := i.claudePackageManager(); pm != StrategyNPM {
+		pmPath, err := i.findPackageManager(pm)
+		if err != nil {
+			i.emitProgress(stepName, "error", err.Error(), 0)
+			return err
+		}
+		if err := i.installClaudeWithPackageManager(stepName, pm, pmPath); err != nil {
+			return err
+		}
+		return i.finishClaudeInstall(stepName)
 	}
 
 	// Verify npm is available (required for installation)
@@ -40,15 +105,49 @@ func (i *Installer) InstallClaudeCode() error {
 		return fmt.Errorf("npm is required to install Claude Code: %w", err)
 	}
 
+	i.warnOnEngineMismatch(stepName, npmPath)
+
+	prefixArgs, userBinDir := npmGlobalArgs()
+	if found {
+		// A broken claude may be a shim whose package is gone; npm will not
+		// overwrite it, so clear it first
+		if removed, err := i.removeStaleClaudeShims(npmPath, prefixArgs); err == nil && len(removed) > 0 {
+			i.emitProgress(stepName, "installing",
+				fmt.Sprintf("Removed %d stale Claude Code shim(s) left by a previous install", len(removed)), 10)
+		}
+	}
+
+	i.setStrategy(stepName, StrategyNPM)
 	i.emitProgress(stepName, "installing", "Installing Claude Code via npm...", 20)
 
-	// Run npm install -g @anthropic-ai/claude-code
-	_, err = i.runCommand(npmPath, "install", "-g", claudeCodePackage)
+	// Run npm install -g @anthropic-ai/claude-code, streaming output so npm's
+	// http/reify log lines can be turned into intermediate progress
+	args := append([]string{"install", "-g", claudeCodePackage, "--loglevel", "http"}, prefixArgs...)
+
+	tracker := &npmProgressTracker{percentage: npmProgressStart}
+	output, err := i.runCommandStreaming(func(line string) {
+		if pct, msg, ok := tracker.update(line); ok {
+			i.emitProgress(stepName, "installing", msg, pct)
+		}
+	}, npmPath, args...)
 	if err != nil {
-		i.emitProgress(stepName, "error", fmt.Sprintf("Failed to install Claude Code: %v", err), 0)
+		cause, err := i.packageManagerFailure(stepName, npmPath, args, output, err)
+		// npm retries registry fetches itself, so a failure to reach the
+		// registry here may be an outage
+		if isNpmNetworkError(output) {
+			err = i.annotateOutage(stepName, serviceNpm, err)
+		}
+		i.emitProgress(stepName, "error", "Failed to install Claude Code: "+cause, 0)
 		return fmt.Errorf("failed to install Claude Code: %w", err)
 	}
 
+	i.ensureNpmBinOnPath(stepName, npmPath, prefixArgs, userBinDir)
+	return i.finishClaudeInstall(stepName)
+}
+
+// finishClaudeInstall waits for a freshly installed claude to appear on PATH
+// and verifies it.
+func (i *Installer) finishClaudeInstall(stepName string) error {
 	// Poll for claude to become available (up to 20 seconds)
 	if err := i.pollForCommand("claude", 20); err != nil {
 		return err
@@ -58,8 +157,11 @@ func (i *Installer) InstallClaudeCode() error {
 
 	// Verify installation
 	if err := i.verifyClaudeCode(); err != nil {
-		i.emitProgress(stepName, "error",
-			"Claude Code was installed but verification failed. Try restarting your terminal.", 0)
+		message := "Claude Code was installed but verification failed. Try restarting your terminal."
+		if errors.Is(err, ErrClaudeShadowed) {
+			message = fmt.Sprintf("Claude Code was installed, but %v", err)
+		}
+		i.emitProgress(stepName, "error", message, 0)
 		return fmt.Errorf("Claude Code installed but verification failed: %w", err)
 	}
 
@@ -83,32 +185,90 @@ func (i *Installer) CheckUpdate() (*ClaudeCodeUpdateInfo, error) {
 	if err != nil {
 		return nil, fmt.Errorf("npm is not available: %w", err)
 	}
+	pm := i.claudePackageManager()
+	pmPath, err := i.findPackageManager(pm)
+	if err != nil {
+		return nil, err
+	}
 
-	latestVersion, err := i.runCommand(npmPath, "view", claudeCodePackage, "version")
+	latestVersion, err := i.latestClaudeVersion(npmPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check latest version: %w", err)
 	}
 
-	info.LatestVersion = strings.TrimSpace(latestVersion)
-	info.Available = info.CurrentVersion != info.LatestVersion
+	info.LatestVersion = latestVersion
+	info.UpdateType = updater.ClassifyUpdate(claudeVersionNumber(info.CurrentVersion), info.LatestVersion)
+	info.Available = info.UpdateType != updater.UpdateNone
+
+	info.UpdateMethod = recommendUpdateMethod(info.UpdateType, i.isClaudeManagedBy(pm, pmPath))
 
 	return info, nil
 }
 
-// UpdateClaudeCode updates Claude Code to the latest version via npm.
+// claudeVersionNumber extracts the version number from `claude --version`
+// output such as "1.0.17 (Claude Code)".
+func claudeVersionNumber(output string) string {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// recommendUpdateMethod chooses how to apply an update of updateType.
+// npmManaged reports whether the installed claude comes from the selected
+// package manager's global packages; otherwise it was installed natively and
+// updates itself.
+func recommendUpdateMethod(updateType string, npmManaged bool) string {
+	switch {
+	case !npmManaged:
+		return UpdateMethodNative
+	case updateType == updater.UpdateMajor:
+		return UpdateMethodReinstall
+	default:
+		return UpdateMethodNPM
+	}
+}
+
+// UpdateClaudeCode updates Claude Code to the latest version using the method
+// CheckUpdate recommends: an in-place npm update, a clean reinstall for major
+// versions, or Claude Code's own updater for native installs.
 func (i *Installer) UpdateClaudeCode() error {
+	return i.withTelemetry("claudeCodeUpdate", i.updateClaudeCode)
+}
+
+// updateClaudeCode performs the update; see UpdateClaudeCode.
+func (i *Installer) updateClaudeCode() error {
 	stepName := "claudeCodeUpdate"
 
+	if err := i.ensureNoPreviousInstall(stepName); err != nil {
+		return err
+	}
+	if err := i.ensureClaudeNotRunning(stepName); err != nil {
+		return err
+	}
+
 	i.emitProgress(stepName, "installing", "Updating Claude Code...", 10)
 
-	npmPath, err := i.findNpm()
-	if err != nil {
-		i.emitProgress(stepName, "error", "npm is not available", 0)
-		return fmt.Errorf("npm is required to update Claude Code: %w", err)
+	// Remembered so the update can be rolled back with RollbackClaudeCode
+	previous := i.installedClaudeVersion()
+
+	// Default to an in-place npm update when the check itself fails
+	method := UpdateMethodNPM
+	if info, err := i.CheckUpdate(); err == nil {
+		method = info.UpdateMethod
+		if info.UpdateType == updater.UpdateMajor {
+			i.emitProgress(stepName, "installing",
+				fmt.Sprintf("Warning: Claude Code %s is a major update and may change behavior", info.LatestVersion), 15)
+		}
 	}
 
-	// Use npm install -g to update to latest
-	_, err = i.runCommand(npmPath, "install", "-g", claudeCodePackage+"@latest")
+	var err error
+	if method == UpdateMethodNative {
+		err = i.updateClaudeNative(stepName)
+	} else {
+		err = i.updateClaudeViaPackageManager(stepName, method == UpdateMethodReinstall, previous)
+	}
 	if err != nil {
 		i.emitProgress(stepName, "error", fmt.Sprintf("Failed to update Claude Code: %v", err), 0)
 		return fmt.Errorf("failed to update Claude Code: %w", err)
@@ -121,11 +281,288 @@ func (i *Installer) UpdateClaudeCode() error {
 		i.emitProgress(stepName, "error", "Update completed but verification failed", 0)
 		return fmt.Errorf("update verification failed: %w", err)
 	}
+	i.recordClaudeVersion(stepName, previous, i.installedClaudeVersion(), false)
 
 	i.emitProgress(stepName, "completed", "Claude Code updated successfully", 100)
 	return nil
 }
 
+// updateClaudeViaPackageManager installs the latest Claude Code package
+// globally with the selected package manager, first removing the installed
+// one when reinstall is set. The package must be removed before the new one
+// can be installed under the same name, so if that install fails the
+// previous version is reinstalled rather than leaving no Claude Code.
+func (i *Installer) updateClaudeViaPackageManager(stepName string, reinstall bool, previous string) error {
+	pm := i.claudePackageManager()
+	pmPath, err := i.findPackageManager(pm)
+	if err != nil {
+		return fmt.Errorf("%s is required to update Claude Code: %w", pm, err)
+	}
+	var prefixArgs []string
+	if pm == StrategyNPM {
+		prefixArgs, _ = npmGlobalArgs()
+	}
+
+	i.setStrategy(stepName, pm)
+	if reinstall {
+		i.emitProgress(stepName, "installing", "Removing the previous major version...", 20)
+		args := append(globalRemoveArgs(pm, claudeCodePackage), prefixArgs...)
+		if output, err := i.runCommand(pmPath, args...); err != nil {
+			_, err = i.packageManagerFailure(stepName, pmPath, args, output, err)
+			return fmt.Errorf("failed to remove the previous version: %w", err)
+		}
+	}
+
+	// Install <package>@latest globally to update to latest
+	i.emitProgress(stepName, "installing", "Installing the latest Claude Code...", 40)
+	args := append(globalAddArgs(pm, claudeCodePackage+"@latest"), prefixArgs...)
+	if output, err := i.runCommand(pmPath, args...); err != nil {
+		_, err = i.packageManagerFailure(stepName, pmPath, args, output, err)
+		if isNpmNetworkError(output) {
+			err = i.annotateOutage(stepName, serviceNpm, err)
+		}
+		err = packageManagerHint(pm, err)
+		if reinstall {
+			i.restoreClaudeVersion(stepName, pm, pmPath, prefixArgs, previous)
+		}
+		return err
+	}
+	return nil
+}
+
+// restoreClaudeVersion reinstalls Claude Code previous after a reinstall
+// removed it and installing the new version failed. Failures are reported
+// as warnings; the caller returns the original install error.
+func (i *Installer) restoreClaudeVersion(stepName, pm, pmPath string, prefixArgs []string, previous string) {
+	target, err := version.Validate(previous)
+	if err != nil {
+		i.emitProgress(stepName, "installing",
+			"Warning: the update failed after removing Claude Code, and the previous version is unknown; install Claude Code again", 45)
+		return
+	}
+	if i.ctx.Err() != nil {
+		return
+	}
+
+	i.emitProgress(stepName, "installing", fmt.Sprintf("Update failed; restoring Claude Code %s...", target), 45)
+	args := append(globalAddArgs(pm, claudeCodePackage+"@"+target), prefixArgs...)
+	if output, err := i.runCommand(pmPath, args...); err != nil {
+		_, err = i.packageManagerFailure(stepName, pmPath, args, output, err)
+		i.emitProgress(stepName, "installing",
+			fmt.Sprintf("Warning: could not restore Claude Code %s: %v", target, err), 45)
+		return
+	}
+	i.emitDetail(stepName, "restored Claude Code %s after the failed update", target)
+}
+
+// updateClaudeNative runs `claude update` for installs not managed by npm.
+func (i *Installer) updateClaudeNative(stepName string) error {
+	claudePath, err := i.findClaude()
+	if err != nil {
+		return err
+	}
+	i.emitProgress(stepName, "installing", "Running Claude Code's updater...", 40)
+	_, err = i.runCommand(claudePath, "update")
+	return err
+}
+
+// RepairNpmGlobal repairs a Claude Code install left half-present by broken
+// npm global state: the package is in the global node_modules but claude no
+// longer runs, or the package is gone but its claude shims remain. Stale shims
+// are removed, then the npm cache is verified and the package force-reinstalled.
+func (i *Installer) RepairNpmGlobal() error {
+	return i.withTelemetry("claudeCodeRepair", i.repairNpmGlobal)
+}
+
+// repairNpmGlobal performs the repair; see RepairNpmGlobal.
+func (i *Installer) repairNpmGlobal() error {
+	stepName := "claudeCodeRepair"
+
+	if err := i.ensureNoPreviousInstall(stepName); err != nil {
+		return err
+	}
+
+	i.emitProgress(stepName, "installing", "Checking Claude Code installation...", 0)
+
+	if _, working := i.isCommandWorking("claude"); working {
+		i.emitProgress(stepName, "completed", "Claude Code is working; no repair needed", 100)
+		return nil
+	}
+
+	npmPath, err := i.findNpm()
+	if err != nil {
+		i.emitProgress(stepName, "error", "npm is not available", 0)
+		return fmt.Errorf("npm is required to repair Claude Code: %w", err)
+	}
+
+	if err := i.ensureClaudeNotRunning(stepName); err != nil {
+		return err
+	}
+
+	prefixArgs, userBinDir := npmGlobalArgs()
+	if !i.isClaudePackageInstalled(npmPath, prefixArgs) {
+		// Shims left behind by a failed install make claude look installed;
+		// remove them and reinstall, otherwise there is nothing to repair
+		removed, err := i.removeStaleClaudeShims(npmPath, prefixArgs)
+		if err != nil {
+			i.emitProgress(stepName, "error", fmt.Sprintf("Failed to remove stale Claude Code shims: %v", err), 0)
+			return fmt.Errorf("failed to remove stale Claude Code shims: %w", err)
+		}
+		if len(removed) == 0 {
+			i.emitProgress(stepName, "error", "Claude Code is not installed via npm; install it instead", 0)
+			return fmt.Errorf("%s is not installed globally; nothing to repair", claudeCodePackage)
+		}
+		i.emitProgress(stepName, "installing",
+			fmt.Sprintf("Removed %d stale Claude Code shim(s) left by a previous install", len(removed)), 5)
+	}
+
+	i.setStrategy(stepName, StrategyNPM)
+	i.emitProgress(stepName, "installing", "Verifying npm cache...", 10)
+	if _, err := i.runCommand(npmPath, "cache", "verify"); err != nil {
+		i.emitProgress(stepName, "error", fmt.Sprintf("Failed to verify npm cache: %v", err), 0)
+		return fmt.Errorf("failed to verify npm cache: %w", err)
+	}
+
+	i.emitProgress(stepName, "installing", "Reinstalling Claude Code...", 40)
+	args := append([]string{"install", "-g", claudeCodePackage, "--force"}, prefixArgs...)
+	if output, err := i.runCommand(npmPath, args...); err != nil {
+		cause, err := i.packageManagerFailure(stepName, npmPath, args, output, err)
+		i.emitProgress(stepName, "error", "Failed to reinstall Claude Code: "+cause, 0)
+		return fmt.Errorf("failed to reinstall Claude Code: %w", err)
+	}
+
+	i.ensureNpmBinOnPath(stepName, npmPath, prefixArgs, userBinDir)
+
+	i.emitProgress(stepName, "installing", "Verifying repair...", 80)
+	if err := i.verifyClaudeCode(); err != nil {
+		i.emitProgress(stepName, "error", "Reinstall completed but Claude Code still does not run", 0)
+		return fmt.Errorf("repair verification failed: %w", err)
+	}
+
+	i.emitProgress(stepName, "completed", "Claude Code repaired successfully", 100)
+	return nil
+}
+
+// warnOnEngineMismatch warns before installing when the installed Node.js or
+// npm does not satisfy Claude Code's declared engine requirements, which would
+// otherwise surface as a raw EBADENGINE error from npm. The check is advisory
+// and is skipped if any version cannot be determined.
+func (i *Installer) warnOnEngineMismatch(stepName, npmPath string) {
+	output, err := i.runCommand(npmPath, "view", claudeCodePackage, "engines", "--json")
+	if err != nil {
+		return
+	}
+	engines, err := parseEngines(output)
+	if err != nil {
+		return
+	}
+
+	installed := map[string]func() (string, error){
+		"node": func() (string, error) { return i.runCommand("node", "--version") },
+		"npm":  func() (string, error) { return i.runCommand(npmPath, "--version") },
+	}
+	for _, tool := range []string{"node", "npm"} {
+		required, ok := engines[tool]
+		if !ok {
+			continue
+		}
+		version, err := installed[tool]()
+		if err != nil {
+			continue
+		}
+		if satisfied, err := updater.SatisfiesRange(version, required); err == nil && !satisfied {
+			i.emitProgress(stepName, "installing",
+				fmt.Sprintf("Warning: Claude Code requires %s %s but %s is installed; please upgrade Node.js if installation fails",
+					tool, required, strings.TrimSpace(version)), 15)
+		}
+	}
+}
+
+// parseEngines parses the output of `npm view <pkg> engines --json`.
+func parseEngines(output string) (map[string]string, error) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil, fmt.Errorf("no engines declared")
+	}
+	var engines map[string]string
+	if err := json.Unmarshal([]byte(output), &engines); err != nil {
+		return nil, fmt.Errorf("failed to parse engines: %w", err)
+	}
+	return engines, nil
+}
+
+// ensureClaudeNotRunning refuses to continue while a Claude Code process is
+// running, since replacing its files would fail or corrupt the install. If
+// processes cannot be listed, the operation proceeds.
+func (i *Installer) ensureClaudeNotRunning(stepName string) error {
+	running, err := detector.IsClaudeRunning()
+	if err != nil || !running {
+		return nil
+	}
+	i.emitProgress(stepName, "error", "Claude Code is currently running. Please close all Claude Code sessions and try again.", 0)
+	return ErrClaudeRunning
+}
+
+// isClaudePackageInstalled reports whether the Claude Code package is present
+// in npm's global node_modules, whether or not its bin shim works.
+func (i *Installer) isClaudePackageInstalled(npmPath string, prefixArgs []string) bool {
+	packages, err := detector.ListGlobalNpmPackagesFor(npmPath, prefixArgs)
+	if err != nil {
+		return false
+	}
+	_, ok := packages[claudeCodePackage]
+	return ok
+}
+
+// npmGlobalArgs returns extra arguments for global npm commands and the
+// per-user bin directory they install into, if any.
+func npmGlobalArgs() ([]string, string) {
+	if runtime.GOOS == "linux" {
+		return linuxNpmGlobalArgs()
+	}
+	return nil, ""
+}
+
+// npmProgressTracker converts npm install output into coarse, monotonically
+// increasing progress milestones (fetching, extracting, linking).
+type npmProgressTracker struct {
+	fetched    int
+	percentage float64
+}
+
+// update inspects a line of npm output and returns the new percentage and
+// message when the line advances progress.
+func (t *npmProgressTracker) update(line string) (float64, string, bool) {
+	lower := strings.ToLower(line)
+
+	var pct float64
+	var msg string
+	switch {
+	case strings.Contains(lower, "added ") && strings.Contains(lower, " package"),
+		strings.Contains(lower, "changed ") && strings.Contains(lower, " package"),
+		strings.Contains(lower, "up to date"):
+		pct, msg = npmProgressEnd-2, "Linking Claude Code..."
+	case strings.Contains(lower, "reify"), strings.Contains(lower, "extract"):
+		pct, msg = 60, "Extracting packages..."
+	case strings.Contains(lower, "http fetch"):
+		t.fetched++
+		fetched := t.fetched
+		if fetched > npmMaxFetchProgress {
+			fetched = npmMaxFetchProgress
+		}
+		pct = npmProgressStart + 5 + float64(fetched)
+		msg = fmt.Sprintf("Fetching packages... (%d)", t.fetched)
+	default:
+		return 0, "", false
+	}
+
+	if pct <= t.percentage {
+		return 0, "", false
+	}
+	t.percentage = pct
+	return pct, msg, true
+}
+
 // getInstalledClaudeVersion returns the currently installed Claude Code version.
 func (i *Installer) getInstalledClaudeVersion() (string, error) {
 	claudePath, err := i.findClaude()
@@ -141,11 +578,23 @@ func (i *Installer) getInstalledClaudeVersion() (string, error) {
 	return strings.TrimSpace(version), nil
 }
 
-// verifyClaudeCode checks that the claude CLI is accessible after installation.
+// verifyClaudeCode checks that the claude CLI is accessible after installation
+// and that the claude a terminal runs is actually Claude Code.
 func (i *Installer) verifyClaudeCode() error {
-	extraPaths := []string{
-		fmt.Sprintf(`%s\npm\claude.cmd`, getAppDataPath()),
-		fmt.Sprintf(`%s\npm\claude.ps1`, getAppDataPath()),
+	shadow := detector.CheckClaudeShadowing()
+	if shadow.Alias != "" {
+		i.emitDetail("claudecode", "warning: a shell alias may redefine claude: %s", shadow.Alias)
+	}
+	if len(shadow.Packages) > 0 {
+		i.emitProgress("claudecode", "installing", claudeBinConflictWarning(shadow), 95)
+	}
+	if shadow.Path != "" {
+		return claudeShadowedError(shadow)
+	}
+
+	var extraPaths []string
+	for _, dir := range detector.NpmBinDirs() {
+		extraPaths = append(extraPaths, filepath.Join(dir, "claude.cmd"), filepath.Join(dir, "claude.ps1"))
 	}
 	return i.verifyExecutable("claude", "claudecode", "--version", extraPaths)
 }
@@ -173,25 +622,53 @@ func (i *Installer) findNpm() (string, error) {
 	return "", fmt.Errorf("npm not found in PATH")
 }
 
-// findClaude locates the claude executable.
+// claudeBinConflictWarning names the global npm packages whose claude
+// binaries conflict and the one that currently wins.
+func claudeBinConflictWarning(shadow detector.ClaudeShadowing) string {
+	var others []string
+	for _, pkg := range shadow.Packages {
+		if pkg != claudeCodePackage {
+			others = append(others, pkg)
+		}
+	}
+	winner := "it is unclear which one runs"
+	if shadow.Winner != "" {
+		winner = shadow.Winner + " currently runs"
+	}
+	return fmt.Sprintf("Warning: several global npm packages provide a claude command (%s); %s. Uninstall the others with: npm uninstall -g %s",
+		strings.Join(shadow.Packages, ", "), winner, strings.Join(others, " "))
+}
+
+// claudeShadowedError describes the program shadowing Claude Code.
+func claudeShadowedError(shadow detector.ClaudeShadowing) error {
+	if shadow.ClaudeCodePath != "" {
+		return fmt.Errorf("%w: %s comes before Claude Code (%s) on PATH", ErrClaudeShadowed, shadow.Path, shadow.ClaudeCodePath)
+	}
+	return fmt.Errorf("%w: %s is a different program", ErrClaudeShadowed, shadow.Path)
+}
+
+// findClaude locates the claude executable. When an unrelated claude comes
+// first on PATH, Claude Code's own executable is returned if it can be found.
 func (i *Installer) findClaude() (string, error) {
 	claudePath, err := exec.LookPath("claude")
 	if err == nil {
+		if shadow := detector.CheckClaudePath(); shadow.Path != "" {
+			if shadow.ClaudeCodePath == "" {
+				return "", claudeShadowedError(shadow)
+			}
+			return shadow.ClaudeCodePath, nil
+		}
 		return claudePath, nil
 	}
 
 	if runtime.GOOS == "windows" {
-		// Check npm global bin directories
-		commonPaths := []string{
-			`claude.cmd`,
-			`claude.ps1`,
-		}
-
-		// Try with APPDATA npm prefix
-		for _, p := range commonPaths {
-			fullPath := fmt.Sprintf(`%s\npm\%s`, getAppDataPath(), p)
-			if _, statErr := exec.LookPath(fullPath); statErr == nil {
-				return fullPath, nil
+		// Check npm global bin directories, including a custom prefix
+		for _, dir := range detector.NpmBinDirs() {
+			for _, name := range []string{"claude.cmd", "claude.ps1"} {
+				fullPath := filepath.Join(dir, name)
+				if _, statErr := exec.LookPath(fullPath); statErr == nil {
+					return fullPath, nil
+				}
 			}
 		}
 	}
@@ -213,3 +690,18 @@ func getAppDataPath() string {
 	}
 	return filepath.Join(home, "AppData", "Roaming")
 }
+
+// getLocalAppDataPath returns the LOCALAPPDATA directory path.
+func getLocalAppDataPath() string {
+	localAppData := os.Getenv("LOCALAPPDATA")
+	if localAppData != "" {
+		return localAppData
+	}
+
+	// Fallback: derive from user home directory
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join("C:\\Users\\Default", "AppData", "Local")
+	}
+	return filepath.Join(home, "AppData", "Local")
+}
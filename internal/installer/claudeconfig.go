@@ -0,0 +1,93 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// claudeConfigDirName is Claude Code's per-user configuration directory.
+const claudeConfigDirName = ".claude"
+
+// ClaudeConfigDir returns Claude Code's configuration directory in the user's
+// home directory. CLAUDE_CONFIG_DIR is deliberately ignored so a reset can
+// never be pointed outside the home .claude directory.
+func ClaudeConfigDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, claudeConfigDirName), nil
+}
+
+// ResetClaudeConfig clears Claude Code's configuration directory so the CLI
+// starts fresh on its next launch. With backup, the directory is renamed to a
+// timestamped sibling instead of deleted and the backup path is returned.
+// Progress is reported under the step "claudeConfigReset".
+func (i *Installer) ResetClaudeConfig(backup bool) (string, error) {
+	stepName := "claudeConfigReset"
+
+	if err := i.ensureClaudeNotRunning(stepName); err != nil {
+		return "", err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		i.emitProgress(stepName, "error", "Could not find the home directory", 0)
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+
+	i.emitProgress(stepName, "installing", "Resetting Claude Code configuration...", 20)
+	backupPath, err := resetConfigDir(home, backup, time.Now())
+	if err != nil {
+		i.emitProgress(stepName, "error", fmt.Sprintf("Failed to reset Claude Code configuration: %v", err), 0)
+		return "", err
+	}
+
+	message := "Claude Code configuration reset"
+	if backupPath != "" {
+		message = fmt.Sprintf("Claude Code configuration reset; backup saved to %s", backupPath)
+	}
+	i.emitProgress(stepName, "completed", message, 100)
+	return backupPath, nil
+}
+
+// resetConfigDir removes, or with backup renames, the .claude directory
+// directly under home. Nothing outside home/.claude is touched: a symlinked
+// .claude is unlinked rather than followed. A missing directory is not an error.
+func resetConfigDir(home string, backup bool, now time.Time) (string, error) {
+	if !filepath.IsAbs(home) {
+		return "", fmt.Errorf("home directory must be absolute: %s", home)
+	}
+	dir := filepath.Join(filepath.Clean(home), claudeConfigDirName)
+
+	info, err := os.Lstat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect %s: %w", dir, err)
+	}
+
+	if backup {
+		backupPath := fmt.Sprintf("%s.backup-%s", dir, now.Format("20060102-150405"))
+		if err := os.Rename(dir, backupPath); err != nil {
+			return "", fmt.Errorf("failed to back up %s: %w", dir, err)
+		}
+		return backupPath, nil
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		// Remove the link itself, never the directory it points to
+		if err := os.Remove(dir); err != nil {
+			return "", fmt.Errorf("failed to remove %s: %w", dir, err)
+		}
+		return "", nil
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return "", fmt.Errorf("failed to remove %s: %w", dir, err)
+	}
+	return "", nil
+}
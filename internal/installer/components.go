@@ -0,0 +1,57 @@
+package installer
+
+import "fmt"
+
+// Component names accepted by InstallAll-style flows.
+const (
+	ComponentNodeJS     = "nodejs"
+	ComponentGit        = "git"
+	ComponentClaudeCode = "claudecode"
+)
+
+// DefaultComponentOrder is the order components are installed in when the
+// caller does not choose one. Node.js comes first because Claude Code needs npm.
+var DefaultComponentOrder = []string{ComponentNodeJS, ComponentGit, ComponentClaudeCode}
+
+// componentDependencies lists, for each component, the components that must
+// be installed before it when both are requested.
+var componentDependencies = map[string][]string{
+	ComponentNodeJS:     nil,
+	ComponentGit:        nil,
+	ComponentClaudeCode: {ComponentNodeJS},
+}
+
+// ValidateComponentOrder checks that components names only known components,
+// each at most once, and that every component comes after the components it
+// depends on. Dependencies left out of the list are assumed to be installed
+// already.
+func ValidateComponentOrder(components []string) error {
+	if len(components) == 0 {
+		return fmt.Errorf("no components to install")
+	}
+
+	position := make(map[string]int, len(components))
+	for idx, name := range components {
+		if _, ok := componentDependencies[name]; !ok {
+			return fmt.Errorf("unknown component %q (want one of %v)", name, DefaultComponentOrder)
+		}
+		if _, dup := position[name]; dup {
+			return fmt.Errorf("component %q is listed more than once", name)
+		}
+		position[name] = idx
+	}
+
+	for _, name := range components {
+		for _, dep := range componentDependencies[name] {
+			if depIdx, ok := position[dep]; ok && depIdx > position[name] {
+				return fmt.Errorf("component %q must be installed after %q", name, dep)
+			}
+		}
+	}
+	return nil
+}
+
+// ComponentDependencies returns the components that name depends on.
+func ComponentDependencies(name string) []string {
+	return append([]string(nil), componentDependencies[name]...)
+}
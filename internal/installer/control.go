@@ -0,0 +1,118 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrStepSkipped is returned by an operation whose step the user skipped.
+var ErrStepSkipped = errors.New("step skipped")
+
+// errControlCancelled is returned once Cancel has been called. It wraps
+// context.Canceled so it is classified like a cancelled context.
+var errControlCancelled = fmt.Errorf("installation cancelled: %w", context.Canceled)
+
+// controlState holds the user's pause, skip and cancel requests. Downloads
+// and install steps poll it at checkpoints; unlike cancelling the context,
+// it lets a step stop at a safe point, and pausing blocks without failing.
+// The context remains the hard stop and always wins.
+type controlState struct {
+	paused    atomic.Bool
+	skip      atomic.Bool
+	cancelled atomic.Bool
+
+	// wake is closed and replaced whenever the state changes, to release
+	// checkpoints blocked while paused.
+	mu   sync.Mutex
+	wake chan struct{}
+}
+
+// set changes a flag and wakes any paused checkpoints.
+func (c *controlState) set(flag *atomic.Bool, value bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	flag.Store(value)
+	if c.wake != nil {
+		close(c.wake)
+		c.wake = nil
+	}
+}
+
+// waitChan returns a channel closed on the next state change.
+func (c *controlState) waitChan() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.wake == nil {
+		c.wake = make(chan struct{})
+	}
+	return c.wake
+}
+
+// checkpoint returns ctx's error, errControlCancelled or ErrStepSkipped when
+// the operation should stop, blocking for as long as it is paused.
+func (c *controlState) checkpoint(ctx context.Context) error {
+	for {
+		// Taken before the flags are read so a change in between isn't missed
+		wake := c.waitChan()
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case c.cancelled.Load():
+			return errControlCancelled
+		case c.skip.Load():
+			return ErrStepSkipped
+		case !c.paused.Load():
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-wake:
+		}
+	}
+}
+
+// Pause makes the installer stop at its next checkpoint, between download
+// reads or before running the next command, until Resume is called.
+func (i *Installer) Pause() {
+	i.control.set(&i.control.paused, true)
+}
+
+// Resume continues an installer paused with Pause.
+func (i *Installer) Resume() {
+	i.control.set(&i.control.paused, false)
+}
+
+// Paused reports whether the installer is paused.
+func (i *Installer) Paused() bool {
+	return i.control.paused.Load()
+}
+
+// SkipStep stops the current operation at its next checkpoint with
+// ErrStepSkipped, even while paused, so the caller can continue with the
+// next step.
+func (i *Installer) SkipStep() {
+	i.control.set(&i.control.skip, true)
+}
+
+// Cancel stops the current operation at its next checkpoint with an error
+// wrapping context.Canceled, even while paused. Cancel the installer's
+// context instead to interrupt a running command immediately.
+func (i *Installer) Cancel() {
+	i.control.set(&i.control.cancelled, true)
+}
+
+// checkpoint blocks while the installer is paused and returns an error when
+// the operation should stop.
+func (i *Installer) checkpoint() error {
+	return i.control.checkpoint(i.ctx)
+}
+
+// stopRequested reports whether err ended an operation because it was
+// skipped or cancelled, in which case no fallback should be tried.
+func (i *Installer) stopRequested(err error) bool {
+	return i.ctx.Err() != nil || errors.Is(err, ErrStepSkipped) || errors.Is(err, errControlCancelled)
+}
@@ -0,0 +1,135 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"claude-code-installer/internal/downloader"
+)
+
+// downloadCacheMaxAge is how long a cached download is kept without being
+// reused before it is pruned.
+const downloadCacheMaxAge = 14 * 24 * time.Hour
+
+// downloadCacheEntry is the metadata stored next to a cached download.
+type downloadCacheEntry struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+	downloader.Validators
+}
+
+// DefaultDownloadCacheDir returns the download cache location in the user's
+// cache directory.
+func DefaultDownloadCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "claude-code-installer", "downloads"), nil
+}
+
+// SetDownloadCacheDir configures a directory where downloads are cached with
+// the server's ETag and Last-Modified. Later downloads of the same URL are
+// made conditional and reuse the cached file when the server answers 304 Not
+// Modified. An empty dir disables the cache.
+func (i *Installer) SetDownloadCacheDir(dir string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.downloadCacheDir = dir
+}
+
+// downloadCachePaths returns where the file and metadata for url are cached.
+func downloadCachePaths(dir, url string) (string, string) {
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:16])
+	return filepath.Join(dir, key), filepath.Join(dir, key+".json")
+}
+
+// loadCachedDownload returns the cache entry and file path for url when a
+// cached file exists and still matches the checksum recorded with it. A
+// corrupted entry is removed.
+func loadCachedDownload(dir, url string) (downloadCacheEntry, string, bool) {
+	filePath, metaPath := downloadCachePaths(dir, url)
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return downloadCacheEntry{}, "", false
+	}
+
+	var entry downloadCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url || entry.Validators.IsZero() {
+		return downloadCacheEntry{}, "", false
+	}
+	if err := downloader.VerifyFileChecksum(filePath, entry.SHA256); err != nil {
+		os.Remove(filePath)
+		os.Remove(metaPath)
+		return downloadCacheEntry{}, "", false
+	}
+	return entry, filePath, true
+}
+
+// storeCachedDownload copies a completed download into the cache along with
+// its validators, and prunes entries that have not been used recently.
+// Downloads without validators are not cached, since they can't be revalidated.
+func storeCachedDownload(dir, url, srcPath string, result *downloader.Result) error {
+	if result.Validators.IsZero() {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create download cache: %w", err)
+	}
+	pruneDownloadCache(dir, time.Now().Add(-downloadCacheMaxAge))
+
+	filePath, metaPath := downloadCachePaths(dir, url)
+	// Drop the old metadata first so a failed copy can't pair it with a new file
+	os.Remove(metaPath)
+	if err := copyFile(srcPath, filePath); err != nil {
+		return err
+	}
+	data, err := json.Marshal(downloadCacheEntry{URL: url, SHA256: result.SHA256, Validators: result.Validators})
+	if err != nil {
+		return fmt.Errorf("failed to encode download cache entry: %w", err)
+	}
+	if err := os.WriteFile(metaPath, data, 0600); err != nil {
+		os.Remove(filePath)
+		return fmt.Errorf("failed to write download cache entry: %w", err)
+	}
+	return nil
+}
+
+// pruneDownloadCache removes cache entries last used before cutoff.
+func pruneDownloadCache(dir string, cutoff time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		os.Remove(filepath.Join(dir, strings.TrimSuffix(name, ".json")))
+		os.Remove(filepath.Join(dir, name))
+	}
+}
+
+// reuseCachedDownload copies the cached file for a download the server
+// reported unchanged to destPath and marks the entry as recently used.
+func reuseCachedDownload(dir, url, cachedPath, destPath string) error {
+	if err := copyFile(cachedPath, destPath); err != nil {
+		return fmt.Errorf("failed to reuse cached download: %w", err)
+	}
+	_, metaPath := downloadCachePaths(dir, url)
+	now := time.Now()
+	_ = os.Chtimes(metaPath, now, now)
+	return nil
+}
@@ -7,13 +7,13 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 
+	"claude-code-installer/internal/detector"
 	"claude-code-installer/internal/httputil"
-	"claude-code-installer/internal/pathutil"
+	"claude-code-installer/internal/updater"
 )
 
 const (
@@ -27,6 +27,7 @@ const (
 type gitReleaseAsset struct {
 	Name               string `json:"name"`
 	BrowserDownloadURL string `json:"browser_download_url"`
+	ContentType        string `json:"content_type"`
 	Size               int64  `json:"size"`
 }
 
@@ -37,71 +38,159 @@ type gitRelease struct {
 }
 
 // InstallGit installs Git using winget (preferred) or direct download (fallback).
+// On macOS it uses Homebrew, or the Xcode Command Line Tools when Homebrew is missing;
+// on Linux it uses the distribution package manager.
 func (i *Installer) InstallGit() error {
+	return i.withTelemetry("git", func() error {
+		return i.withPostInstallHook("git", i.installGit)
+	})
+}
+
+// installGit performs the Git installation; see InstallGit.
+func (i *Installer) installGit() error {
 	stepName := "git"
 
+	if err := i.ensureNoPreviousInstall(stepName); err != nil {
+		return err
+	}
+
 	i.emitProgress(stepName, "installing", "Checking for existing Git installation...", 0)
 
-	// Check if already installed
-	if _, err := exec.LookPath("git"); err == nil {
+	// Check if already installed and actually runnable
+	if found, working := i.isCommandWorking("git"); working {
 		i.emitProgress(stepName, "completed", "Git is already installed", 100)
 		return nil
+	} else if found {
+		i.emitProgress(stepName, "installing", "Existing Git installation is broken, reinstalling...", 5)
 	}
 
-	// Strategy 1: Try winget
-	if isWingetAvailable() {
-		i.emitProgress(stepName, "installing", "Installing Git via winget...", 10)
-
-		err := i.installGitViaWinget()
-		if err == nil {
-			// Refresh PATH and verify
-			_ = pathutil.RefreshPath()
-
-			if verifyErr := i.verifyGit(); verifyErr == nil {
-				i.emitProgress(stepName, "completed", "Git installed successfully via winget", 100)
-				return nil
-			}
+	// The strategies below are Windows-specific (winget, MSI/EXE installers,
+	// Program Files paths); other platforms use their package manager
+	switch runtime.GOOS {
+	case "windows":
+	case "darwin":
+		if !isBrewAvailable() {
+			return i.installGitViaXcodeCLT()
 		}
-
-		i.emitProgress(stepName, "installing", "Winget installation failed, trying direct download...", 20)
+		return i.installViaBrew(stepName, "Git", brewGitFormula, "git")
+	case "linux":
+		return i.installViaLinuxPackageManager(stepName, "Git",
+			func(pm *linuxPackageManager) []string { return pm.gitPackages }, "git")
+	default:
+		return i.unsupportedPlatformError(stepName, "Git")
 	}
 
-	// Strategy 2: Direct download from GitHub
-	i.emitProgress(stepName, "installing", "Downloading Git installer...", 25)
+	i.warnSystemInstallElevation(stepName, "Git", "git", 6)
 
-	err := i.installGitViaDownload()
+	err := i.runBackends(stepName, map[string]installBackend{
+		StrategyWinget:   {StrategyWinget, i.installGitWithWinget},
+		StrategyScoop:    {StrategyScoop, i.installGitWithScoop},
+		StrategyDownload: {StrategyDownload, i.installGitWithDownload},
+	})
 	if err != nil {
 		i.emitProgress(stepName, "error", fmt.Sprintf("Failed to install Git: %v", err), 0)
 		return fmt.Errorf("failed to install Git: %w", err)
 	}
+	return nil
+}
+
+// installGitWithWinget installs Git via winget and verifies it.
+func (i *Installer) installGitWithWinget() error {
+	stepName := "git"
+	i.emitProgress(stepName, "installing", "Installing Git via winget...", 10)
+
+	if err := i.installGitViaWinget(); err != nil {
+		return err
+	}
 
-	// Refresh PATH after installation
-	_ = pathutil.RefreshPath()
+	// Refresh PATH and verify
+	i.refreshPath(stepName)
+	if err := i.verifyGit(); err != nil {
+		return fmt.Errorf("installed but not found on PATH: %w", err)
+	}
+	i.checkGitNetworking(stepName)
+
+	i.emitProgress(stepName, "completed", "Git installed successfully via winget", 100)
+	return nil
+}
+
+// installGitWithScoop installs Git via Scoop and verifies it.
+func (i *Installer) installGitWithScoop() error {
+	stepName := "git"
+	i.emitProgress(stepName, "installing", "Installing Git via Scoop...", 10)
+
+	if err := i.installViaScoop(stepName, scoopGitPackage); err != nil {
+		return err
+	}
+	if err := i.verifyGit(); err != nil {
+		return fmt.Errorf("installed but not found on PATH: %w", err)
+	}
+	i.checkGitNetworking(stepName)
+
+	i.emitProgress(stepName, "completed", "Git installed successfully via Scoop", 100)
+	return nil
+}
+
+// installGitWithDownload installs Git from the GitHub release and verifies it.
+func (i *Installer) installGitWithDownload() error {
+	stepName := "git"
+	if err := i.requireElevationForSystemInstall(stepName, "Git", "git"); err != nil {
+		return err
+	}
+	i.emitProgress(stepName, "installing", "Downloading Git installer...", 25)
+
+	if err := i.installGitViaDownload(); err != nil {
+		return err
+	}
 
-	// Add Git to PATH if not already present
-	if err := pathutil.AddToPath(defaultGitPath); err != nil {
-		i.emitProgress(stepName, "installing", "Warning: could not add Git to PATH automatically", 90)
+	// Refresh PATH after installation
+	i.refreshPath(stepName)
+
+	// Add Git to PATH if not already present. A 32-bit Git on 64-bit
+	// Windows installs under Program Files (x86)
+	i.mu.Lock()
+	bits := i.gitBitness
+	i.mu.Unlock()
+	if err := i.addToPath(stepName, gitCmdDir(gitInstallerArch(bits), os.Getenv)); err != nil {
+		i.emitProgress(stepName, "installing", pathWarning("Git", err), 90)
 	}
 
 	// Verify installation
 	if err := i.verifyGit(); err != nil {
-		i.emitProgress(stepName, "error", "Git was installed but verification failed. Please restart the application.", 0)
-		return fmt.Errorf("Git installed but verification failed: %w", err)
+		return fmt.Errorf("Git was installed but verification failed (restart the application to retry): %w", err)
 	}
+	i.checkGitNetworking(stepName)
 
 	i.emitProgress(stepName, "completed", "Git installed successfully", 100)
 	return nil
 }
 
+// installGitViaXcodeCLT starts the Xcode Command Line Tools installer, which
+// provides git on macOS. The installer is interactive, so the user must finish
+// it and retry.
+func (i *Installer) installGitViaXcodeCLT() error {
+	stepName := "git"
+	i.setStrategy(stepName, StrategyXcodeCLT)
+	i.emitProgress(stepName, "installing", "Requesting Xcode Command Line Tools installation...", 10)
+
+	if _, err := i.runCommand("xcode-select", "--install"); err != nil {
+		i.emitProgress(stepName, "error", fmt.Sprintf("Failed to start Xcode Command Line Tools installation: %v", err), 0)
+		return fmt.Errorf("failed to start Xcode Command Line Tools installation: %w", err)
+	}
+
+	i.emitProgress(stepName, "error",
+		"Please complete the Xcode Command Line Tools installation in the dialog, then try again.", 0)
+	return fmt.Errorf("Git requires the Xcode Command Line Tools; finish the system installer and retry")
+}
+
 // installGitViaWinget installs Git using the Windows Package Manager.
 func (i *Installer) installGitViaWinget() error {
-	_, err := i.runCommand("winget", "install",
+	return i.runWinget("git", "install",
 		wingetGitPackage,
 		"--silent",
 		"--accept-package-agreements",
 		"--accept-source-agreements",
 	)
-	return err
 }
 
 // installGitViaDownload downloads and installs Git from GitHub releases.
@@ -129,6 +218,7 @@ func (i *Installer) installGitViaDownload() error {
 	// Verify download integrity via SHA-256 checksum (mandatory)
 	i.emitProgress("git", "installing", "Verifying download integrity...", 55)
 	checksumURL := downloadURL + ".sha256"
+	i.emitDetail("git", "checksum source: release asset %s", checksumURL)
 	checksumContent, err := i.fetchTextContent(checksumURL)
 	if err != nil {
 		return fmt.Errorf("failed to verify Git download integrity (could not fetch checksum): %w", err)
@@ -139,27 +229,38 @@ func (i *Installer) installGitViaDownload() error {
 	if len(parts) > 0 {
 		expectedHash = parts[0]
 	}
-	if err := verifyFileChecksum(installerPath, expectedHash); err != nil {
+	if err := i.verifyFileChecksumWithProgress(installerPath, expectedHash, "git", 55, 65); err != nil {
 		return fmt.Errorf("Git installer integrity check failed: %w", err)
 	}
 	i.emitProgress("git", "installing", "Download integrity verified", 65)
 
+	// The asset was chosen by file name; confirm it is really Git for Windows
+	// before running it silently
+	if err := validateGitInstallerProduct(installerPath); err != nil {
+		return err
+	}
+	i.retainDownload(installerPath, "git")
+
 	i.emitProgress("git", "installing", "Running Git installer...", 70)
+	if err := verifyFileOwner(installerPath); err != nil {
+		return err
+	}
 
-	// Run the installer with silent options
-	// /VERYSILENT - no UI at all
-	// /NORESTART - don't restart the system
-	// /SP- - suppress "This will install..." prompt
-	// /CLOSEAPPLICATIONS - close running applications if needed
-	// /NOCANCEL - remove cancel button
-	_, err = i.runCommand(installerPath,
-		"/VERYSILENT",
-		"/NORESTART",
-		"/SP-",
-		"/CLOSEAPPLICATIONS",
-		"/NOCANCEL",
-	)
+	// Run the installer silently (see defaultGitInstallerArgs), writing a
+	// detailed setup log that is read back on failure for diagnostics
+	logPath := filepath.Join(tempDir, "git-install.log")
+	args := append(i.installerArgs(ComponentGit, defaultGitInstallerArgs), "/LOG="+logPath)
+	_, err = i.runCommand(installerPath, args...)
 	if err != nil {
+		if i.ctx.Err() != nil {
+			// Killing the launcher doesn't stop the setup process it spawned, so
+			// Git may still be installing; report that instead of verifying now
+			return fmt.Errorf("%w: the Git installer may still be running; check Git once it finishes", ErrInstallerInterrupted)
+		}
+		// Read the log now; the deferred RemoveAll deletes the temp directory
+		if tail := logTail(logPath, installerLogTailLines); tail != "" {
+			return fmt.Errorf("Git installer failed: %w\nInstaller log (last lines):\n%s", err, tail)
+		}
 		return fmt.Errorf("Git installer failed: %w", err)
 	}
 
@@ -169,66 +270,196 @@ func (i *Installer) installGitViaDownload() error {
 
 // getGitDownloadURL fetches the latest Git for Windows download URL from GitHub.
 func (i *Installer) getGitDownloadURL() (string, error) {
-	req, err := http.NewRequestWithContext(i.ctx, "GET", gitReleasesAPIURL, nil)
+	release, err := i.getLatestGitRelease()
 	if err != nil {
 		return "", err
 	}
+	asset, err := i.gitInstallerAsset(release)
+	if err != nil {
+		return "", err
+	}
+	return asset.BrowserDownloadURL, nil
+}
+
+// gitInstallerAsset picks the installer for the requested or detected
+// architecture from release.
+func (i *Installer) gitInstallerAsset(release *gitRelease) (gitReleaseAsset, error) {
+	i.mu.Lock()
+	bits := i.gitBitness
+	i.mu.Unlock()
+	arch := gitInstallerArch(bits)
+	if bits != 0 {
+		i.emitDetail("git", "installer flavor %s requested (arch detected: %s)", arch, runtime.GOARCH)
+	} else {
+		i.emitDetail("git", "arch detected: %s (installer flavor %s)", runtime.GOARCH, arch)
+	}
+
+	asset, ok := selectGitAsset(release.Assets, arch)
+	if !ok {
+		return gitReleaseAsset{}, fmt.Errorf("could not find Git installer in latest release")
+	}
+	if !strings.Contains(strings.ToLower(asset.Name), arch) {
+		if bits != 0 {
+			// A requested bitness is not silently swapped for another
+			return gitReleaseAsset{}, fmt.Errorf("Git release %s has no %s installer", release.TagName, arch)
+		}
+		i.emitDetail("git", "no %s installer asset in release %s, falling back to any installer", arch, release.TagName)
+	}
+	i.emitDetail("git", "installer asset: %s (%s) from release %s", asset.Name, asset.ContentType, release.TagName)
+	return asset, nil
+}
+
+// SetGitBitness selects the Git for Windows installer to download: 32 or 64
+// bits, regardless of the system's architecture, for tools that need a
+// particular build. Zero restores the default, which follows the system. The
+// latest release must include an installer of the requested bitness.
+func (i *Installer) SetGitBitness(bits int) error {
+	if err := ValidateGitBitness(bits); err != nil {
+		return err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.gitBitness = bits
+	return nil
+}
+
+// ValidateGitBitness checks that bits is 0, 32 or 64; see SetGitBitness.
+func ValidateGitBitness(bits int) error {
+	switch bits {
+	case 0, 32, 64:
+		return nil
+	default:
+		return fmt.Errorf("Git bitness must be 32 or 64, got %d", bits)
+	}
+}
+
+// gitInstallerArch returns the installer flavor ("64-bit" or "32-bit") for
+// bits, or for the system's architecture when bits is zero.
+func gitInstallerArch(bits int) string {
+	if bits == 32 || (bits == 0 && runtime.GOARCH == "386") {
+		return "32-bit"
+	}
+	return "64-bit"
+}
+
+// gitCmdDir returns the cmd directory of a Git for Windows installed by the
+// arch ("64-bit" or "32-bit") installer, reading the Program Files locations
+// with getenv. ProgramW6432 and ProgramFiles(x86) name the native and 32-bit
+// locations whether or not this process is 32-bit.
+func gitCmdDir(arch string, getenv func(string) string) string {
+	programFiles := getenv("ProgramW6432")
+	if arch == "32-bit" {
+		// Unset on 32-bit Windows, where everything is in Program Files
+		programFiles = getenv("ProgramFiles(x86)")
+	}
+	if programFiles == "" {
+		programFiles = getenv("ProgramFiles")
+	}
+	if programFiles == "" {
+		return defaultGitPath
+	}
+	return strings.TrimRight(programFiles, `\`) + `\Git\cmd`
+}
+
+// selectGitAsset picks the standalone Git installer for arch ("64-bit" or
+// "32-bit") from a release's assets. Assets whose content type identifies a
+// Windows installer are preferred over those that only look like one by
+// name, and an installer for arch over one for any architecture. Portable
+// and MinGit builds, and assets not hosted on GitHub, are never selected.
+func selectGitAsset(assets []gitReleaseAsset, arch string) (gitReleaseAsset, bool) {
+	byContentType := func(a gitReleaseAsset) bool { return updater.IsWindowsInstallerContentType(a.ContentType) }
+	byName := func(a gitReleaseAsset) bool { return strings.HasSuffix(strings.ToLower(a.Name), ".exe") }
+
+	for _, anyArch := range []bool{false, true} {
+		for _, matches := range []func(gitReleaseAsset) bool{byContentType, byName} {
+			for _, asset := range assets {
+				name := strings.ToLower(asset.Name)
+				if !anyArch && !strings.Contains(name, arch) {
+					continue
+				}
+				if strings.Contains(name, "portable") || strings.Contains(name, "mingit") {
+					continue
+				}
+				if !matches(asset) || validateGitHubDownloadURL(asset.BrowserDownloadURL) != nil {
+					continue
+				}
+				return asset, true
+			}
+		}
+	}
+	return gitReleaseAsset{}, false
+}
+
+// getLatestGitRelease returns the latest Git for Windows release metadata,
+// fetched from GitHub at most once per metadataCacheTTL.
+func (i *Installer) getLatestGitRelease() (*gitRelease, error) {
+	return cachedMetadata(releaseMetadata, "github:"+gitReleasesAPIURL, i.fetchLatestGitRelease)
+}
+
+// fetchLatestGitRelease fetches the latest Git for Windows release metadata from GitHub.
+func (i *Installer) fetchLatestGitRelease() (*gitRelease, error) {
+	req, err := http.NewRequestWithContext(i.ctx, "GET", gitReleasesAPIURL, nil)
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", "claude-code-installer")
 
 	client := &http.Client{
 		Timeout:       apiRequestTimeout,
 		CheckRedirect: httputil.NewTrustedCheckRedirect(httputil.GitHubTrustedHosts()),
+		Transport:     i.transport(),
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch Git releases: %w", err)
+		err = fmt.Errorf("failed to fetch Git releases: %w", err)
+		if isNetworkFailure(err) {
+			err = i.annotateOutage("git", serviceGitHub, err)
+		}
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
 	}
 
 	var release gitRelease
 	if err := json.NewDecoder(io.LimitReader(resp.Body, maxTextResponseSize)).Decode(&release); err != nil {
-		return "", fmt.Errorf("failed to parse release info: %w", err)
+		return nil, fmt.Errorf("failed to parse release info: %w", err)
 	}
 
-	// Find the appropriate installer asset
-	arch := "64-bit"
-	if runtime.GOARCH == "386" {
-		arch = "32-bit"
-	}
+	return &release, nil
+}
 
-	for _, asset := range release.Assets {
-		name := strings.ToLower(asset.Name)
-		// Look for the standalone installer (not portable, not mingit)
-		if strings.Contains(name, arch) &&
-			strings.HasSuffix(name, ".exe") &&
-			!strings.Contains(name, "portable") &&
-			!strings.Contains(name, "mingit") {
-			if err := validateGitHubDownloadURL(asset.BrowserDownloadURL); err != nil {
-				continue
-			}
-			return asset.BrowserDownloadURL, nil
-		}
+// validateGitInstallerProduct checks the installer's version resource to
+// confirm it is the Git for Windows installer.
+func validateGitInstallerProduct(installerPath string) error {
+	product, err := exeProductName(installerPath)
+	if err != nil {
+		return fmt.Errorf("could not confirm the download is the Git for Windows installer: %w", err)
 	}
-
-	// Fallback: any exe installer
-	for _, asset := range release.Assets {
-		name := strings.ToLower(asset.Name)
-		if strings.HasSuffix(name, ".exe") &&
-			!strings.Contains(name, "portable") &&
-			!strings.Contains(name, "mingit") {
-			if err := validateGitHubDownloadURL(asset.BrowserDownloadURL); err != nil {
-				continue
-			}
-			return asset.BrowserDownloadURL, nil
-		}
+	if !isGitForWindowsProduct(product) {
+		return fmt.Errorf("downloaded installer is %q, not Git for Windows; refusing to run it", product)
 	}
+	return nil
+}
 
-	return "", fmt.Errorf("could not find Git installer in latest release")
+// isGitForWindowsProduct reports whether a version-resource product name
+// belongs to the Git for Windows installer, which declares itself as "Git".
+func isGitForWindowsProduct(product string) bool {
+	product = strings.ToLower(strings.TrimSpace(product))
+	return product == "git" || strings.HasPrefix(product, "git for windows")
+}
+
+// gitVersionFromTag converts a Git for Windows release tag such as
+// "v2.47.1.windows.1" into a plain version ("2.47.1").
+func gitVersionFromTag(tag string) string {
+	version := strings.TrimPrefix(strings.TrimSpace(tag), "v")
+	if idx := strings.Index(version, ".windows"); idx != -1 {
+		version = version[:idx]
+	}
+	return version
 }
 
 // validateGitHubDownloadURL ensures the download URL is from a trusted GitHub domain.
@@ -258,5 +489,6 @@ func (i *Installer) verifyGit() error {
 		`C:\Program Files\Git\cmd\git.exe`,
 		`C:\Program Files (x86)\Git\cmd\git.exe`,
 		`C:\Program Files\Git\bin\git.exe`,
+		filepath.Join(detector.ScoopShimsDir(), "git.exe"),
 	})
 }
@@ -0,0 +1,60 @@
+package installer
+
+import (
+	"fmt"
+
+	"claude-code-installer/internal/detector"
+)
+
+// gitCredentialManagerHelper is the credential.helper value that selects
+// Git Credential Manager.
+const gitCredentialManagerHelper = "manager"
+
+// ConfigureGitCredentialManager sets Git Credential Manager as the global
+// credential helper so pushes to GitHub can authenticate through the browser
+// instead of prompting for a password. A helper that already selects Git
+// Credential Manager, including the older manager-core, is left as is. It
+// fails without changing the configuration when Git Credential Manager is
+// not installed; Git for Windows bundles it.
+func (i *Installer) ConfigureGitCredentialManager() error {
+	stepName := "gitCredentials"
+
+	i.emitProgress(stepName, "installing", "Checking Git Credential Manager...", 10)
+
+	gitPath, err := i.findGit()
+	if err != nil {
+		i.emitProgress(stepName, "error", "Git is not installed", 0)
+		return fmt.Errorf("Git is required to configure credentials: %w", err)
+	}
+
+	status := detector.CheckGitCredentials()
+	if !status.ManagerInstalled {
+		i.emitProgress(stepName, "error", "Git Credential Manager is not installed", 0)
+		return fmt.Errorf("Git Credential Manager is not available")
+	}
+	i.emitDetail(stepName, "Git Credential Manager %s", status.ManagerVersion)
+
+	if status.UsesManager {
+		i.emitDetail(stepName, "credential.helper is already %s", status.Helper)
+		i.emitProgress(stepName, "completed", "Git Credential Manager is already configured", 100)
+		return nil
+	}
+
+	// Replace every global helper; a leftover one would still be consulted
+	i.emitProgress(stepName, "installing", "Setting Git Credential Manager as the credential helper...", 50)
+	if _, err := i.runCommand(gitPath, "config", "--global", "--replace-all", "credential.helper", gitCredentialManagerHelper); err != nil {
+		i.emitProgress(stepName, "error", fmt.Sprintf("Failed to configure Git credentials: %v", err), 0)
+		return fmt.Errorf("failed to set credential.helper: %w", err)
+	}
+
+	i.emitProgress(stepName, "completed", "Git Credential Manager configured", 100)
+	return nil
+}
+
+// findGit locates the git executable.
+func (i *Installer) findGit() (string, error) {
+	if gitPath := detector.FindGit(); gitPath != "" {
+		return gitPath, nil
+	}
+	return "", fmt.Errorf("git not found in PATH")
+}
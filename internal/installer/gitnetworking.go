@@ -0,0 +1,93 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrGitNetworking describes an installed Git that runs but cannot make
+// HTTPS connections, typically because its bundled curl or OpenSSL is
+// damaged or was removed by antivirus software. It is reported as a warning
+// after installation, not as an install failure.
+var ErrGitNetworking = errors.New("Git is installed but cannot make HTTPS connections")
+
+const (
+	// gitNetworkCheckURL is a well-known public repository. Git connects to
+	// it with its own HTTP stack, so the trusted host checks don't apply.
+	gitNetworkCheckURL = "https://github.com/git/git.git"
+	// gitNetworkCheckTimeout bounds the ls-remote network check.
+	gitNetworkCheckTimeout = 30 * time.Second
+)
+
+// gitUnreachableMarkers are git and curl error texts for network conditions
+// outside Git's own installation, such as being offline.
+var gitUnreachableMarkers = []string{
+	"could not resolve host",
+	"could not resolve proxy",
+	"failed to connect",
+	"connection timed out",
+	"operation timed out",
+	"connection refused",
+	"network is unreachable",
+	"proxy connect aborted",
+	"received http code 407",
+}
+
+// checkGitNetworking runs `git ls-remote` against a public repository to
+// confirm that Git's bundled HTTPS stack works, since a damaged install can
+// pass `git --version` yet fail every clone. It runs after Git installed
+// successfully, so a failure is reported as a warning rather than failing
+// the step: reinstalling with another backend would not help when a
+// TLS-inspecting proxy or the network is the cause.
+func (i *Installer) checkGitNetworking(stepName string) {
+	gitPath, err := i.findGit()
+	if err != nil {
+		return
+	}
+	i.emitProgress(stepName, "installing", "Checking that Git can connect over HTTPS...", 97)
+
+	ctx, cancel := context.WithTimeout(i.ctx, gitNetworkCheckTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, gitPath, "ls-remote", "--heads", gitNetworkCheckURL, "refs/heads/master")
+	// Never wait on a credential prompt
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	hideConsoleWindow(cmd)
+	done := i.trackCommand()
+	output, err := cmd.CombinedOutput()
+	done()
+	if err == nil || i.ctx.Err() != nil {
+		return
+	}
+
+	detail := lastLine(string(output))
+	if ctx.Err() != nil || gitNetworkUnreachable(string(output)) {
+		i.emitDetail(stepName, "warning: could not check Git's HTTPS connectivity: %s", detail)
+		return
+	}
+	i.emitDetail(stepName, "%v: git ls-remote %s failed: %s", ErrGitNetworking, gitNetworkCheckURL, detail)
+	i.emitProgress(stepName, "installing",
+		"Warning: "+errorRemediation[ErrorCodeGitNetworking], 98)
+}
+
+// gitNetworkUnreachable reports whether git output describes a network
+// problem rather than a broken Git installation.
+func gitNetworkUnreachable(output string) bool {
+	lower := strings.ToLower(output)
+	for _, marker := range gitUnreachableMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastLine returns the last non-empty line of output, which is where git
+// reports the error that ended the command.
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -4,20 +4,28 @@
 package installer
 
 import (
+	"bufio"
+	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
 	"time"
 
+	"claude-code-installer/internal/detector"
+	"claude-code-installer/internal/downloader"
+	"claude-code-installer/internal/elevation"
 	"claude-code-installer/internal/httputil"
+	"claude-code-installer/internal/logging"
+	"claude-code-installer/internal/pathutil"
 )
 
 const (
@@ -27,6 +35,10 @@ const (
 	downloadTimeout     = 10 * time.Minute
 	apiRequestTimeout   = 30 * time.Second
 
+	// installerLogTailLines is how many trailing installer log lines are
+	// included in an error when a native installer fails.
+	installerLogTailLines = 40
+
 	// Default installation paths for Windows
 	defaultNodeJSPath = `C:\Program Files\nodejs`
 	defaultGitPath    = `C:\Program Files\Git\cmd`
@@ -38,64 +50,788 @@ type InstallProgress struct {
 	Status     string  `json:"status"` // "pending", "installing", "completed", "error"
 	Message    string  `json:"message"`
 	Percentage float64 `json:"percentage"`
+	// Strategy is the installation method in use (one of the Strategy* constants).
+	Strategy string `json:"strategy,omitempty"`
+	// FallbackReason is set on the event emitted when a strategy fails and the
+	// installer switches to another one.
+	FallbackReason string `json:"fallbackReason,omitempty"`
+}
+
+// InstallDetail is a low-level record of a decision the installer made, such as
+// whether winget was usable or which checksum source was consulted. Details are
+// meant for diagnostics logs rather than progress display.
+type InstallDetail struct {
+	Step    string `json:"step"`
+	Message string `json:"message"`
 }
 
+// Installation strategies reported in InstallProgress.Strategy.
+const (
+	StrategyWinget   = "winget"
+	StrategyMSI      = "msi"
+	StrategyZip      = "zip"
+	StrategyDownload = "download"
+	StrategyNPM      = "npm"
+	StrategyPNPM     = "pnpm"
+	StrategyYarn     = "yarn"
+	StrategyBrew     = "brew"
+	StrategyXcodeCLT = "xcode-clt"
+	StrategyApt      = "apt"
+	StrategyDnf      = "dnf"
+	StrategyPacman   = "pacman"
+	StrategyScoop    = "scoop"
+)
+
+// ErrUnsupportedPlatform is returned when a component cannot be installed on
+// the current operating system.
+var ErrUnsupportedPlatform = errors.New("automatic installation is not supported on this platform")
+
+// ErrInstallerInterrupted is returned when an installation is cancelled while a
+// native installer runs. The installer may have continued in the background,
+// so the component's state must be re-checked rather than assumed.
+var ErrInstallerInterrupted = errors.New("installation was interrupted while the installer was running")
+
+// ErrDownloadOwnerChanged is returned when a downloaded installer is no longer
+// owned by the user who downloaded it, meaning it may have been replaced after
+// it was verified.
+var ErrDownloadOwnerChanged = errors.New("downloaded file is not owned by the current user")
+
+// maxFallbackReasonLength caps the error summary included in fallback events.
+const maxFallbackReasonLength = 200
+
 // Installer manages the installation of software components.
 type Installer struct {
 	ctx        context.Context
 	onProgress func(InstallProgress)
 	mu         sync.Mutex
+
+	// control holds pause, skip and cancel requests polled at checkpoints.
+	control controlState
+
+	// onDetail, if set, receives the decisions made while choosing strategies.
+	onDetail func(InstallDetail)
+	// onMilestone, if set, receives discrete progress announcements; see
+	// SetMilestoneHandler.
+	onMilestone func(Milestone)
+	// milestones tracks the last milestone announced for each step.
+	milestones map[string]*milestoneState
+	// onErrorDetail receives the full output of failed package manager commands.
+	onErrorDetail func(ErrorDetail)
+	// quiet suppresses intermediate progress events; see SetQuiet.
+	quiet bool
+
+	// recorder, if set, receives a copy of every progress event.
+	recorder *ProgressRecorder
+
+	// byteTracker, if set, accumulates downloaded bytes across installers.
+	byteTracker *ByteTracker
+
+	// downloadDir, when set, is a persistent directory where verified
+	// installers are retained for reuse on offline machines.
+	downloadDir string
+	retained    []string
+
+	// downloadCacheDir, when set, caches downloads for conditional re-downloads.
+	downloadCacheDir string
+
+	// portable forces the per-user zip install of Node.js instead of the MSI.
+	portable bool
+
+	// verifyNodeSignature requires a valid release signature on SHASUMS256.txt.
+	verifyNodeSignature bool
+
+	// checkServiceStatus queries upstream status pages after repeated
+	// failures; see SetServiceStatusCheck.
+	checkServiceStatus bool
+
+	// nodeMirror is an optional alternative base URL for Node.js downloads;
+	// trustMirror skips cross-checking its checksums against nodejs.org.
+	nodeMirror  string
+	trustMirror bool
+	// nodeFallbackMirrors are tried in order when the primary source fails.
+	nodeFallbackMirrors []string
+
+	// backendOrder overrides defaultBackendOrder for Windows installs.
+	backendOrder []string
+
+	// packageManager installs Claude Code; empty means npm. See SetPackageManager.
+	packageManager string
+
+	// processFile records running child processes for later sessions; see
+	// SetProcessFile.
+	processFile string
+
+	// gitBitness selects the 32- or 64-bit Git installer; zero follows the
+	// system. See SetGitBitness.
+	gitBitness int
+
+	// extraInstallerArgs are appended to the native installer command line,
+	// keyed by component; see SetExtraInstallerArgs.
+	extraInstallerArgs map[string][]string
+
+	// strategies records the strategy currently in use for each step.
+	strategies map[string]string
+
+	// lastPercentages records the last percentage emitted for each step in
+	// progress, so percentages never decrease; see clampProgress.
+	lastPercentages map[string]float64
+
+	// postInstallHook, if set, runs after a component is installed and verified.
+	postInstallHook func(component string) error
+
+	// telemetryHook, if set, receives an event at the end of each step.
+	telemetryHook TelemetryHook
+
+	// maxDownloadSize is the per-download size cap in bytes.
+	maxDownloadSize int64
+
+	// minTLSVersion is the lowest TLS version accepted for downloads and API
+	// requests; 0 uses httputil.DefaultMinTLSVersion.
+	minTLSVersion uint16
+	// preferIPv4 makes downloads and API requests connect over IPv4 only;
+	// see SetPreferIPv4.
+	preferIPv4 bool
+
+	// logger, if set, receives every progress event and strategy decision.
+	logger Logger
+
+	// versionHistoryPath, if set, is where Claude Code updates are recorded
+	// so they can be rolled back.
+	versionHistoryPath string
+
+	// elevationHandler, if set, is asked before relaunching a privileged
+	// sub-operation with administrator rights.
+	elevationHandler ElevationHandler
+
+	// pathStale is set when the process PATH could not be refreshed from the
+	// registry, so verification prefers known absolute install paths.
+	pathStale bool
+
+	// wingetSourcesOnce refreshes winget sources before the first winget install.
+	wingetSourcesOnce sync.Once
+
+	// commands tracks child processes started by this installer so shutdown
+	// can wait for them to exit.
+	commands sync.WaitGroup
+}
+
+// Logger persists installer activity. level is one of the logging.Level
+// constants: "debug", "info", "warn" or "error".
+// Implementations must be safe for concurrent use; logging.RotatingLogger is
+// the file-backed implementation.
+type Logger interface {
+	Log(level, step, message string) error
+}
+
+// ElevationHandler is asked to confirm an operation that needs administrator
+// rights. reason describes the operation; returning false declines elevation
+// and the installer falls back to a per-user alternative where one exists.
+type ElevationHandler func(reason string) bool
+
+// InstallOptions configures an Installer at construction time. The zero value
+// uses the defaults.
+type InstallOptions struct {
+	// DownloadDir, when set, retains verified installers for offline reuse.
+	DownloadDir string
+	// DownloadCacheDir, when set, caches downloads so unchanged files are not
+	// downloaded again; see SetDownloadCacheDir.
+	DownloadCacheDir string
+	// PortableMode installs Node.js per-user from the zip archive.
+	PortableMode bool
+	// VerifyNodeSignature checks SHASUMS256.txt.asc against the embedded
+	// Node.js release keys.
+	VerifyNodeSignature bool
+	// CheckServiceStatus queries the GitHub and npm status pages when
+	// requests to them keep failing; see SetServiceStatusCheck.
+	CheckServiceStatus bool
+	// NodeMirror is an alternative HTTPS base URL for Node.js downloads;
+	// TrustMirror skips cross-checking its checksums against nodejs.org.
+	NodeMirror  string
+	TrustMirror bool
+	// NodeFallbackMirrors are tried in order when the primary source fails.
+	NodeFallbackMirrors []string
+	// MaxDownloadSize caps a single download in bytes; 0 uses the 500 MB default.
+	MaxDownloadSize int64
+	// MinTLSVersion is the lowest TLS version accepted (a crypto/tls
+	// VersionTLS constant); 0 uses TLS 1.2.
+	MinTLSVersion uint16
+	// PreferIPv4 connects over IPv4 only; see SetPreferIPv4.
+	PreferIPv4 bool
+	// PostInstallHook runs after each component is installed and verified.
+	PostInstallHook func(component string) error
+	// TelemetryHook, if set, receives an anonymized event at the end of each
+	// step; see SetTelemetryHook.
+	TelemetryHook TelemetryHook
+	// ElevationHandler confirms operations that need administrator rights.
+	ElevationHandler ElevationHandler
+	// BackendOrder sets the order Windows installation backends are tried in;
+	// see SetBackendOrder.
+	BackendOrder []string
+	// ProgressRecorder, if set, receives a copy of every progress event.
+	ProgressRecorder *ProgressRecorder
+	// ByteTracker, if set, accumulates downloaded bytes for an overall
+	// byte-based percentage shared with other installers.
+	ByteTracker *ByteTracker
+	// OnDetail receives the decisions made while choosing install strategies.
+	OnDetail func(InstallDetail)
+	// OnErrorDetail receives the full output of failed package manager commands.
+	OnErrorDetail func(ErrorDetail)
+	// OnMilestone receives discrete progress announcements for screen
+	// readers; see SetMilestoneHandler.
+	OnMilestone func(Milestone)
+	// Logger, if set, persists progress events and strategy decisions.
+	Logger Logger
+	// VersionHistoryPath, if set, records Claude Code updates for rollback;
+	// see SetVersionHistoryPath.
+	VersionHistoryPath string
+	// ExtraInstallerArgs are appended to the native installer command line
+	// of each component; see SetExtraInstallerArgs.
+	ExtraInstallerArgs map[string][]string
+	// PackageManager installs Claude Code: "npm" (default), "pnpm" or "yarn".
+	PackageManager string
+	// GitBitness selects the 32- or 64-bit Git installer; zero (the default)
+	// follows the system. See SetGitBitness.
+	GitBitness int
+	// ProcessFile, if set, records running child processes so a later
+	// session can detect an install left running; see SetProcessFile.
+	ProcessFile string
+	// Quiet emits only each step's first and final progress events; see SetQuiet.
+	Quiet bool
 }
 
 // NewInstaller creates a new Installer instance with the given context and progress callback.
 func NewInstaller(ctx context.Context, onProgress func(InstallProgress)) *Installer {
 	return &Installer{
-		ctx:        ctx,
-		onProgress: onProgress,
+		ctx:             ctx,
+		onProgress:      onProgress,
+		maxDownloadSize: maxDownloadSize,
 	}
 }
 
-// emitProgress sends a progress update via the callback.
+// NewInstallerWithOptions creates an Installer configured with opts, so no
+// setters need to be called after creation. The options are validated and an
+// error is returned if any are invalid.
+func NewInstallerWithOptions(ctx context.Context, onProgress func(InstallProgress), opts InstallOptions) (*Installer, error) {
+	i := NewInstaller(ctx, onProgress)
+
+	if opts.MaxDownloadSize < 0 {
+		return nil, fmt.Errorf("maximum download size must be positive, got %d", opts.MaxDownloadSize)
+	}
+	if opts.MaxDownloadSize > 0 {
+		i.maxDownloadSize = opts.MaxDownloadSize
+	}
+	if opts.MinTLSVersion != 0 {
+		if err := i.SetMinTLSVersion(opts.MinTLSVersion); err != nil {
+			return nil, err
+		}
+	}
+	if err := i.SetNodeMirror(opts.NodeMirror, opts.TrustMirror); err != nil {
+		return nil, err
+	}
+	if err := i.SetNodeFallbackMirrors(opts.NodeFallbackMirrors); err != nil {
+		return nil, err
+	}
+	if err := i.SetBackendOrder(opts.BackendOrder); err != nil {
+		return nil, err
+	}
+	if err := i.SetPackageManager(opts.PackageManager); err != nil {
+		return nil, err
+	}
+	if err := i.SetGitBitness(opts.GitBitness); err != nil {
+		return nil, err
+	}
+	for component, args := range opts.ExtraInstallerArgs {
+		if err := i.SetExtraInstallerArgs(component, args); err != nil {
+			return nil, err
+		}
+	}
+
+	i.downloadDir = opts.DownloadDir
+	i.downloadCacheDir = opts.DownloadCacheDir
+	i.processFile = opts.ProcessFile
+	i.portable = opts.PortableMode
+	i.verifyNodeSignature = opts.VerifyNodeSignature
+	i.checkServiceStatus = opts.CheckServiceStatus
+	i.preferIPv4 = opts.PreferIPv4
+	i.postInstallHook = opts.PostInstallHook
+	i.telemetryHook = opts.TelemetryHook
+	i.elevationHandler = opts.ElevationHandler
+	i.onDetail = opts.OnDetail
+	i.onErrorDetail = opts.OnErrorDetail
+	i.onMilestone = opts.OnMilestone
+	i.quiet = opts.Quiet
+	i.recorder = opts.ProgressRecorder
+	i.byteTracker = opts.ByteTracker
+	i.logger = opts.Logger
+	i.versionHistoryPath = opts.VersionHistoryPath
+	return i, nil
+}
+
+// SetDownloadDir configures a persistent directory where verified installers are
+// kept after installation instead of being deleted with the temp directory.
+// An empty dir disables retention.
+func (i *Installer) SetDownloadDir(dir string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.downloadDir = dir
+}
+
+// SetMaxDownloadSize configures the maximum size in bytes accepted for a
+// single download. The default is 500 MB.
+func (i *Installer) SetMaxDownloadSize(bytes int64) error {
+	if err := ValidateMaxDownloadSize(bytes); err != nil {
+		return err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.maxDownloadSize = bytes
+	return nil
+}
+
+// ValidateMaxDownloadSize checks a maximum download size; see
+// SetMaxDownloadSize.
+func ValidateMaxDownloadSize(bytes int64) error {
+	if bytes <= 0 {
+		return fmt.Errorf("maximum download size must be positive, got %d", bytes)
+	}
+	return nil
+}
+
+// SetMinTLSVersion configures the lowest TLS version, a crypto/tls VersionTLS
+// constant, accepted for downloads and API requests. The default is TLS 1.2.
+// Connections to a server that only offers older versions fail with
+// httputil.ErrTLSVersion.
+func (i *Installer) SetMinTLSVersion(v uint16) error {
+	if err := httputil.ValidateMinTLSVersion(v); err != nil {
+		return err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.minTLSVersion = v
+	return nil
+}
+
+// SetPreferIPv4 makes downloads and API requests connect over IPv4 only.
+// It works around networks where hosts resolve to IPv6 addresses but IPv6
+// traffic is dropped, which stalls connections until they time out however
+// often they are retried. The default uses both IPv4 and IPv6.
+func (i *Installer) SetPreferIPv4(prefer bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.preferIPv4 = prefer
+}
+
+// transport returns the HTTP transport honoring the configured minimum TLS
+// version and IPv4 preference.
+func (i *Installer) transport() http.RoundTripper {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return httputil.TransportFor(i.minTLSVersion, i.preferIPv4)
+}
+
+// SetPostInstallHook configures an additional check that runs after each
+// component's built-in verification succeeds, for organization-specific smoke
+// tests such as running `claude --help`. A non-nil error fails the component.
+func (i *Installer) SetPostInstallHook(hook func(component string) error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.postInstallHook = hook
+}
+
+// withPostInstallHook runs install and then the post-install hook, if any.
+func (i *Installer) withPostInstallHook(stepName string, install func() error) error {
+	if err := install(); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	hook := i.postInstallHook
+	i.mu.Unlock()
+	if hook == nil {
+		return nil
+	}
+
+	if err := hook(stepName); err != nil {
+		i.emitProgress(stepName, "error", fmt.Sprintf("Post-install check failed: %v", err), 0)
+		return fmt.Errorf("post-install check for %s failed: %w", stepName, err)
+	}
+	return nil
+}
+
+// SetElevationHandler configures the callback used to confirm elevation
+// prompts. A nil handler never elevates.
+func (i *Installer) SetElevationHandler(handler ElevationHandler) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.elevationHandler = handler
+}
+
+// requestElevation asks the elevation handler to confirm reason.
+func (i *Installer) requestElevation(reason string) bool {
+	i.mu.Lock()
+	handler := i.elevationHandler
+	i.mu.Unlock()
+	return handler != nil && handler(reason)
+}
+
+// RetainedDownloads returns the paths of installers retained in the download directory.
+func (i *Installer) RetainedDownloads() []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	result := make([]string, len(i.retained))
+	copy(result, i.retained)
+	return result
+}
+
+// retainDownload copies a verified installer into the download directory, if configured.
+// Failures are reported as warnings since retention is not required for installation.
+func (i *Installer) retainDownload(srcPath, stepName string) {
+	i.mu.Lock()
+	dir := i.downloadDir
+	i.mu.Unlock()
+	if dir == "" {
+		return
+	}
+
+	destPath := filepath.Join(dir, filepath.Base(srcPath))
+	if err := copyFile(srcPath, destPath); err != nil {
+		i.emitProgress(stepName, "installing",
+			fmt.Sprintf("Warning: could not keep downloaded installer: %v", err), 66)
+		return
+	}
+
+	i.mu.Lock()
+	i.retained = append(i.retained, destPath)
+	i.mu.Unlock()
+}
+
+// emitProgress sends a progress update via the callback, tagged with the
+// strategy currently in use for the step.
 func (i *Installer) emitProgress(step, status, message string, percentage float64) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
+	i.publishProgress(InstallProgress{
+		Step:       step,
+		Status:     status,
+		Message:    message,
+		Percentage: percentage,
+		Strategy:   i.strategies[step],
+	})
+}
+
+// publishProgress delivers a progress event to the callback and recorder.
+// The caller must hold i.mu.
+func (i *Installer) publishProgress(progress InstallProgress) {
+	_, started := i.lastPercentages[progress.Step]
+	i.clampProgress(&progress)
+	// Quiet mode thins out only what the UI sees; the log and the recording
+	// keep every update
+	suppressed := i.quiet && started && progress.Status == "installing" && progress.FallbackReason == ""
+	// Messages can embed error text quoting a proxy URL with its password
+	progress.Message = httputil.RedactCredentials(progress.Message)
+	if i.logger != nil {
+		level := logging.LevelInfo
+		if progress.Status == "error" {
+			level = logging.LevelError
+		} else if progress.FallbackReason != "" {
+			level = logging.LevelWarn
+		}
+		_ = i.logger.Log(level, progress.Step, fmt.Sprintf("%s: %s", progress.Status, progress.Message))
+	}
+	if i.recorder != nil {
+		// Best effort: recording is a debugging aid and must not fail installs
+		_ = i.recorder.Record(progress)
+	}
+	if suppressed {
+		return
+	}
 	if i.onProgress != nil {
-		i.onProgress(InstallProgress{
-			Step:       step,
-			Status:     status,
-			Message:    message,
-			Percentage: percentage,
-		})
+		i.onProgress(progress)
+	}
+	i.publishMilestones(progress)
+}
+
+// SetQuiet configures quiet mode for scripted and headless use. A quiet
+// installer emits only the event that starts each step, strategy fallbacks,
+// and the step's final "completed", "error" or "skipped" event; the
+// intermediate "installing" updates are withheld from the progress callback
+// and milestone handler but still logged and recorded.
+func (i *Installer) SetQuiet(quiet bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.quiet = quiet
+}
+
+// clampProgress keeps percentages within a step non-decreasing so the
+// progress bar never jumps backwards. A lower percentage is raised to the
+// step's previous one and reported as a detail so the emitting code path can
+// be fixed. Completion, errors and skips end the step, and a strategy
+// fallback restarts it, so those may report any percentage.
+// The caller must hold i.mu.
+func (i *Installer) clampProgress(progress *InstallProgress) {
+	switch {
+	case progress.Status == "completed" || progress.Status == "error" || progress.Status == "skipped":
+		delete(i.lastPercentages, progress.Step)
+		return
+	case progress.FallbackReason != "":
+	default:
+		if last, ok := i.lastPercentages[progress.Step]; ok && progress.Percentage < last {
+			i.publishDetail(progress.Step, fmt.Sprintf("warning: progress went backwards from %g%% to %g%% (%q); clamped to %g%%",
+				last, progress.Percentage, progress.Message, last))
+			progress.Percentage = last
+		}
+	}
+
+	if i.lastPercentages == nil {
+		i.lastPercentages = make(map[string]float64)
+	}
+	i.lastPercentages[progress.Step] = progress.Percentage
+}
+
+// progressFloor returns pct, raised to the percentage stepName last reported
+// so an operation that restarts within a step, such as a download retried
+// from another mirror, doesn't move the bar backwards.
+func (i *Installer) progressFloor(stepName string, pct float64) float64 {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return max(pct, i.lastPercentages[stepName])
+}
+
+// SetByteTracker reports downloaded bytes to tracker, keyed by step, so a run
+// over several installers can show one byte-based percentage.
+func (i *Installer) SetByteTracker(tracker *ByteTracker) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.byteTracker = tracker
+}
+
+// SetProgressRecorder tees every progress event to recorder for later replay
+// with ReplayProgress. A nil recorder stops recording.
+func (i *Installer) SetProgressRecorder(recorder *ProgressRecorder) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.recorder = recorder
+}
+
+// SetLogger persists every progress event and strategy decision to logger.
+// A nil logger disables logging.
+func (i *Installer) SetLogger(logger Logger) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.logger = logger
+}
+
+// SetDetailHandler configures a callback that receives each decision the
+// installer makes while choosing strategies, for diagnosing installs that
+// behave differently across machines.
+func (i *Installer) SetDetailHandler(onDetail func(InstallDetail)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.onDetail = onDetail
+}
+
+// emitDetail reports a strategy decision for a step via the detail callback.
+func (i *Installer) emitDetail(step, format string, args ...any) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.publishDetail(step, fmt.Sprintf(format, args...))
+}
+
+// publishDetail delivers a detail message to the logger and detail handler.
+// The caller must hold i.mu.
+func (i *Installer) publishDetail(step, message string) {
+	message = httputil.RedactCredentials(message)
+	if i.logger != nil {
+		_ = i.logger.Log(logging.LevelDebug, step, message)
+	}
+	if i.onDetail != nil {
+		i.onDetail(InstallDetail{Step: step, Message: message})
+	}
+}
+
+// yesNo formats a decision input for detail messages.
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// setStrategy records the strategy in use for a step; subsequent progress
+// events for the step carry it.
+func (i *Installer) setStrategy(step, strategy string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.strategies == nil {
+		i.strategies = make(map[string]string)
 	}
+	i.strategies[step] = strategy
+}
+
+// emitFallback switches a step to a new strategy and emits a fallback event
+// explaining why the previous strategy failed.
+func (i *Installer) emitFallback(step, from, to string, reason error, percentage float64) {
+	i.setStrategy(step, to)
+
+	summary := summarizeError(reason)
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.publishProgress(InstallProgress{
+		Step:           step,
+		Status:         "installing",
+		Message:        fmt.Sprintf("%s failed (%s), switching to %s...", from, summary, to),
+		Percentage:     percentage,
+		Strategy:       to,
+		FallbackReason: summary,
+	})
+}
+
+// summarizeError returns the first line of an error message, truncated for display.
+func summarizeError(err error) string {
+	if err == nil {
+		return "unknown error"
+	}
+	summary := strings.TrimSpace(err.Error())
+	if idx := strings.IndexAny(summary, "\r\n"); idx != -1 {
+		summary = strings.TrimSpace(summary[:idx])
+	}
+	if len(summary) > maxFallbackReasonLength {
+		summary = summary[:maxFallbackReasonLength] + "..."
+	}
+	return summary
+}
+
+// WaitForCommands blocks until all child processes started by the installer
+// have exited or ctx is done.
+func (i *Installer) WaitForCommands(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		i.commands.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for running commands: %w", ctx.Err())
+	}
+}
+
+// trackCommand registers a running child process and returns a func that
+// must be called once it exits.
+func (i *Installer) trackCommand() func() {
+	i.commands.Add(1)
+	return i.commands.Done
 }
 
 // runCommand executes a command and returns its output.
 func (i *Installer) runCommand(name string, args ...string) (string, error) {
+	if err := i.checkpoint(); err != nil {
+		return "", err
+	}
+	defer i.trackCommand()()
+
 	cmd := exec.CommandContext(i.ctx, name, args...)
 	hideConsoleWindow(cmd)
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return string(output), fmt.Errorf("command '%s %s' failed: %w\nOutput: %s",
-			name, strings.Join(args, " "), err, string(output))
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := i.runProcess(cmd); err != nil {
+		return output.String(), fmt.Errorf("command '%s %s' failed: %w\nOutput: %s",
+			name, strings.Join(args, " "), err, output.String())
 	}
 
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(output.String()), nil
 }
 
 // runCommandSilent executes a command without capturing output.
 func (i *Installer) runCommandSilent(name string, args ...string) error {
+	if err := i.checkpoint(); err != nil {
+		return err
+	}
+	defer i.trackCommand()()
+
 	cmd := exec.CommandContext(i.ctx, name, args...)
 	hideConsoleWindow(cmd)
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := i.runProcess(cmd); err != nil {
 		return fmt.Errorf("command '%s %s' failed: %w\nOutput: %s",
-			name, strings.Join(args, " "), err, string(output))
+			name, strings.Join(args, " "), err, output.String())
 	}
 	return nil
 }
 
+// runCommandStreaming executes a command, invoking onLine for each line of
+// combined stdout/stderr output as it is produced, and returns the full output.
+func (i *Installer) runCommandStreaming(onLine func(string), name string, args ...string) (string, error) {
+	if err := i.checkpoint(); err != nil {
+		return "", err
+	}
+	return i.streamCommand(i.ctx, onLine, nil, name, args...)
+}
+
+// streamCommand runs a command under ctx, invoking onLine for each output line
+// and onActivity whenever any output is written, including partial lines such
+// as carriage-return progress bars.
+func (i *Installer) streamCommand(ctx context.Context, onLine func(string), onActivity func(), name string, args ...string) (string, error) {
+	defer i.trackCommand()()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	hideConsoleWindow(cmd)
+
+	pr, pw := io.Pipe()
+	var out io.Writer = pw
+	if onActivity != nil {
+		out = activityWriter{w: pw, onWrite: onActivity}
+	}
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	var output strings.Builder
+	scanDone := make(chan struct{})
+	go func() {
+		defer close(scanDone)
+		scanner := bufio.NewScanner(pr)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxTextResponseSize)
+		for scanner.Scan() {
+			line := scanner.Text()
+			output.WriteString(line)
+			output.WriteString("\n")
+			if onLine != nil {
+				onLine(line)
+			}
+		}
+		// Drain any remaining output so the child never blocks on a full pipe
+		_, _ = io.Copy(io.Discard, pr)
+	}()
+
+	err := i.runProcess(cmd)
+	pw.Close()
+	<-scanDone
+
+	if err != nil {
+		return output.String(), fmt.Errorf("command '%s %s' failed: %w\nOutput: %s",
+			name, strings.Join(args, " "), err, output.String())
+	}
+	return strings.TrimSpace(output.String()), nil
+}
+
+// activityWriter calls onWrite before forwarding each write to w.
+type activityWriter struct {
+	w       io.Writer
+	onWrite func()
+}
+
+func (a activityWriter) Write(p []byte) (int, error) {
+	a.onWrite()
+	return a.w.Write(p)
+}
+
 // pollForCommand polls for a command to become available in PATH.
 func (i *Installer) pollForCommand(cmdName string, maxAttempts int) error {
 	for attempt := 0; attempt < maxAttempts; attempt++ {
@@ -111,17 +847,56 @@ func (i *Installer) pollForCommand(cmdName string, maxAttempts int) error {
 	return fmt.Errorf("%s not found in PATH after %d attempts", cmdName, maxAttempts)
 }
 
+// isCommandWorking reports whether a command resolves in PATH and actually runs.
+// A shim left behind by a half-removed install may still resolve via LookPath
+// but fail to execute; in that case the component should be reinstalled.
+func (i *Installer) isCommandWorking(name string) (found, working bool) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return false, false
+	}
+	if _, err := i.runCommand(path, "--version"); err != nil {
+		return true, false
+	}
+	return true, true
+}
+
+// refreshPath reloads the process PATH after an installation. A failure is
+// reported as a detail for stepName and marks PATH as stale, so verification
+// checks known install locations before trusting a PATH lookup.
+func (i *Installer) refreshPath(stepName string) {
+	err := pathutil.RefreshPath()
+	if err != nil {
+		i.emitDetail(stepName, "PATH refresh failed: %s; verifying via known install paths", summarizeError(err))
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.pathStale = err != nil
+}
+
 // verifyExecutable checks that an executable is accessible after installation.
+// When the process PATH may be stale, extraPaths are tried before name.
 func (i *Installer) verifyExecutable(name, stepName, versionFlag string, extraPaths []string) error {
 	paths := []string{name}
 	if runtime.GOOS == "windows" {
-		paths = append(paths, extraPaths...)
+		i.mu.Lock()
+		stale := i.pathStale
+		i.mu.Unlock()
+		if stale {
+			paths = append(append([]string{}, extraPaths...), name)
+		} else {
+			paths = append(paths, extraPaths...)
+		}
 	}
 
 	for _, execPath := range paths {
 		cmd := exec.CommandContext(i.ctx, execPath, versionFlag)
 		hideConsoleWindow(cmd)
-		if output, err := cmd.Output(); err == nil {
+		done := i.trackCommand()
+		output, err := cmd.Output()
+		done()
+		if err == nil {
 			version := strings.TrimSpace(string(output))
 			if version == "" {
 				continue // skip if version output is empty
@@ -135,148 +910,235 @@ func (i *Installer) verifyExecutable(name, stepName, versionFlag string, extraPa
 	return fmt.Errorf("%s command not found after installation (tried: %v)", name, paths)
 }
 
-// downloadFileWithRetry wraps downloadFile with exponential backoff retry logic.
-func (i *Installer) downloadFileWithRetry(url, destPath, stepName string) error {
-	maxRetries := defaultMaxRetries
-	var lastErr error
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		lastErr = i.downloadFile(url, destPath, stepName)
-		if lastErr == nil {
-			return nil
-		}
-		if attempt < maxRetries-1 {
-			backoff := time.Duration(1<<uint(attempt)) * time.Second
-			i.emitProgress(stepName, "installing",
-				fmt.Sprintf("Download failed, retrying in %v... (attempt %d/%d)", backoff, attempt+2, maxRetries), 0)
-			select {
-			case <-time.After(backoff):
-				// continue retry
-			case <-i.ctx.Done():
-				return fmt.Errorf("download cancelled: %w", i.ctx.Err())
-			}
-		}
+// RetryVerification re-runs only the post-install check for component
+// ("nodejs", "git" or "claudecode") after reloading PATH, without downloading
+// or installing anything. It confirms that a manual fix, such as adding a
+// directory to PATH, made a component that failed verification usable.
+func (i *Installer) RetryVerification(component string) error {
+	var label string
+	var verify func() error
+	switch component {
+	case ComponentNodeJS:
+		label, verify = "Node.js", i.verifyNode
+	case ComponentGit:
+		label, verify = "Git", i.verifyGit
+	case ComponentClaudeCode:
+		label, verify = "Claude Code", i.verifyClaudeCode
+	default:
+		return fmt.Errorf("unknown component %q (want one of %v)", component, DefaultComponentOrder)
 	}
-	return fmt.Errorf("download failed after %d attempts: %w", maxRetries, lastErr)
+
+	i.refreshPath(component)
+	i.emitProgress(component, "installing", fmt.Sprintf("Verifying %s installation...", label), 80)
+	if err := verify(); err != nil {
+		i.emitProgress(component, "error", fmt.Sprintf("%s still could not be verified", label), 0)
+		return fmt.Errorf("%s verification failed: %w", label, err)
+	}
+	i.emitProgress(component, "completed", fmt.Sprintf("%s verified successfully", label), 100)
+	return nil
 }
 
-// downloadFile downloads a file from the given URL to a local path with progress tracking.
-func (i *Installer) downloadFile(url, destPath, stepName string) error {
-	i.emitProgress(stepName, "installing", fmt.Sprintf("Downloading from %s...", url), 0)
+// Download progress is mapped into this band of a step's percentage, between
+// "Downloading ... installer" and checksum verification, so the rest of the
+// step can still advance the bar after the download completes.
+const (
+	downloadStartPct = 25
+	downloadEndPct   = 50
+)
 
-	req, err := http.NewRequestWithContext(i.ctx, "GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create download request: %w", err)
+// downloadFileWithRetry downloads a file with exponential backoff retry logic,
+// reporting progress and retries for the given step.
+func (i *Installer) downloadFileWithRetry(url, destPath, stepName string) error {
+	i.emitProgress(stepName, "installing", fmt.Sprintf("Downloading from %s...", url), i.progressFloor(stepName, downloadStartPct))
+
+	i.mu.Lock()
+	cacheDir := i.downloadCacheDir
+	i.mu.Unlock()
+
+	d := i.newDownloader(stepName)
+	// Reject a captive portal's HTML page before it is saved as an installer
+	d.ExpectedMagic = downloader.MagicForFilename(destPath)
+	var cachedPath string
+	if cacheDir != "" {
+		if entry, path, ok := loadCachedDownload(cacheDir, url); ok {
+			d.Conditional = entry.Validators
+			cachedPath = path
+		}
 	}
 
+	result, err := d.Download(i.ctx, url, destPath, "")
+	if errors.Is(err, downloader.ErrNotModified) {
+		i.emitDetail(stepName, "%s is unchanged since it was cached; reusing the cached download", url)
+		if err := reuseCachedDownload(cacheDir, url, cachedPath, destPath); err != nil {
+			return err
+		}
+		i.emitProgress(stepName, "installing", "Download unchanged; using cached copy", i.progressFloor(stepName, downloadEndPct))
+		return nil
+	}
+	if isNetworkFailure(err) {
+		// Retries were exhausted on a network failure; check whether the
+		// source is having an outage
+		return i.annotateOutage(stepName, serviceForURL(url), err)
+	}
+	if err == nil && cacheDir != "" {
+		if cacheErr := storeCachedDownload(cacheDir, url, destPath, result); cacheErr != nil {
+			i.emitDetail(stepName, "could not cache download: %v", cacheErr)
+		}
+	}
+	return err
+}
+
+// newDownloader creates a Downloader that reports progress for the given step,
+// scaled into [downloadStartPct, downloadEndPct]. A retry or a download that
+// restarts keeps the bar where it was rather than moving it backwards.
+func (i *Installer) newDownloader(stepName string) *downloader.Downloader {
 	client := &http.Client{
 		Timeout:       downloadTimeout,
-		CheckRedirect: httputil.NewTrustedCheckRedirect(httputil.AllTrustedHosts()),
+		CheckRedirect: httputil.NewTrustedCheckRedirect(i.trustedHosts()),
+		Transport:     i.transport(),
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
-	}
-	defer resp.Body.Close()
+	i.mu.Lock()
+	tracker := i.byteTracker
+	i.mu.Unlock()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download returned status %d", resp.StatusCode)
+	// lastRead converts the downloader's per-attempt byte count into deltas
+	// for the shared tracker; it drops when a retry restarts the download
+	var lastRead int64
+	reported := i.progressFloor(stepName, downloadStartPct)
+	d := downloader.New(client, func(bytesRead, totalSize int64) {
+		if tracker != nil {
+			tracker.Add(stepName, bytesRead-lastRead)
+			lastRead = bytesRead
+		}
+		if totalSize <= 0 {
+			return
+		}
+		filePct := float64(bytesRead) / float64(totalSize) * 100
+		reported = max(reported, downloadStartPct+(downloadEndPct-downloadStartPct)*filePct/100)
+		i.emitProgress(stepName, "installing",
+			fmt.Sprintf("Downloading... %.1f%%", filePct), reported)
+	})
+	i.mu.Lock()
+	d.MaxSize = i.maxDownloadSize
+	i.mu.Unlock()
+	d.MaxRetries = defaultMaxRetries
+	d.Checkpoint = i.checkpoint
+	d.OnRetry = func(nextAttempt, maxRetries int, backoff time.Duration, err error) {
+		i.emitProgress(stepName, "installing",
+			fmt.Sprintf("Download failed, retrying in %v... (attempt %d/%d)", backoff, nextAttempt, maxRetries), reported)
 	}
+	return d
+}
 
-	// Create destination file with restricted permissions (owner read/write only)
-	out, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+// logTail returns the last maxLines non-empty lines of a log file, or an
+// empty string if the log cannot be read.
+func logTail(path string, maxLines int) string {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", destPath, err)
+		return ""
 	}
 
-	// Track download progress
-	totalSize := resp.ContentLength
-	if totalSize > 0 {
-		if totalSize > maxDownloadSize {
-			return fmt.Errorf("file too large: %d bytes exceeds limit of %d", totalSize, maxDownloadSize)
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
 		}
-		reader := &progressReader{
-			reader:    resp.Body,
-			totalSize: totalSize,
-			onProgress: func(bytesRead int64) {
-				pct := float64(bytesRead) / float64(totalSize) * 100
-				i.emitProgress(stepName, "installing",
-					fmt.Sprintf("Downloading... %.1f%%", pct), pct)
-			},
-		}
-		_, err = io.Copy(out, io.LimitReader(reader, maxDownloadSize))
-	} else {
-		_, err = io.Copy(out, io.LimitReader(resp.Body, maxDownloadSize))
-	}
-
-	// Check copy error BEFORE close error to avoid treating a corrupted file as success
-	copyErr := err
-	closeErr := out.Close()
-	if copyErr != nil {
-		os.Remove(destPath) // clean up partial file
-		return fmt.Errorf("failed to write downloaded file: %w", copyErr)
 	}
-	if closeErr != nil {
-		os.Remove(destPath)
-		return fmt.Errorf("failed to finalize downloaded file: %w", closeErr)
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
 	}
-
-	return nil
+	return strings.Join(lines, "\n")
 }
 
-// getTempDir returns a unique temporary directory for downloads with restricted permissions.
+// getTempDir returns a unique temporary directory for downloads that only the
+// current user (and, on Windows, SYSTEM) can access, so other users of a
+// shared machine can't swap a verified installer before it runs.
 // Callers are responsible for cleaning up the returned directory with os.RemoveAll.
 func getTempDir() (string, error) {
 	tempDir, err := os.MkdirTemp("", "claude-code-installer-*")
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %w", err)
 	}
+	if err := restrictToOwner(tempDir); err != nil {
+		os.RemoveAll(tempDir)
+		return "", err
+	}
 	return tempDir, nil
 }
 
-// progressReader wraps an io.Reader to track read progress.
-type progressReader struct {
-	reader     io.Reader
-	totalSize  int64
-	bytesRead  int64
-	onProgress func(bytesRead int64)
-}
-
-func (pr *progressReader) Read(p []byte) (int, error) {
-	n, err := pr.reader.Read(p)
-	pr.bytesRead += int64(n)
-	if pr.onProgress != nil {
-		pr.onProgress(pr.bytesRead)
+// copyFile copies srcPath to destPath, creating the destination directory if needed.
+func copyFile(srcPath, destPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
 	}
-	return n, err
-}
 
-// verifyFileChecksum computes the SHA-256 hash of a file and compares it against an expected hash.
-func verifyFileChecksum(filePath, expectedHash string) error {
-	f, err := os.Open(filePath)
+	in, err := os.Open(srcPath)
 	if err != nil {
-		return fmt.Errorf("failed to open file for checksum verification: %w", err)
+		return fmt.Errorf("failed to open %s: %w", srcPath, err)
 	}
-	defer f.Close()
+	defer in.Close()
 
-	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
-		return fmt.Errorf("failed to compute checksum: %w", err)
+	out, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", destPath, err)
 	}
 
-	actualHash := hex.EncodeToString(h.Sum(nil))
-	if !strings.EqualFold(actualHash, expectedHash) {
-		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedHash, actualHash)
+	// Check copy error BEFORE close error to avoid treating a corrupted file as success
+	_, copyErr := io.Copy(out, in)
+	closeErr := out.Close()
+	if copyErr != nil {
+		os.Remove(destPath)
+		return fmt.Errorf("failed to copy file: %w", copyErr)
+	}
+	if closeErr != nil {
+		os.Remove(destPath)
+		return fmt.Errorf("failed to finalize file: %w", closeErr)
 	}
-
 	return nil
 }
 
+// verifyFileChecksum computes the SHA-256 hash of a file and compares it against an expected hash.
+func verifyFileChecksum(filePath, expectedHash string) error {
+	return downloader.VerifyFileChecksum(filePath, expectedHash)
+}
+
+// verifyFileChecksumWithProgress verifies a file's checksum, mapping hashing
+// progress into the [startPct, endPct] range of stepName's progress.
+func (i *Installer) verifyFileChecksumWithProgress(filePath, expectedHash, stepName string, startPct, endPct float64) error {
+	lastReported := -1
+	return downloader.VerifyFileChecksumWithProgress(filePath, expectedHash, func(bytesRead, totalSize int64) {
+		if totalSize <= 0 {
+			return
+		}
+		// Report whole-percent changes only so large files don't flood the UI
+		filePct := int(bytesRead * 100 / totalSize)
+		if filePct == lastReported {
+			return
+		}
+		lastReported = filePct
+		pct := startPct + (endPct-startPct)*float64(filePct)/100
+		i.emitProgress(stepName, "installing", fmt.Sprintf("Verifying download integrity... %d%%", filePct), pct)
+	})
+}
+
 // findChecksumInSHASUMS searches a SHASUMS256.txt formatted string for a specific filename
 // and returns its SHA-256 hash.
 func findChecksumInSHASUMS(shasumsContent, filename string) (string, error) {
-	for _, line := range strings.Split(shasumsContent, "\n") {
-		line = strings.TrimSpace(line)
+	return findChecksumInSHASUMSReader(context.Background(), strings.NewReader(shasumsContent), filename)
+}
+
+// findChecksumInSHASUMSReader is findChecksumInSHASUMS for a stream, scanned
+// line by line so large manifests are never held in memory. It stops with
+// ctx's error when ctx is cancelled.
+func findChecksumInSHASUMSReader(ctx context.Context, r io.Reader, filename string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTextResponseSize)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
 			continue
 		}
@@ -303,9 +1165,51 @@ func findChecksumInSHASUMS(shasumsContent, filename string) (string, error) {
 			}
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read checksums: %w", err)
+	}
 	return "", fmt.Errorf("checksum not found for %s", filename)
 }
 
+// trustedHosts returns the hosts downloads may be redirected to: the built-in
+// trusted hosts plus the hosts of any configured Node.js mirrors.
+func (i *Installer) trustedHosts() []string {
+	hosts := httputil.AllTrustedHosts()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, mirror := range append([]string{i.nodeMirror}, i.nodeFallbackMirrors...) {
+		if parsed, err := url.Parse(mirror); err == nil && parsed.Hostname() != "" {
+			hosts = append(hosts, parsed.Hostname())
+		}
+	}
+	return hosts
+}
+
+// fetchContentLength returns the size reported by a HEAD request for url,
+// or -1 when the server does not report one.
+func (i *Installer) fetchContentLength(url string) (int64, error) {
+	req, err := http.NewRequestWithContext(i.ctx, "HEAD", url, nil)
+	if err != nil {
+		return -1, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{
+		Timeout:       apiRequestTimeout,
+		CheckRedirect: httputil.NewTrustedCheckRedirect(i.trustedHosts()),
+		Transport:     i.transport(),
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return -1, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("HTTP %d fetching %s", resp.StatusCode, url)
+	}
+	return resp.ContentLength, nil
+}
+
 // fetchTextContent fetches text content from a URL with context support.
 func (i *Installer) fetchTextContent(url string) (string, error) {
 	req, err := http.NewRequestWithContext(i.ctx, "GET", url, nil)
@@ -315,7 +1219,8 @@ func (i *Installer) fetchTextContent(url string) (string, error) {
 
 	client := &http.Client{
 		Timeout:       apiRequestTimeout,
-		CheckRedirect: httputil.NewTrustedCheckRedirect(httputil.AllTrustedHosts()),
+		CheckRedirect: httputil.NewTrustedCheckRedirect(i.trustedHosts()),
+		Transport:     i.transport(),
 	}
 	resp, err := client.Do(req)
 	if err != nil {
@@ -336,8 +1241,57 @@ func (i *Installer) fetchTextContent(url string) (string, error) {
 	return string(body), nil
 }
 
-// isWingetAvailable checks if winget is available on the system.
+// isWingetAvailable checks if winget is available on the system and the
+// Windows build is new enough to support it. The result is cached with the
+// release metadata, so install plans and installs probe winget once.
 func isWingetAvailable() bool {
-	_, err := exec.LookPath("winget")
-	return err == nil
+	available, _ := cachedMetadata(releaseMetadata, "winget-available", func() (bool, error) {
+		if _, err := exec.LookPath("winget"); err != nil {
+			return false, nil
+		}
+		if major, build, err := detector.GetWindowsVersion(); err == nil {
+			return detector.WingetSupportedOnBuild(major, build), nil
+		}
+		return true, nil
+	})
+	return available
+}
+
+// unsupportedPlatformError reports that a component must be installed manually
+// on the current operating system.
+func (i *Installer) unsupportedPlatformError(stepName, component string) error {
+	i.emitProgress(stepName, "error",
+		fmt.Sprintf("Automatic %s installation is not supported on %s. Please install it manually.", component, runtime.GOOS), 0)
+	return fmt.Errorf("%s on %s: %w", component, runtime.GOOS, ErrUnsupportedPlatform)
+}
+
+// addToPath adds a per-machine install directory to the system PATH. When the
+// process is not elevated, the elevation handler is asked to confirm running
+// the update as administrator; otherwise the user PATH is used instead.
+func (i *Installer) addToPath(stepName, dir string) error {
+	err := pathutil.AddToSystemPath(dir)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pathutil.ErrRequiresElevation) &&
+		i.requestElevation(fmt.Sprintf("Add %s to the system PATH for all users", dir)) {
+		if err := elevation.Run(elevation.OpAddSystemPath, dir); err == nil {
+			i.refreshPath(stepName)
+			return nil
+		}
+	}
+	return pathutil.AddToPath(dir)
+}
+
+// pathWarning describes a non-fatal failure to add component to PATH. A PATH
+// too long to extend safely or restricted by policy is called out, since it
+// needs the user to act.
+func pathWarning(component string, err error) string {
+	if errors.Is(err, pathutil.ErrPathTooLong) {
+		return fmt.Sprintf("Warning: PATH is too long to add %s safely (%v). Shorten your PATH, then restart the installer.", component, err)
+	}
+	if errors.Is(err, pathutil.ErrUserPathReadOnly) {
+		return fmt.Sprintf("Warning: the user PATH cannot be modified, likely because of a policy restriction. Add %s to PATH manually.", component)
+	}
+	return fmt.Sprintf("Warning: could not add %s to PATH automatically", component)
 }
@@ -2,9 +2,67 @@
 
 package installer
 
-import "os/exec"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
 
 // hideConsoleWindow is a no-op on non-Windows platforms.
 func hideConsoleWindow(cmd *exec.Cmd) {
 	// No-op: console window hiding is only needed on Windows
 }
+
+// msiInstallInProgress always reports false; Windows Installer only exists on Windows.
+func msiInstallInProgress() bool {
+	return false
+}
+
+// checkWindowsInstaller always succeeds; MSI packages are only installed on Windows.
+func checkWindowsInstaller() error {
+	return nil
+}
+
+// processIdentity is not recorded on non-Windows platforms.
+func processIdentity(pid int) (string, time.Time, error) {
+	return "", time.Time{}, nil
+}
+
+// processRunning reports whether the recorded process is still running.
+// Unlike on Windows, its identity is not checked.
+func processRunning(p RunningProcess) bool {
+	err := syscall.Kill(p.PID, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// restrictToOwner makes dir accessible to the current user only.
+func restrictToOwner(dir string) error {
+	if err := os.Chmod(dir, 0700); err != nil {
+		return fmt.Errorf("failed to restrict permissions on %s: %w", dir, err)
+	}
+	return nil
+}
+
+// verifyFileOwner checks that path is owned by the current user.
+func verifyFileOwner(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read owner of %s: %w", path, err)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%w: %s is owned by uid %d", ErrDownloadOwnerChanged, path, stat.Uid)
+	}
+	return nil
+}
+
+// exeProductName is unsupported; version resources are a Windows PE feature.
+func exeProductName(path string) (string, error) {
+	return "", errors.New("reading executable version information is only supported on Windows")
+}
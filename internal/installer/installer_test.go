@@ -2,9 +2,26 @@ package installer
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
+
+	"claude-code-installer/internal/detector"
+	"claude-code-installer/internal/downloader"
+	"claude-code-installer/internal/elevation"
+	"claude-code-installer/internal/history"
+	"claude-code-installer/internal/httputil"
+	"claude-code-installer/internal/pathutil"
+	"claude-code-installer/internal/updater"
 )
 
 func TestFindChecksumInSHASUMS(t *testing.T) {
@@ -84,6 +101,27 @@ func TestFindChecksumInSHASUMS_StarPrefix(t *testing.T) {
 	}
 }
 
+func TestFindChecksumInSHASUMSReader(t *testing.T) {
+	const want = "aabbccdd11223344556677889900aabbccddeeff00112233445566778899aabb"
+	// Many times the fetchTextContent cap, streamed rather than buffered
+	var b strings.Builder
+	for n := 0; n < 40000; n++ {
+		fmt.Fprintf(&b, "%064x node-v22.%d.0-x64.msi\r\n", n, n)
+	}
+	fmt.Fprintf(&b, "%s  node-v22.13.1-x64.msi\n", want)
+
+	hash, err := findChecksumInSHASUMSReader(context.Background(), strings.NewReader(b.String()), "node-v22.13.1-x64.msi")
+	if err != nil || hash != want {
+		t.Fatalf("findChecksumInSHASUMSReader() = %q, %v, want %q", hash, err, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := findChecksumInSHASUMSReader(ctx, strings.NewReader(b.String()), "node-v22.13.1-x64.msi"); !errors.Is(err, context.Canceled) {
+		t.Errorf("findChecksumInSHASUMSReader() with cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
 func TestVerifyFileChecksum(t *testing.T) {
 	// Create a temp file with known content
 	tmpDir := t.TempDir()
@@ -214,3 +252,2001 @@ func TestPollForCommand_ContextTimeout(t *testing.T) {
 		t.Errorf("error message should indicate timeout/cancellation, got: %s", errMsg)
 	}
 }
+
+func TestNpmProgressTracker(t *testing.T) {
+	tracker := &npmProgressTracker{percentage: npmProgressStart}
+
+	lines := []string{
+		"npm http fetch GET 200 https://registry.npmjs.org/@anthropic-ai%2fclaude-code 120ms",
+		"npm http fetch GET 200 https://registry.npmjs.org/@anthropic-ai/claude-code/-/claude-code-1.0.0.tgz 300ms",
+		"npm timing reify:unpack Completed in 40ms",
+		"npm http fetch GET 200 https://registry.npmjs.org/late 10ms",
+		"added 3 packages in 2s",
+	}
+
+	var last float64
+	emitted := 0
+	for _, line := range lines {
+		pct, _, ok := tracker.update(line)
+		if !ok {
+			continue
+		}
+		emitted++
+		if pct <= last {
+			t.Errorf("progress went backwards: %.1f after %.1f", pct, last)
+		}
+		if pct < npmProgressStart || pct > npmProgressEnd {
+			t.Errorf("progress %.1f outside [%d, %d]", pct, npmProgressStart, npmProgressEnd)
+		}
+		last = pct
+	}
+
+	if emitted != 4 {
+		t.Errorf("got %d progress updates, want 4", emitted)
+	}
+	if _, _, ok := tracker.update("npm notice some unrelated line"); ok {
+		t.Error("unrelated line should not advance progress")
+	}
+}
+
+func TestSummarizeError(t *testing.T) {
+	if got := summarizeError(nil); got != "unknown error" {
+		t.Errorf("summarizeError(nil) = %q", got)
+	}
+
+	err := fmt.Errorf("command 'winget install Git.Git' failed: exit status 1\nOutput: lots of noise")
+	if got := summarizeError(err); got != "command 'winget install Git.Git' failed: exit status 1" {
+		t.Errorf("summarizeError did not keep only the first line: %q", got)
+	}
+
+	long := fmt.Errorf("%s", strings.Repeat("x", maxFallbackReasonLength+50))
+	if got := summarizeError(long); len(got) != maxFallbackReasonLength+3 {
+		t.Errorf("summarizeError did not truncate: got length %d", len(got))
+	}
+}
+
+func TestEmitFallback(t *testing.T) {
+	var events []InstallProgress
+	inst := NewInstaller(context.Background(), func(p InstallProgress) {
+		events = append(events, p)
+	})
+
+	inst.setStrategy("git", StrategyWinget)
+	inst.emitProgress("git", "installing", "Installing Git via winget...", 10)
+	inst.emitFallback("git", StrategyWinget, StrategyDownload, fmt.Errorf("exit status 1"), 20)
+	inst.emitProgress("git", "installing", "Downloading Git installer...", 25)
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	if events[0].Strategy != StrategyWinget {
+		t.Errorf("first event strategy = %q, want %q", events[0].Strategy, StrategyWinget)
+	}
+	if events[1].FallbackReason != "exit status 1" || events[1].Strategy != StrategyDownload {
+		t.Errorf("unexpected fallback event: %+v", events[1])
+	}
+	if events[2].Strategy != StrategyDownload || events[2].FallbackReason != "" {
+		t.Errorf("unexpected event after fallback: %+v", events[2])
+	}
+}
+
+func TestCrossCheckMirrorChecksum(t *testing.T) {
+	canonical := `aabbccdd11223344556677889900aabbccddeeff00112233445566778899aabb  node-v22.13.1-x64.msi`
+	stale := `ffeeddccbbaa998877665544332211ffeeddccbbaa99887766554433221100ab  node-v22.13.1-x64.msi`
+
+	hash, err := crossCheckMirrorChecksum(canonical, canonical, "node-v22.13.1-x64.msi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hash != "aabbccdd11223344556677889900aabbccddeeff00112233445566778899aabb" {
+		t.Errorf("unexpected hash: %s", hash)
+	}
+
+	if _, err := crossCheckMirrorChecksum(stale, canonical, "node-v22.13.1-x64.msi"); err == nil {
+		t.Error("expected error when mirror checksum differs from canonical")
+	}
+	if _, err := crossCheckMirrorChecksum("", canonical, "node-v22.13.1-x64.msi"); err == nil {
+		t.Error("expected error when mirror is missing the file")
+	}
+}
+
+func TestSetNodeMirror(t *testing.T) {
+	inst := NewInstaller(context.Background(), nil)
+
+	if err := inst.SetNodeMirror("http://mirror.example.com/node", false); err == nil {
+		t.Error("expected error for non-HTTPS mirror")
+	}
+
+	if err := inst.SetNodeMirror("https://mirror.example.com/node/", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := inst.nodeBaseURL(); got != "https://mirror.example.com/node" {
+		t.Errorf("nodeBaseURL() = %q", got)
+	}
+	if !inst.nodeMirrorNeedsCrossCheck() {
+		t.Error("untrusted mirror should require cross-check")
+	}
+
+	if err := inst.SetNodeMirror("https://mirror.example.com/node", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inst.nodeMirrorNeedsCrossCheck() {
+		t.Error("trusted mirror should not require cross-check")
+	}
+
+	if err := inst.SetNodeMirror("", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := inst.nodeBaseURL(); got != nodeDownloadBaseURL {
+		t.Errorf("nodeBaseURL() = %q, want default", got)
+	}
+}
+
+func TestGitVersionFromTag(t *testing.T) {
+	tests := map[string]string{
+		"v2.47.1.windows.1": "2.47.1",
+		"v2.47.1.windows.2": "2.47.1",
+		"v2.48.0":           "2.48.0",
+	}
+	for tag, expected := range tests {
+		if got := gitVersionFromTag(tag); got != expected {
+			t.Errorf("gitVersionFromTag(%q) = %q, want %q", tag, got, expected)
+		}
+	}
+}
+
+func TestPlanDownloadSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("got %s request, want HEAD", r.Method)
+		}
+		w.Header().Set("Content-Length", "1234")
+	}))
+	defer server.Close()
+
+	inst := NewInstaller(context.Background(), nil)
+	plan := ComponentPlan{URL: server.URL}
+	inst.planDownloadSize(&plan)
+	if plan.EstimatedSize != 1234 || plan.Error != "" {
+		t.Errorf("planDownloadSize = %d, %q; want 1234 with no error", plan.EstimatedSize, plan.Error)
+	}
+}
+
+func TestSetMaxDownloadSize(t *testing.T) {
+	inst := NewInstaller(context.Background(), nil)
+	if got := inst.newDownloader("test").MaxSize; got != maxDownloadSize {
+		t.Errorf("default MaxSize = %d, want %d", got, maxDownloadSize)
+	}
+
+	for _, size := range []int64{0, -1} {
+		if err := inst.SetMaxDownloadSize(size); err == nil {
+			t.Errorf("SetMaxDownloadSize(%d) should fail", size)
+		}
+	}
+
+	if err := inst.SetMaxDownloadSize(2 << 30); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := inst.newDownloader("test").MaxSize; got != 2<<30 {
+		t.Errorf("MaxSize = %d, want %d", got, int64(2<<30))
+	}
+}
+
+func TestWithPostInstallHook(t *testing.T) {
+	inst := NewInstaller(context.Background(), nil)
+	install := func() error { return nil }
+
+	if err := inst.withPostInstallHook("git", install); err != nil {
+		t.Fatalf("unexpected error without hook: %v", err)
+	}
+
+	var called []string
+	inst.SetPostInstallHook(func(component string) error {
+		called = append(called, component)
+		return fmt.Errorf("smoke test failed")
+	})
+
+	if err := inst.withPostInstallHook("git", install); err == nil {
+		t.Error("expected hook failure to fail the component")
+	}
+	if len(called) != 1 || called[0] != "git" {
+		t.Errorf("hook called with %v, want [git]", called)
+	}
+
+	called = nil
+	installErr := fmt.Errorf("install failed")
+	if err := inst.withPostInstallHook("git", func() error { return installErr }); err != installErr {
+		t.Errorf("got %v, want install error", err)
+	}
+	if len(called) != 0 {
+		t.Error("hook should not run when installation fails")
+	}
+}
+
+func TestLogTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "setup.log")
+	content := "line 1\r\nline 2\r\n\r\nline 3\r\nline 4\r\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	if got := logTail(path, 2); got != "line 3\nline 4" {
+		t.Errorf("logTail(2) = %q", got)
+	}
+	if got := logTail(path, 10); got != "line 1\nline 2\nline 3\nline 4" {
+		t.Errorf("logTail(10) = %q", got)
+	}
+	if got := logTail(filepath.Join(t.TempDir(), "missing.log"), 10); got != "" {
+		t.Errorf("logTail for missing file = %q, want empty", got)
+	}
+}
+
+func TestVerifyManifest(t *testing.T) {
+	dir := t.TempDir()
+	// SHA-256 of "hello world"
+	const helloHash = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	for name, content := range map[string]string{"good.msi": "hello world", "bad.zip": "tampered"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	manifest := strings.Join([]string{
+		helloHash + "  good.msi",
+		helloHash + "  bad.zip",
+		helloHash + "  missing.exe",
+		helloHash + "  ../outside.exe",
+	}, "\n")
+	manifestPath := filepath.Join(dir, "SHASUMS256.txt")
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0600); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	results, err := NewInstaller(context.Background(), nil).VerifyManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]bool{"good.msi": true, "bad.zip": false, "missing.exe": false, "../outside.exe": false}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for _, r := range results {
+		if r.Passed != want[r.File] {
+			t.Errorf("%s: passed = %v (%s), want %v", r.File, r.Passed, r.Error, want[r.File])
+		}
+	}
+}
+
+func TestSetNodeFallbackMirrors(t *testing.T) {
+	inst := NewInstaller(context.Background(), nil)
+
+	if err := inst.SetNodeFallbackMirrors([]string{"http://insecure.example.com"}); err == nil {
+		t.Error("expected error for non-HTTPS fallback mirror")
+	}
+
+	if err := inst.SetNodeFallbackMirrors([]string{"https://nodejs.org/dist", "https://mirror.example.com/node/", ""}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sources := inst.nodeDownloadSources()
+	want := []string{nodeDownloadBaseURL, "https://mirror.example.com/node"}
+	if len(sources) != len(want) {
+		t.Fatalf("nodeDownloadSources() = %v, want %v", sources, want)
+	}
+	for idx := range want {
+		if sources[idx] != want[idx] {
+			t.Errorf("nodeDownloadSources()[%d] = %q, want %q", idx, sources[idx], want[idx])
+		}
+	}
+
+	hosts := inst.trustedHosts()
+	if hosts[len(hosts)-1] != "mirror.example.com" {
+		t.Errorf("fallback mirror host not trusted: %v", hosts)
+	}
+}
+
+func TestNewInstallerWithOptions(t *testing.T) {
+	inst, err := NewInstallerWithOptions(context.Background(), nil, InstallOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error for default options: %v", err)
+	}
+	if inst.nodeBaseURL() != nodeDownloadBaseURL || inst.newDownloader("test").MaxSize != maxDownloadSize {
+		t.Error("zero options should use the defaults")
+	}
+
+	inst, err = NewInstallerWithOptions(context.Background(), nil, InstallOptions{
+		NodeMirror:      "https://mirror.example.com/node/",
+		MaxDownloadSize: 1024,
+		PortableMode:    true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inst.nodeBaseURL() != "https://mirror.example.com/node" || inst.newDownloader("test").MaxSize != 1024 || !inst.usePortableNode() {
+		t.Error("options were not applied")
+	}
+
+	invalid := []InstallOptions{
+		{MaxDownloadSize: -1},
+		{NodeMirror: "http://mirror.example.com"},
+		{NodeFallbackMirrors: []string{"ftp://mirror.example.com"}},
+	}
+	for _, opts := range invalid {
+		if _, err := NewInstallerWithOptions(context.Background(), nil, opts); err == nil {
+			t.Errorf("expected error for options %+v", opts)
+		}
+	}
+}
+
+func TestParseEngines(t *testing.T) {
+	engines, err := parseEngines(`{"node": ">=18.0.0", "npm": ">=9"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if engines["node"] != ">=18.0.0" || engines["npm"] != ">=9" {
+		t.Errorf("unexpected engines: %v", engines)
+	}
+
+	for _, output := range []string{"", "  ", "not json"} {
+		if _, err := parseEngines(output); err == nil {
+			t.Errorf("parseEngines(%q) should fail", output)
+		}
+	}
+}
+
+func TestEmitDetail(t *testing.T) {
+	inst := NewInstaller(context.Background(), nil)
+
+	// Without a handler, details are dropped
+	inst.emitDetail("git", "winget available: %s", yesNo(true))
+
+	var got []InstallDetail
+	inst.SetDetailHandler(func(d InstallDetail) { got = append(got, d) })
+	inst.emitDetail("git", "winget available: %s", yesNo(false))
+
+	want := InstallDetail{Step: "git", Message: "winget available: no"}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("details = %+v, want [%+v]", got, want)
+	}
+}
+
+func TestSetBackendOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   []string
+		wantErr bool
+	}{
+		{"empty restores default", nil, false},
+		{"scoop first", []string{StrategyScoop, StrategyWinget, StrategyDownload}, false},
+		{"download only", []string{StrategyDownload}, false},
+		{"unknown backend", []string{"chocolatey"}, true},
+		{"duplicate backend", []string{StrategyWinget, StrategyWinget}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst := NewInstaller(context.Background(), nil)
+			err := inst.SetBackendOrder(tt.order)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetBackendOrder(%v) error = %v, wantErr %v", tt.order, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			want := tt.order
+			if len(want) == 0 {
+				want = defaultBackendOrder
+			}
+			if got := inst.preferredBackends(); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("preferredBackends() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRunBackendsFallsBackInOrder(t *testing.T) {
+	inst := NewInstaller(context.Background(), nil)
+	if err := inst.SetBackendOrder([]string{StrategyDownload}); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	err := inst.runBackends("git", map[string]installBackend{
+		StrategyDownload: {StrategyDownload, func() error { calls++; return fmt.Errorf("boom") }},
+	})
+	if err == nil || err.Error() != "boom" || calls != 1 {
+		t.Errorf("runBackends() = %v after %d calls, want boom after 1", err, calls)
+	}
+
+	err = inst.runBackends("git", map[string]installBackend{})
+	if err != errNoBackendAvailable {
+		t.Errorf("runBackends() with no backends = %v, want %v", err, errNoBackendAvailable)
+	}
+}
+
+func TestProgressRecorderReplay(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "progress.jsonl")
+	f, err := os.Create(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inst := NewInstaller(context.Background(), nil)
+	inst.SetProgressRecorder(NewProgressRecorder(f))
+	inst.setStrategy("git", StrategyWinget)
+	inst.emitProgress("git", "installing", "Installing Git via winget...", 10)
+	inst.emitFallback("git", StrategyWinget, StrategyDownload, fmt.Errorf("exit status 1"), 20)
+	inst.emitProgress("git", "completed", "Git installed successfully", 100)
+	f.Close()
+
+	var replayed []InstallProgress
+	if err := ReplayProgress(logPath, func(p InstallProgress) { replayed = append(replayed, p) }); err != nil {
+		t.Fatalf("ReplayProgress: %v", err)
+	}
+
+	want := []InstallProgress{
+		{Step: "git", Status: "installing", Message: "Installing Git via winget...", Percentage: 10, Strategy: StrategyWinget},
+		{Step: "git", Status: "installing", Message: "winget failed (exit status 1), switching to download...",
+			Percentage: 20, Strategy: StrategyDownload, FallbackReason: "exit status 1"},
+		{Step: "git", Status: "completed", Message: "Git installed successfully", Percentage: 100, Strategy: StrategyDownload},
+	}
+	if fmt.Sprint(replayed) != fmt.Sprint(want) {
+		t.Errorf("replayed events = %+v, want %+v", replayed, want)
+	}
+}
+
+func TestReplayProgressInvalidLine(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "progress.jsonl")
+	if err := os.WriteFile(logPath, []byte("{not json}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReplayProgress(logPath, func(InstallProgress) {}); err == nil {
+		t.Error("expected error for invalid progress log")
+	}
+}
+
+func TestIsGitForWindowsProduct(t *testing.T) {
+	tests := []struct {
+		product string
+		want    bool
+	}{
+		{"Git", true},
+		{" git ", true},
+		{"Git for Windows", true},
+		{"MinGit", false},
+		{"GitHub Desktop", false},
+		{"Node.js", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isGitForWindowsProduct(tt.product); got != tt.want {
+			t.Errorf("isGitForWindowsProduct(%q) = %v, want %v", tt.product, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveClaudeShims(t *testing.T) {
+	binDir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(content), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An npm-generated shim for Claude Code is removed
+	write("claude", "#!/bin/sh\nexec node \"$basedir/../lib/node_modules/@anthropic-ai/claude-code/cli.js\" \"$@\"\n")
+	removed, err := removeClaudeShims(binDir)
+	if err != nil {
+		t.Fatalf("removeClaudeShims: %v", err)
+	}
+	if len(removed) != 1 {
+		t.Errorf("removed = %v, want the claude shim", removed)
+	}
+	if _, err := os.Stat(filepath.Join(binDir, "claude")); !os.IsNotExist(err) {
+		t.Error("stale shim still exists")
+	}
+
+	// An unrelated claude command is left alone
+	write("claude", "#!/bin/sh\necho some other tool\n")
+	removed, err = removeClaudeShims(binDir)
+	if err != nil || len(removed) != 0 {
+		t.Errorf("removeClaudeShims() = %v, %v; want nothing removed", removed, err)
+	}
+}
+
+func TestIsClaudeShimWindowsScript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claude.cmd")
+	content := "@IF EXIST \"%dp0%\\node.exe\" (\r\n  \"%dp0%\\node.exe\"  \"%dp0%\\node_modules\\@anthropic-ai\\claude-code\\cli.js\" %*\r\n)"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !isClaudeShim(path) {
+		t.Error("expected Windows cmd shim to be recognized")
+	}
+	if isClaudeShim(filepath.Join(t.TempDir(), "missing")) {
+		t.Error("missing file should not be a shim")
+	}
+}
+
+func TestByteTracker(t *testing.T) {
+	var updates [][2]int64
+	tracker := NewByteTracker(map[string]int64{"nodejs": 100, "git": 300}, func(downloaded, total int64) {
+		updates = append(updates, [2]int64{downloaded, total})
+	})
+
+	tracker.Add("nodejs", 50)
+	if d, total := tracker.Totals(); d != 50 || total != 400 {
+		t.Errorf("Totals() = %d/%d, want 50/400", d, total)
+	}
+
+	// A restarted download gives back the discarded bytes
+	tracker.Add("nodejs", -50)
+	tracker.Add("nodejs", 100)
+
+	// Downloading more than estimated raises the total instead of passing 100%
+	tracker.Add("git", 400)
+	if d, total := tracker.Totals(); d != 500 || total != 500 {
+		t.Errorf("Totals() = %d/%d, want 500/500", d, total)
+	}
+	if got := BytePercentage(tracker.Totals()); got != 100 {
+		t.Errorf("BytePercentage = %v, want 100", got)
+	}
+	if len(updates) != 4 {
+		t.Errorf("got %d updates, want 4", len(updates))
+	}
+}
+
+func TestByteTrackerFinish(t *testing.T) {
+	tracker := NewByteTracker(map[string]int64{"nodejs": 100, "git": 300}, nil)
+
+	// A component installed without downloading counts as complete
+	tracker.Finish("git")
+	if got := BytePercentage(tracker.Totals()); got != 75 {
+		t.Errorf("BytePercentage after Finish = %v, want 75", got)
+	}
+	if got := BytePercentage(0, 0); got != 0 {
+		t.Errorf("BytePercentage(0, 0) = %v, want 0", got)
+	}
+}
+
+func TestRecommendUpdateMethod(t *testing.T) {
+	tests := []struct {
+		updateType string
+		npmManaged bool
+		want       string
+	}{
+		{updater.UpdatePatch, true, UpdateMethodNPM},
+		{updater.UpdateMinor, true, UpdateMethodNPM},
+		{updater.UpdateMajor, true, UpdateMethodReinstall},
+		{updater.UpdateMajor, false, UpdateMethodNative},
+		{updater.UpdatePatch, false, UpdateMethodNative},
+	}
+
+	for _, tt := range tests {
+		if got := recommendUpdateMethod(tt.updateType, tt.npmManaged); got != tt.want {
+			t.Errorf("recommendUpdateMethod(%q, %v) = %q, want %q", tt.updateType, tt.npmManaged, got, tt.want)
+		}
+	}
+
+	if got := claudeVersionNumber("1.0.17 (Claude Code)\n"); got != "1.0.17" {
+		t.Errorf("claudeVersionNumber = %q, want 1.0.17", got)
+	}
+}
+
+func TestResetConfigDir(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) string {
+		t.Helper()
+		home := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(home, ".claude", "projects"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(home, ".claude", "settings.json"), []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return home
+	}
+
+	t.Run("delete", func(t *testing.T) {
+		home := setup(t)
+		backupPath, err := resetConfigDir(home, false, now)
+		if err != nil || backupPath != "" {
+			t.Fatalf("resetConfigDir() = %q, %v", backupPath, err)
+		}
+		if _, err := os.Stat(filepath.Join(home, ".claude")); !os.IsNotExist(err) {
+			t.Error(".claude still exists")
+		}
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		home := setup(t)
+		backupPath, err := resetConfigDir(home, true, now)
+		if err != nil {
+			t.Fatalf("resetConfigDir() error: %v", err)
+		}
+		want := filepath.Join(home, ".claude.backup-20260301-123000")
+		if backupPath != want {
+			t.Errorf("backup path = %q, want %q", backupPath, want)
+		}
+		if _, err := os.Stat(filepath.Join(want, "settings.json")); err != nil {
+			t.Errorf("backup is missing settings.json: %v", err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		if _, err := resetConfigDir(t.TempDir(), false, now); err != nil {
+			t.Errorf("resetConfigDir() on missing dir: %v", err)
+		}
+	})
+
+	t.Run("symlink is not followed", func(t *testing.T) {
+		home := t.TempDir()
+		target := t.TempDir()
+		keep := filepath.Join(target, "keep.txt")
+		if err := os.WriteFile(keep, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, filepath.Join(home, ".claude")); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+		if _, err := resetConfigDir(home, false, now); err != nil {
+			t.Fatalf("resetConfigDir() error: %v", err)
+		}
+		if _, err := os.Stat(keep); err != nil {
+			t.Errorf("symlink target contents were removed: %v", err)
+		}
+	})
+
+	t.Run("relative home", func(t *testing.T) {
+		if _, err := resetConfigDir("relative", false, now); err == nil {
+			t.Error("expected error for relative home")
+		}
+	})
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCode  ErrorCode
+		retryable bool
+	}{
+		{"canceled", fmt.Errorf("download failed: %w", context.Canceled), ErrorCodeCanceled, true},
+		{"checksum", fmt.Errorf("verify: %w", downloader.ErrChecksumMismatch), ErrorCodeChecksumMismatch, true},
+		{"owner changed", fmt.Errorf("run: %w", ErrDownloadOwnerChanged), ErrorCodeChecksumMismatch, true},
+		{"previous install", fmt.Errorf("%w: msiexec.exe (PID 42)", ErrPreviousInstallRunning), ErrorCodePreviousInstall, true},
+		{"elevation declined", fmt.Errorf("msi: %w", elevation.ErrDeclined), ErrorCodeElevationDeclined, false},
+		{"claude running", ErrClaudeRunning, ErrorCodeClaudeRunning, false},
+		{"git networking", fmt.Errorf("git: %w", ErrGitNetworking), ErrorCodeGitNetworking, false},
+		{"windows installer", fmt.Errorf("%w: the Windows Installer service is disabled", ErrWindowsInstallerUnavailable), ErrorCodeWindowsInstaller, false},
+		{"claude shadowed", fmt.Errorf("verify: %w", claudeShadowedError(detector.ClaudeShadowing{Path: "/usr/bin/claude"})), ErrorCodeClaudeShadowed, false},
+		{"interrupted", fmt.Errorf("%w: rolled back", ErrInstallerInterrupted), ErrorCodeInterrupted, false},
+		{"proxy auth", fmt.Errorf("fetch: %w", httputil.ErrProxyAuth), ErrorCodeProxyAuth, false},
+		{"service outage", fmt.Errorf("%w: GitHub is down: %w", ErrServiceOutage, &net.OpError{Op: "dial", Err: errors.New("refused")}), ErrorCodeServiceOutage, true},
+		{"network", fmt.Errorf("fetch: %w", &net.OpError{Op: "dial", Err: errors.New("refused")}), ErrorCodeNetwork, true},
+		{"captive portal", fmt.Errorf("download: %w", downloader.ErrUnexpectedContent), ErrorCodeNetwork, true},
+		{"unknown", errors.New("something else"), ErrorCodeUnknown, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ie := ClassifyError("nodejs", tt.err)
+			if ie.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", ie.Code, tt.wantCode)
+			}
+			if ie.Retryable() != tt.retryable {
+				t.Errorf("Retryable() = %v, want %v", ie.Retryable(), tt.retryable)
+			}
+			if ie.Step != "nodejs" || ie.Remediation() == "" || !errors.Is(ie, tt.err) {
+				t.Errorf("unexpected InstallError %+v", ie)
+			}
+		})
+	}
+
+	t.Run("existing InstallError is kept", func(t *testing.T) {
+		inner := &InstallError{Step: "git", Code: ErrorCodeNoBackend, Err: errNoBackendAvailable}
+		ie := ClassifyError("installAll", fmt.Errorf("wrapped: %w", inner))
+		if ie != inner {
+			t.Errorf("ClassifyError() = %+v, want the wrapped InstallError", ie)
+		}
+	})
+
+	if ClassifyError("git", nil) != nil {
+		t.Error("ClassifyError(nil) should be nil")
+	}
+}
+
+func TestSetMinTLSVersion(t *testing.T) {
+	inst := NewInstaller(context.Background(), nil)
+	if err := inst.SetMinTLSVersion(tls.VersionTLS13); err != nil {
+		t.Fatalf("SetMinTLSVersion(TLS 1.3) error: %v", err)
+	}
+	if inst.transport() != httputil.TransportWithMinTLS(tls.VersionTLS13) {
+		t.Error("transport does not honor the minimum TLS version")
+	}
+	if err := inst.SetMinTLSVersion(0x0200); err == nil {
+		t.Error("expected error for SSL 2.0 version constant")
+	}
+
+	if _, err := NewInstallerWithOptions(context.Background(), nil, InstallOptions{MinTLSVersion: 1}); err == nil {
+		t.Error("expected NewInstallerWithOptions to reject an invalid TLS version")
+	}
+}
+
+func TestSetPreferIPv4(t *testing.T) {
+	inst, err := NewInstallerWithOptions(context.Background(), nil, InstallOptions{PreferIPv4: true})
+	if err != nil {
+		t.Fatalf("NewInstallerWithOptions() error: %v", err)
+	}
+	if inst.transport() != httputil.TransportFor(0, true) {
+		t.Error("transport does not honor PreferIPv4")
+	}
+	inst.SetPreferIPv4(false)
+	if inst.transport() != httputil.DefaultTransport() {
+		t.Error("transport should be dual-stack by default")
+	}
+}
+
+func TestParseAuditSignatures(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		wantStatus string
+		wantErr    bool
+	}{
+		{
+			name:       "verified",
+			output:     `{"invalid":[],"missing":[]}`,
+			wantStatus: SignatureVerified,
+		},
+		{
+			name:       "invalid with warnings around the report",
+			output:     "npm WARN config something\n" + `{"invalid":[{"name":"@anthropic-ai/claude-code","version":"1.0.0"}],"missing":[]}` + "\n",
+			wantStatus: SignatureInvalid,
+		},
+		{
+			name:       "missing",
+			output:     `{"invalid":[],"missing":[{"name":"@anthropic-ai/claude-code","version":"1.0.0"}]}`,
+			wantStatus: SignatureMissing,
+		},
+		{
+			name:       "other package invalid",
+			output:     `{"invalid":[{"name":"left-pad","version":"1.0.0"}],"missing":[]}`,
+			wantStatus: SignatureVerified,
+		},
+		{
+			name:    "regular audit report from old npm",
+			output:  `{"auditReportVersion":2,"vulnerabilities":{}}`,
+			wantErr: true,
+		},
+		{
+			name:    "no JSON",
+			output:  "Unknown command: signatures",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := parseAuditSignatures(tt.output)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAuditSignatures() error: %v", err)
+			}
+			if got := signatureStatus(report, claudeCodePackage); got != tt.wantStatus {
+				t.Errorf("signatureStatus() = %q, want %q", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestParseHiddenLockfileIntegrity(t *testing.T) {
+	lockfile := `{"name":"lib","lockfileVersion":3,"packages":{` +
+		`"node_modules/@anthropic-ai/claude-code":{"version":"1.0.0","integrity":"sha512-abc"},` +
+		`"node_modules/left-pad":{"version":"1.3.0","integrity":"sha512-def"}}}`
+
+	tests := []struct {
+		name    string
+		data    string
+		version string
+		want    string
+		wantErr bool
+	}{
+		{name: "installed version", data: lockfile, version: "1.0.0", want: "sha512-abc"},
+		{name: "different version recorded", data: lockfile, version: "1.0.1", wantErr: true},
+		{name: "package not recorded", data: `{"packages":{}}`, version: "1.0.0", wantErr: true},
+		{name: "not JSON", data: "garbage", version: "1.0.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHiddenLockfileIntegrity([]byte(tt.data), claudeCodePackage, tt.version)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHiddenLockfileIntegrity() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseHiddenLockfileIntegrity() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// recordingLogger collects logged lines for assertions.
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Log(level, step, message string) error {
+	l.lines = append(l.lines, fmt.Sprintf("%s [%s] %s", level, step, message))
+	return nil
+}
+
+func TestSetLogger(t *testing.T) {
+	logger := &recordingLogger{}
+	inst := NewInstaller(context.Background(), nil)
+	inst.SetLogger(logger)
+
+	inst.emitProgress("git", "installing", "Downloading...", 10)
+	inst.emitDetail("git", "winget available: %s", yesNo(false))
+	inst.emitProgress("git", "error", "boom", 0)
+
+	want := []string{
+		"info [git] installing: Downloading...",
+		"debug [git] winget available: no",
+		"error [git] error: boom",
+	}
+	if fmt.Sprint(logger.lines) != fmt.Sprint(want) {
+		t.Errorf("logged %q, want %q", logger.lines, want)
+	}
+}
+
+func TestPathWarning(t *testing.T) {
+	tooLong := fmt.Errorf("%w: adding C:\\Git\\cmd would make PATH 2100 characters", pathutil.ErrPathTooLong)
+	if msg := pathWarning("Git", tooLong); !strings.Contains(msg, "too long") || !strings.Contains(msg, "Shorten your PATH, then restart") {
+		t.Errorf("pathWarning() = %q, want it to mention the length limit and how to fix it", msg)
+	}
+	readOnly := fmt.Errorf("%w: Access is denied.", pathutil.ErrUserPathReadOnly)
+	if msg := pathWarning("Git", readOnly); !strings.Contains(msg, "Add Git to PATH manually") {
+		t.Errorf("pathWarning() = %q, want it to ask for a manual PATH change", msg)
+	}
+	if msg := pathWarning("Git", fmt.Errorf("access denied")); msg != "Warning: could not add Git to PATH automatically" {
+		t.Errorf("pathWarning() = %q", msg)
+	}
+}
+
+func TestIsWingetNoPackageFound(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"No package found matching input criteria.", true},
+		{"   - \r\nNO PACKAGE FOUND MATCHING INPUT CRITERIA.\r\n", true},
+		{"Successfully installed", false},
+		{"Installer hash does not match.", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isWingetNoPackageFound(tt.output); got != tt.want {
+			t.Errorf("isWingetNoPackageFound(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestValidateComponentOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []string
+		wantErr    bool
+	}{
+		{"default", DefaultComponentOrder, false},
+		{"git first", []string{"git", "nodejs", "claudecode"}, false},
+		{"skip git", []string{"nodejs", "claudecode"}, false},
+		{"claude code alone", []string{"claudecode"}, false},
+		{"claude code before node", []string{"claudecode", "nodejs"}, true},
+		{"unknown", []string{"nodejs", "python"}, true},
+		{"duplicate", []string{"git", "git"}, true},
+		{"empty", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateComponentOrder(tt.components)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateComponentOrder(%v) error = %v, wantErr %v", tt.components, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInstallPlanForComponents(t *testing.T) {
+	plan := &InstallPlan{Components: []ComponentPlan{
+		{Component: "nodejs", EstimatedSize: 30},
+		{Component: "git", EstimatedSize: 60},
+		{Component: "claudecode", Installed: true},
+	}}
+
+	got := plan.ForComponents([]string{"git", "claudecode"})
+	if len(got.Components) != 2 || got.Components[0].Component != "git" {
+		t.Fatalf("unexpected components: %+v", got.Components)
+	}
+	if got.TotalDownloadSize != 60 || got.EstimatedSteps != 1 {
+		t.Errorf("totals = %d bytes, %d steps; want 60 bytes, 1 step", got.TotalDownloadSize, got.EstimatedSteps)
+	}
+}
+
+func TestSelectGitAsset(t *testing.T) {
+	const base = "https://github.com/git-for-windows/git/releases/download/v2.47.1.windows.1/"
+	asset := func(name, contentType string) gitReleaseAsset {
+		return gitReleaseAsset{Name: name, ContentType: contentType, BrowserDownloadURL: base + name}
+	}
+
+	tests := []struct {
+		name   string
+		assets []gitReleaseAsset
+		arch   string
+		want   string
+	}{
+		{
+			name: "content type preferred over name",
+			assets: []gitReleaseAsset{
+				asset("Git-64-bit-setup.exe", "application/octet-stream"),
+				asset("Git-2.47.1-64-bit.exe", "application/x-msdownload"),
+			},
+			arch: "64-bit",
+			want: "Git-2.47.1-64-bit.exe",
+		},
+		{
+			name: "portable and mingit excluded despite content type",
+			assets: []gitReleaseAsset{
+				asset("PortableGit-2.47.1-64-bit.7z.exe", "application/x-msdownload"),
+				asset("MinGit-2.47.1-64-bit.exe", "application/x-msdownload"),
+				asset("Git-2.47.1-64-bit.exe", "application/octet-stream"),
+			},
+			arch: "64-bit",
+			want: "Git-2.47.1-64-bit.exe",
+		},
+		{
+			name: "arch match preferred over content type",
+			assets: []gitReleaseAsset{
+				asset("Git-2.47.1-arm64.exe", "application/x-msdownload"),
+				asset("Git-2.47.1-64-bit.exe", "application/octet-stream"),
+			},
+			arch: "64-bit",
+			want: "Git-2.47.1-64-bit.exe",
+		},
+		{
+			name: "falls back to any architecture",
+			assets: []gitReleaseAsset{
+				asset("Git-2.47.1-arm64.exe", "application/x-msdownload"),
+			},
+			arch: "32-bit",
+			want: "Git-2.47.1-arm64.exe",
+		},
+		{
+			name: "untrusted host rejected",
+			assets: []gitReleaseAsset{
+				{Name: "Git-2.47.1-64-bit.exe", ContentType: "application/x-msdownload", BrowserDownloadURL: "https://example.com/Git-2.47.1-64-bit.exe"},
+			},
+			arch: "64-bit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := selectGitAsset(tt.assets, tt.arch)
+			if ok != (tt.want != "") || got.Name != tt.want {
+				t.Errorf("selectGitAsset() = %q, %v, want %q", got.Name, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoalesceProgress(t *testing.T) {
+	var pending []InstallProgress
+	pending = coalesceProgress(pending, InstallProgress{Step: "nodejs", Status: "installing", Percentage: 10})
+	pending = coalesceProgress(pending, InstallProgress{Step: "nodejs", Status: "installing", Percentage: 20})
+	pending = coalesceProgress(pending, InstallProgress{Step: "nodejs", Status: "completed", Percentage: 100})
+	pending = coalesceProgress(pending, InstallProgress{Step: "git", Status: "installing", Percentage: 5})
+	pending = coalesceProgress(pending, InstallProgress{Step: "nodejs", Status: "installing", Percentage: 30})
+
+	want := []InstallProgress{
+		{Step: "nodejs", Status: "installing", Percentage: 20},
+		{Step: "nodejs", Status: "completed", Percentage: 100},
+		{Step: "git", Status: "installing", Percentage: 5},
+		{Step: "nodejs", Status: "installing", Percentage: 30},
+	}
+	if len(pending) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(pending), len(want), pending)
+	}
+	for idx := range want {
+		if pending[idx] != want[idx] {
+			t.Errorf("event %d = %+v, want %+v", idx, pending[idx], want[idx])
+		}
+	}
+}
+
+func TestNewInstallerWithChannel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inst, progress := NewInstallerWithChannel(ctx)
+
+	// Nobody reads while events are produced; emitting must not block
+	const events = 10 * progressChannelBuffer
+	emitted := make(chan struct{})
+	go func() {
+		for n := 0; n < events; n++ {
+			inst.emitProgress("nodejs", "installing", "Downloading...", float64(n)/events*100)
+		}
+		inst.emitProgress("nodejs", "completed", "Node.js installed", 100)
+		close(emitted)
+	}()
+	select {
+	case <-emitted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("emitting progress blocked on an unread channel")
+	}
+
+	var received []InstallProgress
+	for len(received) == 0 || received[len(received)-1].Status != "completed" {
+		select {
+		case p := <-progress:
+			received = append(received, p)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("completed event not delivered; got %d events", len(received))
+		}
+	}
+	if len(received) > events {
+		t.Errorf("received %d events, want intermediate events coalesced", len(received))
+	}
+
+	cancel()
+	select {
+	case _, ok := <-progress:
+		if ok {
+			t.Error("unexpected event after cancellation")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel not closed after cancellation")
+	}
+}
+
+func TestProgressPercentagesMonotonic(t *testing.T) {
+	var got []float64
+	var details []string
+	inst := NewInstaller(context.Background(), func(p InstallProgress) {
+		got = append(got, p.Percentage)
+	})
+	inst.SetDetailHandler(func(d InstallDetail) {
+		details = append(details, d.Message)
+	})
+
+	inst.emitProgress("nodejs", "installing", "Downloading...", 10)
+	inst.emitProgress("nodejs", "installing", "Verifying...", 95)
+	inst.emitProgress("nodejs", "installing", "Extracting...", 80)
+	inst.emitProgress("git", "installing", "Starting...", 5)
+	inst.emitFallback("nodejs", StrategyWinget, StrategyZip, errors.New("winget failed"), 20)
+	inst.emitProgress("nodejs", "installing", "Downloading...", 30)
+	inst.emitProgress("nodejs", "error", "Failed", 0)
+	inst.emitProgress("nodejs", "installing", "Retrying...", 10)
+	inst.emitProgress("nodejs", "completed", "Done", 100)
+	inst.emitProgress("nodejs", "installing", "Reinstalling...", 5)
+
+	want := []float64{10, 95, 95, 5, 20, 30, 0, 10, 100, 5}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("percentages = %v, want %v", got, want)
+	}
+	if len(details) != 1 || !strings.Contains(details[0], "backwards") {
+		t.Errorf("details = %q, want one warning about the decrease", details)
+	}
+}
+
+func TestDownloadProgressStaysInBand(t *testing.T) {
+	payload := append([]byte("MZ"), make([]byte, 256<<10)...)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(payload)))
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	var events []InstallProgress
+	var details []string
+	inst := NewInstaller(context.Background(), func(p InstallProgress) {
+		events = append(events, p)
+	})
+	inst.SetDetailHandler(func(d InstallDetail) {
+		details = append(details, d.Message)
+	})
+
+	inst.emitProgress("git", "installing", "Downloading Git installer...", 25)
+	if err := inst.downloadFileWithRetry(server.URL, filepath.Join(t.TempDir(), "installer.exe"), "git"); err != nil {
+		t.Fatalf("downloadFileWithRetry() error: %v", err)
+	}
+	downloaded := len(events)
+	inst.emitProgress("git", "installing", "Verifying download integrity...", 55)
+	inst.emitProgress("git", "installing", "Running Git installer...", 70)
+	inst.emitProgress("git", "completed", "Git installed successfully", 100)
+
+	for _, e := range events[1:downloaded] {
+		if e.Percentage < downloadStartPct || e.Percentage > downloadEndPct {
+			t.Errorf("download event %q at %g%%, want within [%d, %d]", e.Message, e.Percentage, downloadStartPct, downloadEndPct)
+		}
+	}
+	want := []float64{55, 70, 100}
+	for idx, e := range events[downloaded:] {
+		if e.Percentage != want[idx] {
+			t.Errorf("event %q at %g%%, want %g%%", e.Message, e.Percentage, want[idx])
+		}
+	}
+	if len(details) != 0 {
+		t.Errorf("details = %q, want no backwards-progress warnings", details)
+	}
+}
+
+func TestIsUnderAnyDir(t *testing.T) {
+	dirs := []string{`C:\Program Files`, `C:\Program Files (x86)\`}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{`C:\Program Files\nodejs\node.exe`, true},
+		{`c:\program files\Git\cmd\git.exe`, true},
+		{`C:\Program Files (x86)\nodejs\node.exe`, true},
+		{`C:/Program Files/Git/cmd/git.exe`, true},
+		{`C:\Program FilesX\node.exe`, false},
+		{`C:\Program Files`, false},
+		{`C:\Users\me\AppData\Local\Programs\nodejs\node.exe`, false},
+	}
+	for _, tt := range tests {
+		if got := isUnderAnyDir(tt.path, dirs); got != tt.want {
+			t.Errorf("isUnderAnyDir(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSetExtraInstallerArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		component string
+		args      []string
+		want      []string
+		wantErr   bool
+	}{
+		{"node defaults", ComponentNodeJS, nil, defaultNodeMSIArgs, false},
+		{"node feature subset", ComponentNodeJS, []string{"ADDLOCAL=NodeRuntime,npm"}, []string{"ADDLOCAL=ALL", "ADDLOCAL=NodeRuntime,npm"}, false},
+		{"node msiexec option", ComponentNodeJS, []string{"/norestart"}, nil, true},
+		{"node lowercase property", ComponentNodeJS, []string{"addlocal=ALL"}, nil, true},
+		{"git components", ComponentGit, []string{"/COMPONENTS=icons,gitlfs"}, append(append([]string(nil), defaultGitInstallerArgs...), "/COMPONENTS=icons,gitlfs"), false},
+		{"git without slash", ComponentGit, []string{"COMPONENTS=icons"}, nil, true},
+		{"git log override", ComponentGit, []string{"/log=C:\\temp\\git.log"}, nil, true},
+		{"shell metacharacter", ComponentGit, []string{"/DIR=C:\\Git & calc"}, nil, true},
+		{"quote", ComponentNodeJS, []string{`INSTALLDIR="C:\Node"`}, nil, true},
+		{"control character", ComponentGit, []string{"/SUPPRESSMSGBOXES\n"}, nil, true},
+		{"empty argument", ComponentGit, []string{""}, nil, true},
+		{"unsupported component", ComponentClaudeCode, []string{"--force"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst := NewInstaller(context.Background(), nil)
+			err := inst.SetExtraInstallerArgs(tt.component, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetExtraInstallerArgs(%q, %q) error = %v, wantErr %v", tt.component, tt.args, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defaults := defaultNodeMSIArgs
+			if tt.component == ComponentGit {
+				defaults = defaultGitInstallerArgs
+			}
+			if got := inst.installerArgs(tt.component, defaults); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("installerArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("empty clears", func(t *testing.T) {
+		inst := NewInstaller(context.Background(), nil)
+		if err := inst.SetExtraInstallerArgs(ComponentGit, []string{"/SUPPRESSMSGBOXES"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := inst.SetExtraInstallerArgs(ComponentGit, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := inst.installerArgs(ComponentGit, defaultGitInstallerArgs); fmt.Sprint(got) != fmt.Sprint(defaultGitInstallerArgs) {
+			t.Errorf("installerArgs() = %q, want defaults", got)
+		}
+	})
+}
+
+func TestServiceForURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/git-for-windows/git/releases/download/v2.47.1.windows.1/Git-2.47.1-64-bit.exe", serviceGitHub},
+		{"https://objects.githubusercontent.com/github-production-release-asset/1", serviceGitHub},
+		{"https://registry.npmjs.org/@anthropic-ai%2fclaude-code", serviceNpm},
+		{"https://nodejs.org/dist/v22.13.1/node-v22.13.1-x64.msi", ""},
+		{"https://github.com.example.com/file", ""},
+		{"://bad", ""},
+	}
+
+	for _, tt := range tests {
+		if got := serviceForURL(tt.url); got != tt.want {
+			t.Errorf("serviceForURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestOutageCheckOnlyForNetworkFailures(t *testing.T) {
+	if !isNetworkFailure(fmt.Errorf("fetch: %w", &net.OpError{Op: "dial", Err: errors.New("refused")})) {
+		t.Error("dial failure should be a network failure")
+	}
+	if isNetworkFailure(fmt.Errorf("fetch: %w", httputil.ErrProxyAuth)) {
+		t.Error("proxy authentication failure should not be a network failure")
+	}
+
+	if !isNpmNetworkError("npm error code ETIMEDOUT\nnpm error network request failed") {
+		t.Error("npm ETIMEDOUT should be a network error")
+	}
+	if isNpmNetworkError("npm ERR! code EACCES\nnpm ERR! ECONNRESET mentioned in passing") {
+		t.Error("npm EACCES should not be a network error")
+	}
+	if !isNpmNetworkError("ERR_PNPM_META_FETCH_FAIL GET https://registry.npmjs.org/: getaddrinfo ENOTFOUND") {
+		t.Error("pnpm ENOTFOUND should be a network error")
+	}
+}
+
+func TestFetchServiceStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErr    bool
+		wantOutage bool
+	}{
+		{"operational", http.StatusOK, `{"status":{"indicator":"none","description":"All Systems Operational"}}`, false, false},
+		{"minor incident", http.StatusOK, `{"status":{"indicator":"minor","description":"Partially Degraded Service"}}`, false, false},
+		{"major outage", http.StatusOK, `{"status":{"indicator":"major","description":"Partial System Outage"}}`, false, true},
+		{"critical outage", http.StatusOK, `{"status":{"indicator":"critical","description":"Major Service Outage"}}`, false, true},
+		{"missing indicator", http.StatusOK, `{"page":{}}`, true, false},
+		{"invalid json", http.StatusOK, `<html>`, true, false},
+		{"server error", http.StatusServiceUnavailable, ``, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+
+			status, err := fetchServiceStatus(context.Background(), srv.Client(), serviceGitHub, srv.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchServiceStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if status.Service != serviceGitHub || status.Outage() != tt.wantOutage {
+				t.Errorf("fetchServiceStatus() = %+v, want outage %v", status, tt.wantOutage)
+			}
+		})
+	}
+}
+
+func TestCachedMetadata(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newMetadataCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	fetches := 0
+	fetch := func() (string, error) {
+		fetches++
+		return fmt.Sprintf("v%d", fetches), nil
+	}
+	get := func() string {
+		t.Helper()
+		value, err := cachedMetadata(cache, "key", fetch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	if got := get(); got != "v1" {
+		t.Errorf("first lookup = %q, want v1", got)
+	}
+	now = now.Add(30 * time.Second)
+	if got := get(); got != "v1" {
+		t.Errorf("lookup within TTL = %q, want cached v1", got)
+	}
+	now = now.Add(time.Minute)
+	if got := get(); got != "v2" {
+		t.Errorf("lookup after TTL = %q, want refetched v2", got)
+	}
+	cache.clear()
+	if got := get(); got != "v3" {
+		t.Errorf("lookup after clear = %q, want refetched v3", got)
+	}
+
+	failing := func() (string, error) { return "", errors.New("offline") }
+	if _, err := cachedMetadata(cache, "other", failing); err == nil {
+		t.Fatal("expected fetch error")
+	}
+	if value, err := cachedMetadata(cache, "other", fetch); err != nil || value != "v4" {
+		t.Errorf("lookup after failure = %q, %v, want v4 (errors are not cached)", value, err)
+	}
+}
+
+func TestWithTelemetry(t *testing.T) {
+	inst := NewInstaller(context.Background(), nil)
+
+	// Off by default: steps run normally with nothing to report to
+	if err := inst.withTelemetry("git", func() error { return nil }); err != nil {
+		t.Fatalf("unexpected error without hook: %v", err)
+	}
+
+	var events []TelemetryEvent
+	inst.SetTelemetryHook(func(event TelemetryEvent) {
+		events = append(events, event)
+	})
+
+	err := inst.withTelemetry("git", func() error {
+		inst.setStrategy("git", StrategyWinget)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stepErr := fmt.Errorf(`C:\Users\alice\AppData: %w`, ErrClaudeRunning)
+	if err := inst.withTelemetry("claudecode", func() error { return stepErr }); err != stepErr {
+		t.Errorf("withTelemetry() error = %v, want the step's error", err)
+	}
+
+	want := []TelemetryEvent{
+		{Component: "git", Strategy: StrategyWinget, Success: true},
+		{Component: "claudecode", ErrorCode: ErrorCodeClaudeRunning},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for n, event := range events {
+		event.Duration = 0
+		if event != want[n] {
+			t.Errorf("event %d = %+v, want %+v", n, event, want[n])
+		}
+	}
+
+	inst.SetTelemetryHook(nil)
+	_ = inst.withTelemetry("git", func() error { return nil })
+	if len(events) != len(want) {
+		t.Error("events reported after the hook was removed")
+	}
+}
+
+func TestGitNetworkUnreachable(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   bool
+	}{
+		{"offline", "fatal: unable to access 'https://github.com/git/git.git/': Could not resolve host: github.com", true},
+		{"proxy auth", "fatal: unable to access 'https://github.com/git/git.git/': Received HTTP code 407 from proxy after CONNECT", true},
+		{"timeout", "fatal: unable to access 'https://github.com/git/git.git/': Failed to connect to github.com port 443 after 21038 ms: Timed out", true},
+		{"missing https helper", "git: 'remote-https' is not a git command. See 'git --help'.", false},
+		{"broken schannel", "fatal: unable to access 'https://github.com/git/git.git/': schannel: AcquireCredentialsHandle failed: SEC_E_NO_CREDENTIALS", false},
+		{"missing dll", "C:/Program Files/Git/mingw64/libexec/git-core/git-remote-https.exe: error while loading shared libraries: libcurl-4.dll: cannot open shared object file", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gitNetworkUnreachable(tt.output); got != tt.want {
+				t.Errorf("gitNetworkUnreachable(%q) = %v, want %v", tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetPackageManager(t *testing.T) {
+	tests := []struct {
+		pm      string
+		want    string
+		wantErr bool
+	}{
+		{"", StrategyNPM, false},
+		{StrategyNPM, StrategyNPM, false},
+		{StrategyPNPM, StrategyPNPM, false},
+		{StrategyYarn, StrategyYarn, false},
+		{"bun", "", true},
+		{"NPM", "", true},
+	}
+
+	for _, tt := range tests {
+		inst := NewInstaller(context.Background(), nil)
+		err := inst.SetPackageManager(tt.pm)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetPackageManager(%q) error = %v, wantErr %v", tt.pm, err, tt.wantErr)
+			continue
+		}
+		if err == nil && inst.claudePackageManager() != tt.want {
+			t.Errorf("SetPackageManager(%q): claudePackageManager() = %q, want %q", tt.pm, inst.claudePackageManager(), tt.want)
+		}
+	}
+}
+
+func TestGlobalPackageArgs(t *testing.T) {
+	tests := []struct {
+		pm         string
+		wantAdd    string
+		wantRemove string
+	}{
+		{StrategyNPM, "install -g pkg@latest", "uninstall -g pkg"},
+		{StrategyPNPM, "add -g pkg@latest", "remove -g pkg"},
+		{StrategyYarn, "global add pkg@latest", "global remove pkg"},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(globalAddArgs(tt.pm, "pkg@latest"), " "); got != tt.wantAdd {
+			t.Errorf("globalAddArgs(%q) = %q, want %q", tt.pm, got, tt.wantAdd)
+		}
+		if got := strings.Join(globalRemoveArgs(tt.pm, "pkg"), " "); got != tt.wantRemove {
+			t.Errorf("globalRemoveArgs(%q) = %q, want %q", tt.pm, got, tt.wantRemove)
+		}
+	}
+}
+
+func TestDiagnoseNpmError(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"npm 10 code line", "npm error code EACCES\nnpm error syscall mkdir", "(EACCES)"},
+		{"npm 6 code line", "npm ERR! code EBADENGINE\nnpm ERR! engine Unsupported engine", "(EBADENGINE)"},
+		{"code with placeholder", "npm error code EBUSY", "locked (EBUSY)"},
+		{"pnpm without code line", "ERR_PNPM_META_FETCH_FAIL GET https://registry.npmjs.org/: getaddrinfo ENOTFOUND", "(ENOTFOUND)"},
+		{"unknown code", "npm error code EWHATEVER", "error code EWHATEVER"},
+		{"no code", "something went wrong", "see the full output"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diagnoseNpmError(tt.output); !strings.Contains(got, tt.want) {
+				t.Errorf("diagnoseNpmError() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNpmDebugLog(t *testing.T) {
+	output := "npm error code EACCES\nnpm error A complete log of this run can be found in: /home/u/.npm/_logs/2024-01-01T00_00_00_000Z-debug-0.log\n"
+	if got, want := npmDebugLog(output), "/home/u/.npm/_logs/2024-01-01T00_00_00_000Z-debug-0.log"; got != want {
+		t.Errorf("npmDebugLog() = %q, want %q", got, want)
+	}
+	// npm 6 prints the path on the following line
+	output = "npm ERR! A complete log of this run can be found in:\nnpm ERR!     C:\\Users\\u\\AppData\\Roaming\\npm-cache\\_logs\\debug.log\n"
+	if got, want := npmDebugLog(output), `C:\Users\u\AppData\Roaming\npm-cache\_logs\debug.log`; got != want {
+		t.Errorf("npmDebugLog() = %q, want %q", got, want)
+	}
+	if got := npmDebugLog("npm error code E404"); got != "" {
+		t.Errorf("npmDebugLog() = %q, want empty", got)
+	}
+}
+
+func TestDownloadFileWithRetry_Cache(t *testing.T) {
+	var fullDownloads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fullDownloads++
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, "MZ installer")
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	for attempt := 0; attempt < 2; attempt++ {
+		var last float64
+		inst := NewInstaller(context.Background(), func(p InstallProgress) { last = p.Percentage })
+		inst.SetDownloadCacheDir(cacheDir)
+		dest := filepath.Join(t.TempDir(), "installer.exe")
+		if err := inst.downloadFileWithRetry(server.URL, dest, "git"); err != nil {
+			t.Fatalf("attempt %d: unexpected error: %v", attempt, err)
+		}
+		if data, _ := os.ReadFile(dest); string(data) != "MZ installer" {
+			t.Errorf("attempt %d: downloaded %q, want %q", attempt, data, "MZ installer")
+		}
+		// A cache hit ends the download band, leaving the rest of the step room
+		if attempt == 1 && last != downloadEndPct {
+			t.Errorf("cache hit reported %.1f%%, want %d%%", last, downloadEndPct)
+		}
+	}
+	if fullDownloads != 1 {
+		t.Errorf("server sent the file %d times, want 1", fullDownloads)
+	}
+
+	// A cached file that no longer matches its checksum is not reused
+	filePath, _ := downloadCachePaths(cacheDir, server.URL)
+	if err := os.WriteFile(filePath, []byte("tampered"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := loadCachedDownload(cacheDir, server.URL); ok {
+		t.Error("loadCachedDownload() reused a corrupted file")
+	}
+}
+
+func TestClaudeBinConflictWarning(t *testing.T) {
+	shadow := detector.ClaudeShadowing{
+		Packages: []string{claudeCodePackage, "claude"},
+		Winner:   "claude",
+	}
+	msg := claudeBinConflictWarning(shadow)
+	for _, want := range []string{"claude currently runs", "npm uninstall -g claude"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("claudeBinConflictWarning() = %q, want it to contain %q", msg, want)
+		}
+	}
+	if strings.Contains(msg, "uninstall -g "+claudeCodePackage) {
+		t.Errorf("claudeBinConflictWarning() = %q, suggests uninstalling Claude Code", msg)
+	}
+}
+
+func TestControlStateCheckpoint(t *testing.T) {
+	t.Run("pause blocks until resume", func(t *testing.T) {
+		inst := NewInstaller(context.Background(), nil)
+		inst.Pause()
+
+		done := make(chan error, 1)
+		go func() { done <- inst.checkpoint() }()
+		select {
+		case err := <-done:
+			t.Fatalf("checkpoint returned %v while paused", err)
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		inst.Resume()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("checkpoint after resume = %v, want nil", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("checkpoint still blocked after resume")
+		}
+	})
+
+	t.Run("skip and cancel end a pause", func(t *testing.T) {
+		for _, tt := range []struct {
+			stop func(*Installer)
+			want error
+		}{
+			{(*Installer).SkipStep, ErrStepSkipped},
+			{(*Installer).Cancel, context.Canceled},
+		} {
+			inst := NewInstaller(context.Background(), nil)
+			inst.Pause()
+			done := make(chan error, 1)
+			go func() { done <- inst.checkpoint() }()
+			tt.stop(inst)
+			select {
+			case err := <-done:
+				if !errors.Is(err, tt.want) {
+					t.Errorf("checkpoint = %v, want %v", err, tt.want)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("checkpoint still blocked")
+			}
+		}
+	})
+
+	t.Run("context wins", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		inst := NewInstaller(ctx, nil)
+		inst.Pause()
+		inst.SkipStep()
+		cancel()
+		if err := inst.checkpoint(); !errors.Is(err, context.Canceled) {
+			t.Errorf("checkpoint = %v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestDownloadFileWithRetry_SkipStep(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "installer")
+	}))
+	defer server.Close()
+
+	inst := NewInstaller(context.Background(), nil)
+	inst.SkipStep()
+	err := inst.downloadFileWithRetry(server.URL, filepath.Join(t.TempDir(), "installer.exe"), "git")
+	if !errors.Is(err, ErrStepSkipped) {
+		t.Errorf("downloadFileWithRetry() = %v, want ErrStepSkipped", err)
+	}
+	if _, err := inst.runCommand("go", "version"); !errors.Is(err, ErrStepSkipped) {
+		t.Errorf("runCommand() = %v, want ErrStepSkipped", err)
+	}
+}
+
+func TestGetTempDirRestricted(t *testing.T) {
+	dir, err := getTempDir()
+	if err != nil {
+		t.Fatalf("getTempDir() error = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0700 {
+			t.Errorf("temp dir permissions = %o, want 700", perm)
+		}
+	}
+
+	file := filepath.Join(dir, "installer.msi")
+	if err := os.WriteFile(file, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyFileOwner(file); err != nil {
+		t.Errorf("verifyFileOwner() error = %v, want nil for a file we created", err)
+	}
+	if err := verifyFileOwner(filepath.Join(dir, "missing")); err == nil {
+		t.Error("verifyFileOwner() = nil for a missing file")
+	}
+}
+
+func TestRetryVerification(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake node")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "node"), []byte("#!/bin/sh\necho v20.11.0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	var statuses []string
+	inst := NewInstaller(context.Background(), func(p InstallProgress) {
+		if p.Step == ComponentNodeJS {
+			statuses = append(statuses, p.Status)
+		}
+	})
+	if err := inst.RetryVerification(ComponentNodeJS); err != nil {
+		t.Fatalf("RetryVerification(nodejs) error = %v", err)
+	}
+	if len(statuses) == 0 || statuses[len(statuses)-1] != "completed" {
+		t.Errorf("statuses = %v, want to end with completed", statuses)
+	}
+
+	if err := inst.RetryVerification(ComponentGit); err == nil {
+		t.Error("RetryVerification(git) = nil with no git on PATH")
+	}
+	if err := inst.RetryVerification("python"); err == nil {
+		t.Error("RetryVerification(python) = nil for an unknown component")
+	}
+}
+
+func TestSetGitBitness(t *testing.T) {
+	inst := NewInstaller(context.Background(), nil)
+	for _, bits := range []int{0, 32, 64} {
+		if err := inst.SetGitBitness(bits); err != nil {
+			t.Errorf("SetGitBitness(%d) error = %v", bits, err)
+		}
+	}
+	if err := inst.SetGitBitness(16); err == nil {
+		t.Error("SetGitBitness(16) = nil, want error")
+	}
+
+	if got := gitInstallerArch(32); got != "32-bit" {
+		t.Errorf("gitInstallerArch(32) = %q, want 32-bit", got)
+	}
+	if got := gitInstallerArch(64); got != "64-bit" {
+		t.Errorf("gitInstallerArch(64) = %q, want 64-bit", got)
+	}
+	want := "64-bit"
+	if runtime.GOARCH == "386" {
+		want = "32-bit"
+	}
+	if got := gitInstallerArch(0); got != want {
+		t.Errorf("gitInstallerArch(0) = %q, want %q", got, want)
+	}
+}
+
+func TestGitCmdDir(t *testing.T) {
+	env64 := map[string]string{
+		"ProgramFiles":      `C:\Program Files`,
+		"ProgramW6432":      `C:\Program Files`,
+		"ProgramFiles(x86)": `C:\Program Files (x86)`,
+	}
+	env32 := map[string]string{"ProgramFiles": `C:\Program Files`}
+	tests := []struct {
+		arch string
+		env  map[string]string
+		want string
+	}{
+		{"64-bit", env64, `C:\Program Files\Git\cmd`},
+		// SetGitBitness(32) on 64-bit Windows
+		{"32-bit", env64, `C:\Program Files (x86)\Git\cmd`},
+		{"32-bit", env32, `C:\Program Files\Git\cmd`},
+		{"64-bit", map[string]string{}, defaultGitPath},
+	}
+	for _, tt := range tests {
+		getenv := func(key string) string { return tt.env[key] }
+		if got := gitCmdDir(tt.arch, getenv); got != tt.want {
+			t.Errorf("gitCmdDir(%q) = %q, want %q", tt.arch, got, tt.want)
+		}
+	}
+}
+
+func TestPreviousSessionProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "processes.json")
+	now := time.Now()
+	entries := []RunningProcess{
+		{PID: os.Getpid(), Name: "npm", SessionPID: os.Getpid(), Started: now},
+		{PID: os.Getpid(), Name: filepath.Base(os.Args[0]), SessionPID: -1, Started: now},
+		{PID: 1 << 30, Name: "msiexec.exe", SessionPID: -1, Started: now},
+		{PID: os.Getpid(), Name: filepath.Base(os.Args[0]), SessionPID: -1, Started: now.Add(-maxRecordedProcessAge - time.Hour)},
+	}
+	if err := writeProcessFile(path, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	running, err := PreviousSessionProcesses(path)
+	if err != nil {
+		t.Fatalf("PreviousSessionProcesses() error = %v", err)
+	}
+	if len(running) != 1 || running[0].SessionPID != -1 || running[0].PID != os.Getpid() {
+		t.Errorf("running = %+v, want only the live process from another session", running)
+	}
+
+	kept, err := readProcessFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 2 {
+		t.Errorf("process file keeps %d entries, want 2 (this session's and the live one)", len(kept))
+	}
+
+	if running, err := PreviousSessionProcesses(filepath.Join(t.TempDir(), "missing.json")); err != nil || len(running) != 0 {
+		t.Errorf("PreviousSessionProcesses(missing) = %v, %v, want none", running, err)
+	}
+}
+
+func TestRunCommandRecordsProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh to read the process file while it runs")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "processes.json")
+	inst := NewInstaller(context.Background(), nil)
+	inst.SetProcessFile(path)
+
+	// Queries are not recorded
+	if _, err := inst.runCommand("sh", "-c", `[ ! -f "$0" ]`, path); err != nil {
+		t.Errorf("process file written for a query: %v", err)
+	}
+
+	// The entry is written just after the process starts, so wait for it
+	npm := filepath.Join(dir, "npm")
+	script := "#!/bin/sh\nfor i in $(seq 100); do [ -f \"$2\" ] && exec cat \"$2\"; sleep 0.05; done\n"
+	if err := os.WriteFile(npm, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	output, err := inst.runCommand(npm, "install", path)
+	if err != nil {
+		t.Fatalf("runCommand() error = %v", err)
+	}
+	if !strings.Contains(output, `"name":"npm"`) {
+		t.Errorf("process file while running = %s, want an entry for npm", output)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("process file still exists after the command exited: %v", err)
+	}
+
+	if err := writeProcessFile(path, []RunningProcess{{PID: os.Getpid(), Name: "npm", SessionPID: -1, Started: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inst.ensureNoPreviousInstall("nodejs"); !errors.Is(err, ErrPreviousInstallRunning) {
+		t.Errorf("ensureNoPreviousInstall() = %v, want ErrPreviousInstallRunning", err)
+	}
+}
+
+func TestRecordedProcess(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{`C:\Windows\System32\msiexec.exe`, []string{"/i", "node.msi"}, true},
+		{`C:\Users\u\AppData\Local\Temp\claude-git-1\Git-installer.exe`, []string{"/VERYSILENT"}, true},
+		{`C:\Program Files\nodejs\npm.cmd`, []string{"install", "-g", "@anthropic-ai/claude-code"}, true},
+		{"/usr/bin/npm", []string{"root", "-g"}, false},
+		{"pnpm", []string{"add", "-g", "@anthropic-ai/claude-code"}, true},
+		{"yarn", []string{"global", "add", "@anthropic-ai/claude-code"}, true},
+		{"yarn", []string{"global", "bin"}, false},
+		{"git", []string{"--version"}, false},
+	}
+	for _, tt := range tests {
+		if got := recordedProcess(tt.name, tt.args); got != tt.want {
+			t.Errorf("recordedProcess(%q, %q) = %v, want %v", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestMilestones(t *testing.T) {
+	var got []string
+	inst := NewInstaller(context.Background(), nil)
+	inst.SetMilestoneHandler(func(m Milestone) {
+		got = append(got, fmt.Sprintf("%s %d %s", m.Phase, m.Percentage, m.Message))
+	})
+
+	inst.emitProgress("nodejs", "installing", "Checking for existing Node.js installation...", 0)
+	inst.emitProgress("nodejs", "installing", "Downloading from https://nodejs.org/dist/node.msi...", 10)
+	for pct := 11.0; pct < 50; pct += 3 {
+		inst.emitProgress("nodejs", "installing", "Downloading...", pct)
+	}
+	inst.emitProgress("nodejs", "installing", "Verifying download integrity...", 55)
+	inst.emitProgress("nodejs", "installing", "Running Node.js installer...", 70)
+	inst.emitProgress("nodejs", "installing", "Verified v20.11.0", 95)
+	inst.emitProgress("nodejs", "completed", "Node.js installed successfully", 100)
+
+	want := []string{
+		" 0 Node.js: 0 percent complete",
+		"downloading 0 Node.js: download started",
+		"downloading 25 Node.js: 25 percent complete",
+		"verifying 50 Node.js: verifying",
+		"verifying 50 Node.js: 50 percent complete",
+		"installing 50 Node.js: installing",
+		"verifying 75 Node.js: verifying",
+		"verifying 75 Node.js: 75 percent complete",
+		"done 100 Node.js: done",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("milestones:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	got = nil
+	inst.emitProgress("git", "installing", "Downloading Git...", 30)
+	inst.emitProgress("git", "error", "Download failed", 0)
+	if last := got[len(got)-1]; last != "failed 25 Git: failed" {
+		t.Errorf("last milestone = %q, want failed at 25%%", last)
+	}
+}
+
+func TestParseNpmViewDist(t *testing.T) {
+	output := `{"version":"1.2.3","dist":{"tarball":"https://registry.npmjs.org/@anthropic-ai/claude-code/-/claude-code-1.2.3.tgz","integrity":"sha512-abc"}}`
+	info, err := parseNpmViewDist(output)
+	if err != nil {
+		t.Fatalf("parseNpmViewDist() error: %v", err)
+	}
+	if info.Version != "1.2.3" || info.Dist.Integrity != "sha512-abc" || !strings.HasSuffix(info.Dist.Tarball, "claude-code-1.2.3.tgz") {
+		t.Errorf("parseNpmViewDist() = %+v", info)
+	}
+
+	for _, output := range []string{"", "not json", `{"dist":{}}`, `{"version":"file:../evil"}`} {
+		if _, err := parseNpmViewDist(output); err == nil {
+			t.Errorf("parseNpmViewDist(%q) should fail", output)
+		}
+	}
+}
+
+func TestMirrorNodeFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("manifest should not download, got %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Length", "1234")
+	}))
+	defer server.Close()
+
+	inst := NewInstaller(context.Background(), nil)
+	inst.nodeMirror = server.URL
+	inst.verifyNodeSignature = true
+
+	file := inst.mirrorNodeFile(nodeMSIFilename())
+	if file.Error != "" {
+		t.Fatalf("mirrorNodeFile() error: %s", file.Error)
+	}
+	wantChecksum := fmt.Sprintf("%s/v%s/SHASUMS256.txt", server.URL, nodeLTSVersion)
+	if file.Component != ComponentNodeJS || file.Size != 1234 || file.ChecksumURL != wantChecksum || file.SignatureURL != wantChecksum+".asc" {
+		t.Errorf("mirrorNodeFile() = %+v", file)
+	}
+	if !strings.HasPrefix(file.URL, server.URL+"/") || !strings.HasSuffix(file.URL, nodeMSIFilename()) {
+		t.Errorf("mirrorNodeFile() URL = %q", file.URL)
+	}
+}
+
+func TestSetQuiet(t *testing.T) {
+	var got []string
+	logger := &recordingLogger{}
+	inst, err := NewInstallerWithOptions(context.Background(), func(p InstallProgress) {
+		got = append(got, fmt.Sprintf("%s %s %g", p.Step, p.Status, p.Percentage))
+	}, InstallOptions{Quiet: true, Logger: logger})
+	if err != nil {
+		t.Fatalf("NewInstallerWithOptions() error: %v", err)
+	}
+
+	inst.emitProgress("nodejs", "installing", "Checking for existing Node.js installation...", 0)
+	for pct := 10.0; pct < 90; pct += 10 {
+		inst.emitProgress("nodejs", "installing", "Downloading...", pct)
+	}
+	inst.emitFallback("nodejs", StrategyWinget, StrategyMSI, errors.New("winget failed"), 90)
+	inst.emitProgress("nodejs", "installing", "Running Node.js installer...", 95)
+	inst.emitProgress("nodejs", "completed", "Node.js installed successfully", 100)
+	inst.emitProgress("git", "installing", "Checking for existing Git installation...", 0)
+	inst.emitProgress("git", "installing", "Downloading Git...", 30)
+	inst.emitProgress("git", "error", "Download failed", 30)
+
+	want := []string{
+		"nodejs installing 0",
+		"nodejs installing 90",
+		"nodejs completed 100",
+		"git installing 0",
+		"git error 30",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("quiet events:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	// Every update is still logged, including the ones the UI doesn't see
+	if len(logger.lines) != 15 {
+		t.Errorf("quiet installer logged %d updates, want 15:\n%s", len(logger.lines), strings.Join(logger.lines, "\n"))
+	}
+
+	got = nil
+	inst.SetQuiet(false)
+	inst.emitProgress("git", "installing", "Checking for existing Git installation...", 0)
+	inst.emitProgress("git", "installing", "Downloading Git...", 30)
+	if len(got) != 2 {
+		t.Errorf("non-quiet installer emitted %d events, want 2", len(got))
+	}
+}
+
+func TestRollbackClaudeCodeRejectsInvalidVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "versions.jsonl")
+	if err := history.AppendVersion(path, history.VersionEntry{
+		Component: ComponentClaudeCode,
+		Previous:  "file:../../evil",
+		Installed: "2.0.0",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	inst := NewInstaller(context.Background(), nil)
+	inst.SetVersionHistoryPath(path)
+	target, err := inst.rollbackTarget("2.0.0")
+	if err == nil || !strings.Contains(err.Error(), "version history is corrupt") {
+		t.Errorf("rollbackTarget() = %q, %v, want a corrupt history error", target, err)
+	}
+
+	if err := history.AppendVersion(path, history.VersionEntry{
+		Component: ComponentClaudeCode,
+		Previous:  "v1.9.0",
+		Installed: "2.0.0",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := inst.rollbackTarget("2.0.0"); err != nil || target != "1.9.0" {
+		t.Errorf("rollbackTarget() = %q, %v, want 1.9.0", target, err)
+	}
+}
@@ -3,10 +3,26 @@
 package installer
 
 import (
+	"errors"
+	"fmt"
 	"os/exec"
+	"strings"
 	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
 )
 
+// msiExecuteMutex is held by the Windows Installer service while it installs a package.
+const msiExecuteMutex = `Global\_MSIExecute`
+
+// windowsInstallerService is the name of the Windows Installer service.
+const windowsInstallerService = "msiserver"
+
+// stillActive is the exit code GetExitCodeProcess reports for a running process.
+const stillActive = 259
+
 // hideConsoleWindow sets the SysProcAttr to hide the console window on Windows.
 func hideConsoleWindow(cmd *exec.Cmd) {
 	cmd.SysProcAttr = &syscall.SysProcAttr{
@@ -14,3 +30,223 @@ func hideConsoleWindow(cmd *exec.Cmd) {
 		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
 	}
 }
+
+// msiInstallInProgress reports whether the Windows Installer service is
+// currently running an installation.
+func msiInstallInProgress() bool {
+	name, err := windows.UTF16PtrFromString(msiExecuteMutex)
+	if err != nil {
+		return false
+	}
+	handle, err := windows.OpenMutex(windows.SYNCHRONIZE, false, name)
+	if err != nil {
+		return false
+	}
+	windows.CloseHandle(handle)
+	return true
+}
+
+// checkWindowsInstaller confirms that msiexec is on PATH and that the Windows
+// Installer service exists and is not disabled. The service starts on demand,
+// so being stopped is normal. Running "msiexec /?" is avoided because it shows
+// a help dialog that waits to be dismissed. If the service can't be queried,
+// the check passes and msiexec reports any problem itself.
+func checkWindowsInstaller() error {
+	if _, err := exec.LookPath("msiexec"); err != nil {
+		return fmt.Errorf("%w: msiexec was not found: %v", ErrWindowsInstallerUnavailable, err)
+	}
+
+	scm, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return nil
+	}
+	defer windows.CloseServiceHandle(scm)
+	name, err := windows.UTF16PtrFromString(windowsInstallerService)
+	if err != nil {
+		return nil
+	}
+	service, err := windows.OpenService(scm, name, windows.SERVICE_QUERY_CONFIG|windows.SERVICE_QUERY_STATUS)
+	if errors.Is(err, windows.ERROR_SERVICE_DOES_NOT_EXIST) {
+		return fmt.Errorf("%w: the Windows Installer service is not installed", ErrWindowsInstallerUnavailable)
+	}
+	if err != nil {
+		return nil
+	}
+	defer windows.CloseServiceHandle(service)
+
+	var needed uint32
+	// The first call fails with ERROR_INSUFFICIENT_BUFFER and reports the size
+	_ = windows.QueryServiceConfig(service, nil, 0, &needed)
+	if needed == 0 {
+		return nil
+	}
+	buf := make([]byte, needed)
+	config := (*windows.QUERY_SERVICE_CONFIG)(unsafe.Pointer(&buf[0]))
+	if err := windows.QueryServiceConfig(service, config, needed, &needed); err != nil {
+		return nil
+	}
+	if config.StartType == windows.SERVICE_DISABLED {
+		var status windows.SERVICE_STATUS
+		// A disabled service that is still running can serve this install
+		if windows.QueryServiceStatus(service, &status) == nil && status.CurrentState == windows.SERVICE_RUNNING {
+			return nil
+		}
+		return fmt.Errorf("%w: the Windows Installer service is disabled", ErrWindowsInstallerUnavailable)
+	}
+	return nil
+}
+
+// processIdentity returns the full image path and creation time of process
+// pid, which together tell it apart from a later process reusing the PID.
+func processIdentity(pid int) (string, time.Time, error) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer windows.CloseHandle(handle)
+	return handleIdentity(handle)
+}
+
+// handleIdentity is processIdentity for an open process handle.
+func handleIdentity(handle windows.Handle) (string, time.Time, error) {
+	buf := make([]uint16, windows.MAX_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(handle, 0, &buf[0], &size); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to query process image: %w", err)
+	}
+	var created, exited, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(handle, &created, &exited, &kernel, &user); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to query process times: %w", err)
+	}
+	return windows.UTF16ToString(buf[:size]), time.Unix(0, created.Nanoseconds()), nil
+}
+
+// processRunning reports whether the recorded process is still running. Its
+// full image path and creation time must both match, since batch files such
+// as npm.cmd run as cmd.exe and a reused PID may well belong to another
+// cmd.exe. Entries recorded without them are treated as exited.
+func processRunning(p RunningProcess) bool {
+	if p.Image == "" || p.Created.IsZero() {
+		return false
+	}
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(p.PID))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil || code != stillActive {
+		return false
+	}
+	image, created, err := handleIdentity(handle)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(image, p.Image) && created.Equal(p.Created)
+}
+
+// restrictToOwner replaces dir's inherited permissions with full control for
+// the current user and SYSTEM only, so other users can't replace downloaded
+// installers. SYSTEM keeps access because the Windows Installer service reads
+// MSI packages as SYSTEM.
+func restrictToOwner(dir string) error {
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", err)
+	}
+	system, err := windows.CreateWellKnownSid(windows.WinLocalSystemSid)
+	if err != nil {
+		return fmt.Errorf("failed to get SYSTEM SID: %w", err)
+	}
+
+	access := []windows.EXPLICIT_ACCESS{
+		{
+			AccessPermissions: windows.GENERIC_ALL,
+			AccessMode:        windows.SET_ACCESS,
+			Inheritance:       windows.SUB_CONTAINERS_AND_OBJECTS_INHERIT,
+			Trustee: windows.TRUSTEE{
+				TrusteeForm:  windows.TRUSTEE_IS_SID,
+				TrusteeType:  windows.TRUSTEE_IS_USER,
+				TrusteeValue: windows.TrusteeValueFromSID(user.User.Sid),
+			},
+		},
+		{
+			AccessPermissions: windows.GENERIC_ALL,
+			AccessMode:        windows.SET_ACCESS,
+			Inheritance:       windows.SUB_CONTAINERS_AND_OBJECTS_INHERIT,
+			Trustee: windows.TRUSTEE{
+				TrusteeForm:  windows.TRUSTEE_IS_SID,
+				TrusteeType:  windows.TRUSTEE_IS_WELL_KNOWN_GROUP,
+				TrusteeValue: windows.TrusteeValueFromSID(system),
+			},
+		},
+	}
+	acl, err := windows.ACLFromEntries(access, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build access list: %w", err)
+	}
+
+	// PROTECTED_DACL stops the parent's permissions from being inherited
+	err = windows.SetNamedSecurityInfo(dir, windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION, nil, nil, acl, nil)
+	if err != nil {
+		return fmt.Errorf("failed to restrict permissions on %s: %w", dir, err)
+	}
+	return nil
+}
+
+// verifyFileOwner checks that path is owned by the current user, or by the
+// Administrators group that owns files an elevated process creates.
+func verifyFileOwner(path string) error {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.OWNER_SECURITY_INFORMATION)
+	if err != nil {
+		return fmt.Errorf("failed to read owner of %s: %w", path, err)
+	}
+	owner, _, err := sd.Owner()
+	if err != nil {
+		return fmt.Errorf("failed to read owner of %s: %w", path, err)
+	}
+
+	token := windows.GetCurrentProcessToken()
+	user, err := token.GetTokenUser()
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", err)
+	}
+	if owner.Equals(user.User.Sid) {
+		return nil
+	}
+	if token.IsElevated() {
+		if admins, err := windows.CreateWellKnownSid(windows.WinBuiltinAdministratorsSid); err == nil && owner.Equals(admins) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is owned by %s", ErrDownloadOwnerChanged, path, owner.String())
+}
+
+// exeProductName reads the ProductName string from an executable's version
+// resource, using the first language/code page the resource declares.
+func exeProductName(path string) (string, error) {
+	size, err := windows.GetFileVersionInfoSize(path, nil)
+	if err != nil {
+		return "", fmt.Errorf("no version information: %w", err)
+	}
+	info := make([]byte, size)
+	block := unsafe.Pointer(&info[0])
+	if err := windows.GetFileVersionInfo(path, 0, size, block); err != nil {
+		return "", fmt.Errorf("failed to read version information: %w", err)
+	}
+
+	var translation *[2]uint16
+	var length uint32
+	if err := windows.VerQueryValue(block, `\VarFileInfo\Translation`, unsafe.Pointer(&translation), &length); err != nil || length < 4 {
+		return "", fmt.Errorf("version information has no language table")
+	}
+
+	subBlock := fmt.Sprintf(`\StringFileInfo\%04x%04x\ProductName`, translation[0], translation[1])
+	var value *uint16
+	if err := windows.VerQueryValue(block, subBlock, unsafe.Pointer(&value), &length); err != nil || length == 0 {
+		return "", fmt.Errorf("version information has no product name")
+	}
+	return windows.UTF16PtrToString(value), nil
+}
@@ -0,0 +1,107 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Default arguments of the native installers, which SetExtraInstallerArgs
+// appends to.
+var (
+	// defaultNodeMSIArgs are public MSI properties passed after
+	// `msiexec /qn /i <msi>`: ADDLOCAL=ALL installs every feature (runtime,
+	// npm, corepack, PATH entries and documentation shortcuts).
+	defaultNodeMSIArgs = []string{"ADDLOCAL=ALL"}
+
+	// defaultGitInstallerArgs are the Inno Setup switches passed to the Git
+	// installer, followed by /LOG=<temp file>:
+	//   /VERYSILENT        no UI at all
+	//   /NORESTART         don't restart the system
+	//   /SP-               suppress the "This will install..." prompt
+	//   /CLOSEAPPLICATIONS close running applications if needed
+	//   /NOCANCEL          remove the cancel button
+	defaultGitInstallerArgs = []string{"/VERYSILENT", "/NORESTART", "/SP-", "/CLOSEAPPLICATIONS", "/NOCANCEL"}
+)
+
+// installerArgShellChars are rejected in extra installer arguments. Arguments
+// are passed without a shell, but msiexec and Inno Setup parse their own
+// command lines, where quotes and these characters change the meaning.
+const installerArgShellChars = "\"&|<>^`;%$"
+
+// SetExtraInstallerArgs appends args to the native installer invocation of
+// component: MSI properties such as ADDLOCAL=NodeRuntime,npm for "nodejs",
+// or Inno Setup switches such as /COMPONENTS=icons,gitlfs for "git". They
+// are added after the defaults (defaultNodeMSIArgs, defaultGitInstallerArgs),
+// so for repeated settings they take precedence.
+// Empty args clears the extra arguments for component.
+func (i *Installer) SetExtraInstallerArgs(component string, args []string) error {
+	if err := ValidateInstallerArgs(component, args); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(args) == 0 {
+		delete(i.extraInstallerArgs, component)
+		return nil
+	}
+	if i.extraInstallerArgs == nil {
+		i.extraInstallerArgs = make(map[string][]string)
+	}
+	i.extraInstallerArgs[component] = append([]string(nil), args...)
+	return nil
+}
+
+// ValidateInstallerArgs checks extra installer arguments for component.
+// Node.js accepts only PROPERTY=value MSI properties, since msiexec options
+// would change the operation; Git accepts Inno Setup /switches other than
+// /LOG, which the installer relies on for diagnostics.
+func ValidateInstallerArgs(component string, args []string) error {
+	if component != ComponentNodeJS && component != ComponentGit {
+		return fmt.Errorf("extra installer arguments are not supported for %q", component)
+	}
+	for _, arg := range args {
+		if arg == "" || strings.TrimSpace(arg) != arg {
+			return fmt.Errorf("installer argument %q is empty or has surrounding whitespace", arg)
+		}
+		if strings.ContainsAny(arg, installerArgShellChars) || strings.ContainsFunc(arg, isControlRune) {
+			return fmt.Errorf("installer argument %q contains disallowed characters", arg)
+		}
+
+		switch component {
+		case ComponentNodeJS:
+			name, _, ok := strings.Cut(arg, "=")
+			if !ok || name == "" || strings.ToUpper(name) != name || strings.ContainsAny(name, "/- ") {
+				return fmt.Errorf("Node.js installer argument %q must be an MSI property such as ADDLOCAL=NodeRuntime", arg)
+			}
+		case ComponentGit:
+			if !strings.HasPrefix(arg, "/") {
+				return fmt.Errorf("Git installer argument %q must be a /switch", arg)
+			}
+			if strings.HasPrefix(strings.ToUpper(arg), "/LOG") {
+				return fmt.Errorf("Git installer argument %q would replace the installer log", arg)
+			}
+		}
+	}
+	return nil
+}
+
+// isControlRune reports whether r is an ASCII control character.
+func isControlRune(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
+
+// installerArgs returns defaults followed by the extra arguments configured
+// for component.
+func (i *Installer) installerArgs(component string, defaults []string) []string {
+	i.mu.Lock()
+	extra := i.extraInstallerArgs[component]
+	i.mu.Unlock()
+
+	args := append([]string(nil), defaults...)
+	if len(extra) > 0 {
+		i.emitDetail(component, "extra installer arguments: %s", strings.Join(extra, " "))
+		args = append(args, extra...)
+	}
+	return args
+}
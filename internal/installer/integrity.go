@@ -0,0 +1,112 @@
+package installer
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"claude-code-installer/internal/downloader"
+)
+
+// ErrIntegrityUnsupported is returned when an installed component cannot be
+// verified against official checksums.
+var ErrIntegrityUnsupported = errors.New("integrity verification is not supported for this installation")
+
+// VerifyInstalledIntegrity checks whether an installed component's binary matches
+// the official release artifact. It reports whether the binary matched; an error
+// means verification could not be completed.
+//
+// Only the zip-based (portable) Node.js install can be verified: the official
+// SHASUMS cover release archives, and the node.exe laid down by the MSI is not
+// published as a separately checksummed artifact. Git for Windows publishes
+// checksums only for its installers, so installed Git cannot be verified.
+func (i *Installer) VerifyInstalledIntegrity(component string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(component)) {
+	case "nodejs", "node":
+		return i.verifyNodeIntegrity()
+	case "git", "claudecode", "claude":
+		return false, fmt.Errorf("%s: %w", component, ErrIntegrityUnsupported)
+	default:
+		return false, fmt.Errorf("unknown component %q", component)
+	}
+}
+
+// verifyNodeIntegrity re-downloads the Node.js zip matching the installed
+// version, verifies it against SHASUMS256.txt, and compares its node.exe with
+// the installed one.
+func (i *Installer) verifyNodeIntegrity() (bool, error) {
+	nodePath := filepath.Join(portableNodeDir(), "node.exe")
+	if _, err := os.Stat(nodePath); err != nil {
+		return false, fmt.Errorf("only zip-installed Node.js can be verified: %w", ErrIntegrityUnsupported)
+	}
+
+	output, err := i.runCommand(nodePath, "--version")
+	if err != nil {
+		return false, fmt.Errorf("failed to determine installed Node.js version: %w", err)
+	}
+	version := strings.TrimPrefix(strings.TrimSpace(output), "v")
+
+	tempDir, err := getTempDir()
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	zipFilename := fmt.Sprintf("node-v%s-win-%s.zip", version, nodeArch())
+	zipPath := filepath.Join(tempDir, zipFilename)
+
+	if err := i.downloadNodeFile(version, zipFilename, zipPath); err != nil {
+		return false, fmt.Errorf("failed to download reference archive: %w", err)
+	}
+	if err := i.verifyNodeDownload(zipPath, zipFilename, version); err != nil {
+		return false, err
+	}
+
+	expectedHash, err := zipEntrySHA256(zipPath, "node.exe")
+	if err != nil {
+		return false, err
+	}
+	actualHash, err := downloader.FileSHA256(nodePath)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(expectedHash, actualHash), nil
+}
+
+// zipEntrySHA256 returns the SHA-256 hash of the first archive entry whose base
+// name is name.
+func zipEntrySHA256(zipPath, name string) (string, error) {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || path.Base(strings.ReplaceAll(f.Name, `\`, "/")) != name {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return "", fmt.Errorf("failed to open archive entry %s: %w", f.Name, err)
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, io.LimitReader(rc, maxDownloadSize))
+		rc.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to hash archive entry %s: %w", f.Name, err)
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	return "", fmt.Errorf("%s not found in archive", name)
+}
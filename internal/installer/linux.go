@@ -0,0 +1,150 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"claude-code-installer/internal/pathutil"
+)
+
+// linuxPackageManager describes how to install packages with a distribution package manager.
+type linuxPackageManager struct {
+	name         string
+	strategy     string
+	refreshArgs  []string // optional metadata refresh run before installing
+	installArgs  []string
+	nodePackages []string
+	gitPackages  []string
+}
+
+// linuxPackageManagers lists supported package managers in detection order.
+var linuxPackageManagers = []linuxPackageManager{
+	{
+		name:         "apt-get",
+		strategy:     StrategyApt,
+		refreshArgs:  []string{"update"},
+		installArgs:  []string{"install", "-y"},
+		nodePackages: []string{"nodejs", "npm"},
+		gitPackages:  []string{"git"},
+	},
+	{
+		name:         "dnf",
+		strategy:     StrategyDnf,
+		installArgs:  []string{"install", "-y"},
+		nodePackages: []string{"nodejs", "npm"},
+		gitPackages:  []string{"git"},
+	},
+	{
+		name:         "pacman",
+		strategy:     StrategyPacman,
+		installArgs:  []string{"-S", "--noconfirm", "--needed"},
+		nodePackages: []string{"nodejs", "npm"},
+		gitPackages:  []string{"git"},
+	},
+}
+
+// linuxNpmPrefixDir is the per-user npm global prefix used when not running as root,
+// relative to the home directory.
+const linuxNpmPrefixDir = ".npm-global"
+
+// findLinuxPackageManager returns the first supported package manager found in PATH.
+func findLinuxPackageManager() (*linuxPackageManager, error) {
+	for idx := range linuxPackageManagers {
+		if _, err := exec.LookPath(linuxPackageManagers[idx].name); err == nil {
+			return &linuxPackageManagers[idx], nil
+		}
+	}
+	return nil, fmt.Errorf("no supported package manager found (tried apt-get, dnf, pacman)")
+}
+
+// privilegedCommand returns the command and arguments to run name with root
+// privileges, using pkexec (graphical prompt) or non-interactive sudo.
+func privilegedCommand(name string, args ...string) (string, []string, error) {
+	if os.Geteuid() == 0 {
+		return name, args, nil
+	}
+	for _, helper := range []struct {
+		name string
+		args []string
+	}{
+		{"pkexec", nil},
+		{"sudo", []string{"-n"}},
+	} {
+		if helperPath, err := exec.LookPath(helper.name); err == nil {
+			fullArgs := append(append(append([]string{}, helper.args...), name), args...)
+			return helperPath, fullArgs, nil
+		}
+	}
+	return "", nil, fmt.Errorf("root privileges are required to run %s (pkexec or sudo not found)", name)
+}
+
+// installViaLinuxPackageManager installs packages with the detected distribution
+// package manager and verifies that command is runnable.
+func (i *Installer) installViaLinuxPackageManager(stepName, component string, packages func(*linuxPackageManager) []string, command string) error {
+	pm, err := findLinuxPackageManager()
+	if err != nil {
+		i.emitProgress(stepName, "error",
+			fmt.Sprintf("No supported package manager found. Please install %s manually.", component), 0)
+		return fmt.Errorf("cannot install %s: %w", component, err)
+	}
+
+	i.setStrategy(stepName, pm.strategy)
+
+	if len(pm.refreshArgs) > 0 {
+		i.emitProgress(stepName, "installing", fmt.Sprintf("Refreshing %s package lists...", pm.name), 10)
+		name, args, err := privilegedCommand(pm.name, pm.refreshArgs...)
+		if err == nil {
+			// Non-fatal: stale metadata often still resolves the packages
+			_, _ = i.runCommand(name, args...)
+		}
+	}
+
+	i.emitProgress(stepName, "installing", fmt.Sprintf("Installing %s via %s...", component, pm.name), 20)
+
+	name, args, err := privilegedCommand(pm.name, append(append([]string{}, pm.installArgs...), packages(pm)...)...)
+	if err != nil {
+		i.emitProgress(stepName, "error", err.Error(), 0)
+		return fmt.Errorf("failed to install %s: %w", component, err)
+	}
+	if _, err := i.runCommand(name, args...); err != nil {
+		i.emitProgress(stepName, "error", fmt.Sprintf("Failed to install %s: %v", component, err), 0)
+		return fmt.Errorf("failed to install %s via %s: %w", component, pm.name, err)
+	}
+
+	if err := i.verifyExecutable(command, stepName, "--version", nil); err != nil {
+		i.emitProgress(stepName, "error",
+			fmt.Sprintf("%s was installed but verification failed. Please restart the application.", component), 0)
+		return fmt.Errorf("%s installed but verification failed: %w", component, err)
+	}
+
+	i.emitProgress(stepName, "completed", fmt.Sprintf("%s installed successfully via %s", component, pm.name), 100)
+	return nil
+}
+
+// linuxNpmGlobalArgs returns extra npm arguments so global installs go to a
+// per-user prefix when not running as root, plus that prefix's bin directory.
+// Distribution npm installs its global prefix under /usr, which needs root.
+func linuxNpmGlobalArgs() ([]string, string) {
+	if os.Geteuid() == 0 {
+		return nil, ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, ""
+	}
+	prefix := filepath.Join(home, linuxNpmPrefixDir)
+	return []string{"--prefix", prefix}, filepath.Join(prefix, "bin")
+}
+
+// exposeNpmBinDir makes a per-user npm bin directory available to this process
+// and to future login shells.
+func (i *Installer) exposeNpmBinDir(stepName, binDir string) {
+	prependToProcessPath(binDir)
+	if err := pathutil.AddToPath(binDir); err != nil {
+		i.emitProgress(stepName, "installing",
+			fmt.Sprintf("Warning: could not add %s to PATH automatically: %v", binDir, strings.TrimSpace(err.Error())), 78)
+	}
+}
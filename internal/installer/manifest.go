@@ -0,0 +1,97 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxManifestSize caps the size of a checksum manifest read by VerifyManifest.
+const maxManifestSize = maxTextResponseSize
+
+// VerifyResult is the outcome of verifying one file listed in a checksum manifest.
+type VerifyResult struct {
+	File   string `json:"file"`
+	Passed bool   `json:"passed"`
+	// Error explains why verification failed.
+	Error string `json:"error,omitempty"`
+}
+
+// VerifyManifest verifies every file listed in a SHASUMS256.txt-style manifest
+// against the files in the manifest's directory, for pre-flighting an offline
+// bundle before installation. Per-file failures are reported in the results;
+// an error is returned only if the manifest itself cannot be used.
+func (i *Installer) VerifyManifest(manifestPath string) ([]VerifyResult, error) {
+	info, err := os.Stat(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if info.Size() > maxManifestSize {
+		return nil, fmt.Errorf("manifest too large: %d bytes exceeds limit of %d", info.Size(), maxManifestSize)
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	content := string(data)
+
+	files := manifestFiles(content)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("manifest %s lists no files", manifestPath)
+	}
+
+	dir := filepath.Dir(manifestPath)
+	results := make([]VerifyResult, 0, len(files))
+	for idx, file := range files {
+		if err := i.ctx.Err(); err != nil {
+			return nil, fmt.Errorf("verification cancelled: %w", err)
+		}
+		i.emitProgress("verifyManifest", "installing",
+			fmt.Sprintf("Verifying %s...", file), float64(idx)/float64(len(files))*100)
+
+		result := VerifyResult{File: file}
+		if err := verifyManifestEntry(content, dir, file); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Passed = true
+		}
+		results = append(results, result)
+	}
+
+	i.emitProgress("verifyManifest", "completed", fmt.Sprintf("Verified %d files", len(results)), 100)
+	return results, nil
+}
+
+// verifyManifestEntry verifies a single manifest entry relative to dir.
+func verifyManifestEntry(content, dir, file string) error {
+	// Entries must stay inside the bundle directory
+	if !filepath.IsLocal(filepath.FromSlash(file)) {
+		return fmt.Errorf("invalid file path in manifest")
+	}
+
+	expectedHash, err := findChecksumInSHASUMS(content, file)
+	if err != nil {
+		return err
+	}
+	return verifyFileChecksum(filepath.Join(dir, filepath.FromSlash(file)), expectedHash)
+}
+
+// manifestFiles returns the file names listed in a SHASUMS256.txt-style
+// manifest, in order and without duplicates.
+func manifestFiles(content string) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, line := range strings.Split(content, "\n") {
+		parts := strings.Fields(line)
+		if len(parts) < 2 {
+			continue
+		}
+		name := strings.TrimPrefix(parts[1], "*")
+		if !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+	return files
+}
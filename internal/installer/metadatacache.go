@@ -0,0 +1,115 @@
+package installer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"claude-code-installer/internal/detector"
+	"claude-code-installer/internal/version"
+)
+
+// metadataCacheTTL is how long resolved release metadata is reused. The UI
+// checks the system, plans and checks for updates in quick succession, and
+// each of those resolves the same releases.
+const metadataCacheTTL = 5 * time.Minute
+
+// metadataCache is an in-memory, TTL-based cache of release metadata keyed
+// by source. Failed lookups are not cached.
+type metadataCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]metadataEntry
+}
+
+type metadataEntry struct {
+	value   any
+	expires time.Time
+}
+
+// releaseMetadata is shared by all installers, since the App creates one per
+// operation.
+var releaseMetadata = newMetadataCache(metadataCacheTTL)
+
+func newMetadataCache(ttl time.Duration) *metadataCache {
+	return &metadataCache{ttl: ttl, now: time.Now, entries: make(map[string]metadataEntry)}
+}
+
+// clear discards every cached entry.
+func (c *metadataCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]metadataEntry)
+}
+
+// cachedMetadata returns the unexpired value cached under key, or calls fetch
+// and caches its result when it succeeds. Concurrent misses may each fetch.
+func cachedMetadata[T any](c *metadataCache, key string, fetch func() (T, error)) (T, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		if value, ok := entry.value.(T); ok {
+			return value, nil
+		}
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = metadataEntry{value: value, expires: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+	return value, nil
+}
+
+// RefreshMetadataCache discards cached release metadata (the latest Git
+// release, the latest Claude Code version, download sizes and whether winget
+// is available) and npm's cached global bin directory, so the next lookups
+// fetch them again.
+func RefreshMetadataCache() {
+	releaseMetadata.clear()
+	detector.InvalidateNpmGlobalBinDir()
+}
+
+// PrewarmMetadataCache resolves the release metadata that system checks,
+// install plans and update checks need, concurrently and in the background
+// of the UI's first screen. Failures are ignored; they are reported by the
+// operation that needs the metadata.
+func (i *Installer) PrewarmMetadataCache() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = i.getLatestGitRelease()
+	}()
+	if npmPath, err := i.findNpm(); err == nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = i.latestClaudeVersion(npmPath)
+		}()
+	}
+	wg.Wait()
+}
+
+// latestClaudeVersion returns the latest published Claude Code version
+// according to the registry npmPath is configured with.
+func (i *Installer) latestClaudeVersion(npmPath string) (string, error) {
+	return cachedMetadata(releaseMetadata, "npm:"+npmPath+":"+claudeCodePackage, func() (string, error) {
+		output, err := i.runCommand(npmPath, "view", claudeCodePackage, "version")
+		if err != nil {
+			return "", err
+		}
+		// The version is shown to the user and compared against; reject
+		// anything a misbehaving registry returns that isn't one
+		latest, err := version.Validate(output)
+		if err != nil {
+			return "", fmt.Errorf("npm registry returned an invalid version: %w", err)
+		}
+		return latest, nil
+	})
+}
@@ -0,0 +1,159 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Milestone phases reported in Milestone.Phase.
+const (
+	PhaseDownloading = "downloading"
+	PhaseVerifying   = "verifying"
+	PhaseInstalling  = "installing"
+	PhaseDone        = "done"
+	PhaseFailed      = "failed"
+	PhaseSkipped     = "skipped"
+)
+
+// milestoneStep is the percentage interval between milestones.
+const milestoneStep = 25
+
+// Milestone is a discrete progress announcement for assistive technology:
+// the start of a phase, or the step crossing a quarter of its progress.
+type Milestone struct {
+	Step string `json:"step"`
+	// Phase is the phase the step is in (one of the Phase* constants), or ""
+	// before the step has entered one.
+	Phase string `json:"phase"`
+	// Percentage is the milestone reached: 0, 25, 50, 75 or 100. A failed or
+	// skipped step reports the last one it reached.
+	Percentage int `json:"percentage"`
+	// Message is a short sentence suitable for a screen reader to announce.
+	Message string `json:"message"`
+}
+
+// milestoneState records the last milestone announced for a step.
+type milestoneState struct {
+	phase   string
+	reached int
+}
+
+// stepLabels names steps in milestone announcements.
+var stepLabels = map[string]string{
+	ComponentNodeJS:      "Node.js",
+	ComponentGit:         "Git",
+	ComponentClaudeCode:  "Claude Code",
+	"claudeCodeUpdate":   "Claude Code update",
+	"claudeCodeRollback": "Claude Code rollback",
+	"claudeCodeRepair":   "Claude Code repair",
+}
+
+// SetMilestoneHandler configures a callback that receives a Milestone when a
+// step starts downloading, verifying or installing, when it finishes, and
+// each time it reaches 0, 25, 50, 75 or 100 percent. The continuous progress
+// events are unchanged; milestones are a low-frequency companion for screen
+// readers, which can't usefully announce every percentage update. A nil
+// handler turns milestones off.
+func (i *Installer) SetMilestoneHandler(onMilestone func(Milestone)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.onMilestone = onMilestone
+}
+
+// publishMilestones announces any phase change or milestone percentage that
+// progress reaches. The caller must hold i.mu.
+func (i *Installer) publishMilestones(progress InstallProgress) {
+	if i.onMilestone == nil {
+		return
+	}
+	if i.milestones == nil {
+		i.milestones = make(map[string]*milestoneState)
+	}
+	label := stepLabels[progress.Step]
+	if label == "" {
+		label = progress.Step
+	}
+
+	state, started := i.milestones[progress.Step]
+	phase := progressPhase(progress)
+	switch phase {
+	case PhaseDone, PhaseFailed, PhaseSkipped:
+		// The step is over; a later event for it starts afresh
+		delete(i.milestones, progress.Step)
+		percentage := 100
+		if phase != PhaseDone {
+			percentage = 0
+			if started {
+				percentage = max(state.reached, 0)
+			}
+		}
+		i.onMilestone(Milestone{Step: progress.Step, Phase: phase, Percentage: percentage, Message: phaseAnnouncement(label, phase)})
+		return
+	}
+
+	if !started {
+		state = &milestoneState{reached: -1}
+		i.milestones[progress.Step] = state
+	}
+	reached := int(progress.Percentage) / milestoneStep * milestoneStep
+	reached = max(0, min(reached, 100))
+	if phase != "" && phase != state.phase {
+		state.phase = phase
+		i.onMilestone(Milestone{Step: progress.Step, Phase: phase, Percentage: reached, Message: phaseAnnouncement(label, phase)})
+	}
+	if reached > state.reached {
+		state.reached = reached
+		i.onMilestone(Milestone{
+			Step:       progress.Step,
+			Phase:      state.phase,
+			Percentage: reached,
+			Message:    fmt.Sprintf("%s: %d percent complete", label, reached),
+		})
+	}
+}
+
+// progressPhase infers the phase a progress event belongs to from its status
+// and message, or returns "" when the message doesn't indicate one.
+func progressPhase(progress InstallProgress) string {
+	switch progress.Status {
+	case "completed":
+		return PhaseDone
+	case "error":
+		return PhaseFailed
+	case "skipped":
+		return PhaseSkipped
+	}
+
+	message := strings.ToLower(progress.Message)
+	switch {
+	case strings.HasPrefix(message, "checking"), strings.HasPrefix(message, "starting"):
+		// "Checking for existing Node.js installation..." precedes any phase
+		return ""
+	case strings.Contains(message, "verif"), strings.Contains(message, "checksum"), strings.Contains(message, "integrity"):
+		return PhaseVerifying
+	case strings.HasPrefix(message, "downloading"):
+		return PhaseDownloading
+	case strings.Contains(message, "install"), strings.HasPrefix(message, "running"):
+		return PhaseInstalling
+	}
+	return ""
+}
+
+// phaseAnnouncement describes entering phase for a screen reader.
+func phaseAnnouncement(label, phase string) string {
+	switch phase {
+	case PhaseDownloading:
+		return label + ": download started"
+	case PhaseVerifying:
+		return label + ": verifying"
+	case PhaseInstalling:
+		return label + ": installing"
+	case PhaseDone:
+		return label + ": done"
+	case PhaseFailed:
+		return label + ": failed"
+	case PhaseSkipped:
+		return label + ": skipped"
+	}
+	return label
+}
@@ -0,0 +1,174 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"claude-code-installer/internal/version"
+)
+
+// DownloadManifest lists what the installer downloads on Windows, resolved
+// without downloading anything, so administrators can copy the files to an
+// internal mirror and point NodeMirror and the npm registry at it.
+type DownloadManifest struct {
+	Files []MirrorFile `json:"files"`
+	// ClaudeCode is the npm package Claude Code is installed from.
+	ClaudeCode MirrorPackage `json:"claudeCode"`
+	// TotalSize is the sum of the known file and package sizes in bytes.
+	TotalSize int64 `json:"totalSize"`
+}
+
+// MirrorFile is a file downloaded during installation, with the sources its
+// integrity is checked against.
+type MirrorFile struct {
+	Component string `json:"component"`
+	URL       string `json:"url"`
+	// Size is the file size in bytes, or 0 when unknown.
+	Size int64 `json:"size"`
+	// ChecksumURL publishes the SHA-256 the download is verified against.
+	ChecksumURL string `json:"checksumUrl,omitempty"`
+	// SignatureURL is the signature over ChecksumURL, when one is checked.
+	SignatureURL string `json:"signatureUrl,omitempty"`
+	// Error is set when the file could not be resolved.
+	Error string `json:"error,omitempty"`
+}
+
+// MirrorPackage is an npm package installed during installation.
+type MirrorPackage struct {
+	// Spec is the exact package to mirror, such as "@anthropic-ai/claude-code@1.0.0".
+	Spec      string `json:"spec"`
+	Tarball   string `json:"tarball,omitempty"`
+	Integrity string `json:"integrity,omitempty"`
+	// Size is the tarball size in bytes, or 0 when unknown.
+	Size int64 `json:"size"`
+	// Error is set when the version could not be resolved, for example
+	// because npm is not installed yet.
+	Error string `json:"error,omitempty"`
+}
+
+// DownloadManifest resolves the URLs, checksum sources and sizes of the
+// Node.js MSI and zip, the Git for Windows installer, and the Claude Code npm
+// package, using the configured Node.js mirror and Git bitness. Resolution
+// failures are reported per entry; an error is returned only if resolving is
+// cancelled.
+func (i *Installer) DownloadManifest() (*DownloadManifest, error) {
+	manifest := &DownloadManifest{}
+	for _, filename := range []string{nodeMSIFilename(), nodeZipFilename()} {
+		manifest.Files = append(manifest.Files, i.mirrorNodeFile(filename))
+	}
+	manifest.Files = append(manifest.Files, i.mirrorGitInstaller())
+	manifest.ClaudeCode = i.mirrorClaudePackage()
+	if err := i.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("resolving downloads cancelled: %w", err)
+	}
+
+	for _, file := range manifest.Files {
+		manifest.TotalSize += file.Size
+	}
+	manifest.TotalSize += manifest.ClaudeCode.Size
+	return manifest, nil
+}
+
+// mirrorNodeFile resolves a Node.js release file and its SHASUMS256.txt.
+func (i *Installer) mirrorNodeFile(filename string) MirrorFile {
+	file := MirrorFile{
+		Component:   ComponentNodeJS,
+		URL:         i.nodeDownloadURL(filename),
+		ChecksumURL: i.nodeDownloadURL("SHASUMS256.txt"),
+	}
+	if i.nodeSignatureRequired() {
+		file.SignatureURL = file.ChecksumURL + ".asc"
+	}
+	size, err := cachedMetadata(releaseMetadata, "size:"+file.URL, func() (int64, error) {
+		return i.fetchContentLength(file.URL)
+	})
+	if err != nil {
+		file.Error = err.Error()
+	} else if size > 0 {
+		file.Size = size
+	}
+	return file
+}
+
+// mirrorGitInstaller resolves the Git for Windows installer and its .sha256 asset.
+func (i *Installer) mirrorGitInstaller() MirrorFile {
+	file := MirrorFile{Component: ComponentGit}
+	release, err := i.getLatestGitRelease()
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+	downloadURL, err := i.getGitDownloadURL()
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+	file.URL = downloadURL
+	file.ChecksumURL = downloadURL + ".sha256"
+	for _, asset := range release.Assets {
+		if asset.BrowserDownloadURL == downloadURL {
+			file.Size = asset.Size
+			break
+		}
+	}
+	return file
+}
+
+// mirrorClaudePackage resolves the latest Claude Code version and tarball
+// from the npm registry npm is configured with.
+func (i *Installer) mirrorClaudePackage() MirrorPackage {
+	pkg := MirrorPackage{Spec: claudeCodePackage}
+	npmPath, err := i.findNpm()
+	if err != nil {
+		pkg.Error = "npm is needed to resolve the Claude Code version; install Node.js first"
+		return pkg
+	}
+	output, err := i.runCommand(npmPath, "view", claudeCodePackage, "version", "dist", "--json")
+	if err != nil {
+		pkg.Error = summarizeError(err)
+		return pkg
+	}
+	info, err := parseNpmViewDist(output)
+	if err != nil {
+		pkg.Error = err.Error()
+		return pkg
+	}
+
+	pkg.Spec = claudeCodePackage + "@" + info.Version
+	pkg.Tarball = info.Dist.Tarball
+	pkg.Integrity = info.Dist.Integrity
+	if pkg.Tarball != "" {
+		if size, err := i.fetchContentLength(pkg.Tarball); err == nil && size > 0 {
+			pkg.Size = size
+		}
+	}
+	return pkg
+}
+
+// npmViewDist is the output of `npm view <pkg> version dist --json`.
+type npmViewDist struct {
+	Version string `json:"version"`
+	Dist    struct {
+		Tarball   string `json:"tarball"`
+		Integrity string `json:"integrity"`
+	} `json:"dist"`
+}
+
+// parseNpmViewDist parses the output of `npm view <pkg> version dist --json`.
+func parseNpmViewDist(output string) (npmViewDist, error) {
+	var info npmViewDist
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &info); err != nil {
+		return info, fmt.Errorf("failed to parse npm view output: %w", err)
+	}
+	if info.Version == "" {
+		return info, fmt.Errorf("npm view did not report a version")
+	}
+	// The version becomes part of the package spec administrators mirror
+	normalized, err := version.Validate(info.Version)
+	if err != nil {
+		return info, fmt.Errorf("npm view reported an invalid version: %w", err)
+	}
+	info.Version = normalized
+	return info, nil
+}
@@ -1,76 +1,203 @@
 package installer
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
+	"claude-code-installer/internal/detector"
+	"claude-code-installer/internal/elevation"
+	"claude-code-installer/internal/nodekeys"
 	"claude-code-installer/internal/pathutil"
+	"claude-code-installer/internal/updater"
 )
 
 const (
+	// CleanupTimeout bounds the cleanup an installer does after being
+	// cancelled, such as rolling back an interrupted MSI install. The app
+	// waits at least this long on shutdown so the cleanup is not cut short.
+	CleanupTimeout = 10 * time.Second
+
 	// nodeLTSVersion is the current Node.js LTS version to install.
 	nodeLTSVersion = "22.13.1"
 	// nodeDownloadBaseURL is the base URL for Node.js downloads.
 	nodeDownloadBaseURL = "https://nodejs.org/dist"
 	// wingetNodePackage is the winget package ID for Node.js LTS.
 	wingetNodePackage = "OpenJS.NodeJS.LTS"
+	// claudeCodeMinNode is the oldest Node.js Claude Code supports.
+	claudeCodeMinNode = ">=18"
 )
 
+// ErrWindowsInstallerUnavailable is returned before downloading the Node.js
+// MSI when msiexec is missing or the Windows Installer service is disabled.
+var ErrWindowsInstallerUnavailable = errors.New("Windows Installer is not available")
+
 // InstallNodeJS installs Node.js using winget (preferred) or direct MSI download (fallback).
+// On macOS it uses Homebrew and on Linux the distribution package manager.
 func (i *Installer) InstallNodeJS() error {
+	return i.withTelemetry("nodejs", func() error {
+		// A new Node.js may bring an npm with a different global prefix
+		defer detector.InvalidateNpmGlobalBinDir()
+		return i.withPostInstallHook("nodejs", i.installNodeJS)
+	})
+}
+
+// installNodeJS performs the Node.js installation; see InstallNodeJS.
+func (i *Installer) installNodeJS() error {
 	stepName := "nodejs"
 
+	if err := i.ensureNoPreviousInstall(stepName); err != nil {
+		return err
+	}
+
 	i.emitProgress(stepName, "installing", "Checking for existing Node.js installation...", 0)
 
-	// Check if already installed
-	if _, err := exec.LookPath("node"); err == nil {
+	// Check if already installed and actually runnable
+	if found, working := i.isCommandWorking("node"); working {
 		i.emitProgress(stepName, "completed", "Node.js is already installed", 100)
 		return nil
+	} else if found {
+		i.emitProgress(stepName, "installing", "Existing Node.js installation is broken, reinstalling...", 5)
 	}
 
-	// Strategy 1: Try winget
-	if isWingetAvailable() {
-		i.emitProgress(stepName, "installing", "Installing Node.js via winget...", 10)
+	// The strategies below are Windows-specific (winget, MSI/EXE installers,
+	// Program Files paths); other platforms use their package manager
+	switch runtime.GOOS {
+	case "windows":
+	case "darwin":
+		return i.installViaBrew(stepName, "Node.js", brewNodeFormula, "node")
+	case "linux":
+		return i.installViaLinuxPackageManager(stepName, "Node.js",
+			func(pm *linuxPackageManager) []string { return pm.nodePackages }, "node")
+	default:
+		return i.unsupportedPlatformError(stepName, "Node.js")
+	}
 
-		err := i.installNodeViaWinget()
-		if err == nil {
-			// Refresh PATH and verify
-			_ = pathutil.RefreshPath()
+	i.emitDetail(stepName, "arch detected: %s (Node.js distribution %s)", runtime.GOARCH, nodeArch())
+	i.warnSystemInstallElevation(stepName, "Node.js", "node", 6)
 
-			if verifyErr := i.verifyNode(); verifyErr == nil {
-				i.emitProgress(stepName, "completed", "Node.js installed successfully via winget", 100)
-				return nil
-			}
-		}
-
-		i.emitProgress(stepName, "installing", "Winget installation failed, trying direct download...", 20)
+	// A version manager shims node through its own directories; a system-wide
+	// install alongside it leads to confusing PATH precedence
+	for _, manager := range detector.DetectNodeVersionManagers() {
+		i.emitProgress(stepName, "installing",
+			fmt.Sprintf("Warning: %s is managing Node.js versions; a system Node.js install may conflict with it", manager.Name), 7)
 	}
+	i.warnVisualStudioNode(stepName)
 
-	// Strategy 2: Direct MSI download
-	i.emitProgress(stepName, "installing", "Downloading Node.js installer...", 25)
-
-	err := i.installNodeViaMSI()
+	err := i.runBackends(stepName, map[string]installBackend{
+		StrategyWinget:   {StrategyWinget, i.installNodeWithWinget},
+		StrategyScoop:    {StrategyScoop, i.installNodeWithScoop},
+		StrategyDownload: {i.nodeDownloadStrategy(), i.installNodeWithDownload},
+	})
 	if err != nil {
 		i.emitProgress(stepName, "error", fmt.Sprintf("Failed to install Node.js: %v", err), 0)
 		return fmt.Errorf("failed to install Node.js: %w", err)
 	}
+	return nil
+}
+
+// warnVisualStudioNode warns when Visual Studio already bundles a Node.js
+// that meets Claude Code's minimum version. It is still not usable on its own:
+// it isn't on PATH and ships without npm, so a separate install goes ahead.
+func (i *Installer) warnVisualStudioNode(stepName string) {
+	nodePath := detector.FindVisualStudioNode()
+	if nodePath == "" {
+		return
+	}
+	version, err := i.runCommand(nodePath, "--version")
+	if err != nil {
+		return
+	}
+	version = strings.TrimSpace(version)
+	i.emitDetail(stepName, "Visual Studio bundles Node.js %s at %s", version, nodePath)
+	if satisfied, err := updater.SatisfiesRange(version, claudeCodeMinNode); err == nil && satisfied {
+		i.emitProgress(stepName, "installing",
+			fmt.Sprintf("Warning: Node.js %s bundled with Visual Studio already meets Claude Code's minimum, but it is not on PATH and has no npm; installing a separate Node.js", version), 7)
+	}
+}
+
+// installNodeWithWinget installs Node.js via winget and verifies it.
+func (i *Installer) installNodeWithWinget() error {
+	stepName := "nodejs"
+	i.emitProgress(stepName, "installing", "Installing Node.js via winget...", 10)
+
+	if err := i.installNodeViaWinget(); err != nil {
+		return err
+	}
+
+	// Refresh PATH and verify
+	i.refreshPath(stepName)
+	if err := i.verifyNode(); err != nil {
+		return fmt.Errorf("installed but not found on PATH: %w", err)
+	}
+
+	i.emitProgress(stepName, "completed", "Node.js installed successfully via winget", 100)
+	return nil
+}
+
+// installNodeWithScoop installs Node.js via Scoop and verifies it.
+func (i *Installer) installNodeWithScoop() error {
+	stepName := "nodejs"
+	i.emitProgress(stepName, "installing", "Installing Node.js via Scoop...", 10)
+
+	if err := i.installViaScoop(stepName, scoopNodePackage); err != nil {
+		return err
+	}
+	if err := i.verifyNode(); err != nil {
+		return fmt.Errorf("installed but not found on PATH: %w", err)
+	}
+
+	i.emitProgress(stepName, "completed", "Node.js installed successfully via Scoop", 100)
+	return nil
+}
+
+// installNodeWithDownload installs Node.js from the official MSI or zip and
+// verifies it. The MSI needs elevation to write to Program Files, so the
+// portable zip is used when not elevated or when requested.
+func (i *Installer) installNodeWithDownload() error {
+	stepName := "nodejs"
+	// A per-user zip would sit behind the broken machine-wide install on PATH
+	if err := i.requireElevationForSystemInstall(stepName, "Node.js", "node"); err != nil {
+		return err
+	}
+	i.emitDetail(stepName, "portable mode requested: %s, elevated: %s -> using %s",
+		yesNo(i.portableRequested()), yesNo(pathutil.IsElevated()), i.nodeDownloadStrategy())
+
+	var err error
+	if i.usePortableNode() {
+		i.emitProgress(stepName, "installing", "Downloading Node.js archive...", 25)
+		err = i.installNodeViaZip()
+	} else {
+		i.emitProgress(stepName, "installing", "Downloading Node.js installer...", 25)
+		err = i.installNodeViaMSI()
+	}
+	if err != nil {
+		return err
+	}
 
 	// Refresh PATH after installation
-	_ = pathutil.RefreshPath()
+	i.refreshPath(stepName)
 
 	// Add Node.js to PATH if not already present
-	if err := pathutil.AddToPath(defaultNodeJSPath); err != nil {
+	if i.usePortableNode() {
+		err = pathutil.AddToPath(portableNodeDir())
+	} else {
+		err = i.addToPath(stepName, defaultNodeJSPath)
+	}
+	if err != nil {
 		// Non-fatal: log but continue
-		i.emitProgress(stepName, "installing", "Warning: could not add Node.js to PATH automatically", 90)
+		i.emitProgress(stepName, "installing", pathWarning("Node.js", err), 90)
 	}
 
 	// Verify installation
 	if err := i.verifyNode(); err != nil {
-		i.emitProgress(stepName, "error", "Node.js was installed but verification failed. Please restart the application.", 0)
-		return fmt.Errorf("Node.js installed but verification failed: %w", err)
+		return fmt.Errorf("Node.js was installed but verification failed (restart the application to retry): %w", err)
 	}
 
 	i.emitProgress(stepName, "completed", "Node.js installed successfully", 100)
@@ -79,28 +206,23 @@ func (i *Installer) InstallNodeJS() error {
 
 // installNodeViaWinget installs Node.js using the Windows Package Manager.
 func (i *Installer) installNodeViaWinget() error {
-	_, err := i.runCommand("winget", "install",
+	return i.runWinget("nodejs", "install",
 		wingetNodePackage,
 		"--silent",
 		"--accept-package-agreements",
 		"--accept-source-agreements",
 	)
-	return err
 }
 
 // installNodeViaMSI downloads and installs Node.js via MSI installer.
 func (i *Installer) installNodeViaMSI() error {
-	// Determine architecture
-	arch := "x64"
-	if runtime.GOARCH == "386" {
-		arch = "x86"
-	} else if runtime.GOARCH == "arm64" {
-		arch = "arm64"
+	// Check msiexec can run before spending time on a 30 MB download
+	if err := checkWindowsInstaller(); err != nil {
+		return err
 	}
 
 	// Build download URL
-	msiFilename := fmt.Sprintf("node-v%s-%s.msi", nodeLTSVersion, arch)
-	downloadURL := fmt.Sprintf("%s/v%s/%s", nodeDownloadBaseURL, nodeLTSVersion, msiFilename)
+	msiFilename := nodeMSIFilename()
 
 	// Create temp directory for download (unique per call, caller must clean up)
 	tempDir, err := getTempDir()
@@ -111,34 +233,38 @@ func (i *Installer) installNodeViaMSI() error {
 
 	msiPath := filepath.Join(tempDir, msiFilename)
 
-	// Download the MSI with retry logic
-	if err := i.downloadFileWithRetry(downloadURL, msiPath, "nodejs"); err != nil {
+	// Download the MSI with retry logic, falling back to other mirrors
+	if err := i.downloadNodeFile(nodeLTSVersion, msiFilename, msiPath); err != nil {
 		return fmt.Errorf("failed to download Node.js installer: %w", err)
 	}
 
 	// Verify download integrity via SHA-256 checksum (mandatory)
 	i.emitProgress("nodejs", "installing", "Verifying download integrity...", 55)
-	shasumsURL := fmt.Sprintf("%s/v%s/SHASUMS256.txt", nodeDownloadBaseURL, nodeLTSVersion)
-	shasumsContent, err := i.fetchTextContent(shasumsURL)
-	if err != nil {
-		return fmt.Errorf("failed to verify Node.js download integrity (could not fetch checksums): %w", err)
-	}
-	expectedHash, err := findChecksumInSHASUMS(shasumsContent, msiFilename)
-	if err != nil {
-		return fmt.Errorf("failed to verify Node.js download integrity (checksum not found for %s): %w", msiFilename, err)
-	}
-	if err := verifyFileChecksum(msiPath, expectedHash); err != nil {
-		return fmt.Errorf("Node.js installer integrity check failed: %w", err)
+	if err := i.verifyNodeDownload(msiPath, msiFilename, nodeLTSVersion); err != nil {
+		return err
 	}
 	i.emitProgress("nodejs", "installing", "Download integrity verified", 65)
+	i.retainDownload(msiPath, "nodejs")
 
 	i.emitProgress("nodejs", "installing", "Running Node.js installer...", 70)
+	if err := verifyFileOwner(msiPath); err != nil {
+		return err
+	}
+
+	// Only a product this run installs may be rolled back on cancellation;
+	// uninstalling a Node.js the user already had would remove it
+	preinstalled := detector.NodeMSIInstalled()
+
+	// Keep the install tracked through any rollback so shutdown waits for it
+	defer i.trackCommand()()
 
 	// Run msiexec with quiet install
-	_, err = i.runCommand("msiexec", "/qn", "/i", msiPath,
-		"ADDLOCAL=ALL",
-	)
+	args := append([]string{"/qn", "/i", msiPath}, i.installerArgs(ComponentNodeJS, defaultNodeMSIArgs)...)
+	_, err = i.runCommand("msiexec", args...)
 	if err != nil {
+		if i.ctx.Err() != nil {
+			return i.rollbackInterruptedMSI(msiPath, preinstalled)
+		}
 		return fmt.Errorf("msiexec failed: %w", err)
 	}
 
@@ -146,10 +272,395 @@ func (i *Installer) installNodeViaMSI() error {
 	return i.pollForCommand("node", 30)
 }
 
+// rollbackInterruptedMSI handles cancellation while msiexec runs. Killing the
+// msiexec client does not stop the Windows Installer service, which carries on
+// installing in the background; wait for it to settle, then uninstall the
+// package so no partial install is left behind. A Node.js that was installed
+// before this run is left in place. Both waits are bounded by CleanupTimeout
+// so the cleanup finishes before the app stops waiting on shutdown.
+func (i *Installer) rollbackInterruptedMSI(msiPath string, preinstalled bool) error {
+	// i.ctx is already cancelled, so the cleanup runs under its own deadline
+	ctx, cancel := context.WithTimeout(context.Background(), CleanupTimeout)
+	defer cancel()
+
+	pct := i.progressFloor("nodejs", 70)
+	i.emitProgress("nodejs", "installing", "Cancelling: waiting for Windows Installer to finish...", pct)
+	for msiInstallInProgress() {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: Windows Installer is still running; check Node.js once it finishes", ErrInstallerInterrupted)
+		case <-time.After(1 * time.Second):
+		}
+	}
+	if preinstalled {
+		return fmt.Errorf("%w: Node.js was already installed before this run, so it was not rolled back; check Node.js", ErrInstallerInterrupted)
+	}
+
+	i.emitProgress("nodejs", "installing", "Cancelling: rolling back the Node.js installation...", pct)
+	if err := i.uninstallMSI(ctx, msiPath); err != nil {
+		return fmt.Errorf("%w: rollback failed, Node.js may be partially installed: %v", ErrInstallerInterrupted, err)
+	}
+	return fmt.Errorf("%w: Node.js installation was rolled back", ErrInstallerInterrupted)
+}
+
+// uninstallMSI uninstalls the product of the package at msiPath, through the
+// elevation handler when the process is not elevated, as installing it
+// required, and within ctx's deadline.
+func (i *Installer) uninstallMSI(ctx context.Context, msiPath string) error {
+	if pathutil.IsElevated() {
+		_, err := i.streamCommand(ctx, nil, nil, "msiexec", "/qn", "/x", msiPath)
+		return err
+	}
+	if !i.requestElevation("Roll back the interrupted Node.js installation") {
+		return elevation.ErrDeclined
+	}
+	deadline, _ := ctx.Deadline()
+	return elevation.RunWithin(time.Until(deadline), elevation.OpUninstallMSI, msiPath)
+}
+
+// installNodeViaZip downloads the Node.js zip archive and extracts it to a
+// per-user directory, which requires no administrator rights.
+func (i *Installer) installNodeViaZip() error {
+	zipFilename := nodeZipFilename()
+
+	// Create temp directory for download (unique per call, caller must clean up)
+	tempDir, err := getTempDir()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	zipPath := filepath.Join(tempDir, zipFilename)
+
+	// Download the archive with retry logic, falling back to other mirrors
+	if err := i.downloadNodeFile(nodeLTSVersion, zipFilename, zipPath); err != nil {
+		return fmt.Errorf("failed to download Node.js archive: %w", err)
+	}
+
+	// Verify download integrity via SHA-256 checksum (mandatory)
+	i.emitProgress("nodejs", "installing", "Verifying download integrity...", 55)
+	if err := i.verifyNodeDownload(zipPath, zipFilename, nodeLTSVersion); err != nil {
+		return err
+	}
+	i.emitProgress("nodejs", "installing", "Download integrity verified", 65)
+	i.retainDownload(zipPath, "nodejs")
+
+	i.emitProgress("nodejs", "installing", "Extracting Node.js...", 70)
+
+	destDir := portableNodeDir()
+	// Replace any previous portable install so stale files don't linger
+	if err := os.RemoveAll(destDir); err != nil {
+		return fmt.Errorf("failed to remove previous Node.js directory: %w", err)
+	}
+	if err := extractZip(zipPath, destDir, true); err != nil {
+		return fmt.Errorf("failed to extract Node.js archive: %w", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(destDir, "node.exe")); err != nil {
+		return fmt.Errorf("node.exe not found after extraction: %w", err)
+	}
+	return nil
+}
+
+// verifyNodeDownload checks a downloaded Node.js file against the SHASUMS256.txt
+// of the given release version.
+// When a custom mirror is configured and not explicitly trusted, the mirror's
+// checksum is cross-checked against the canonical nodejs.org SHASUMS first.
+func (i *Installer) verifyNodeDownload(filePath, filename, version string) error {
+	shasumsURL := fmt.Sprintf("%s/v%s/SHASUMS256.txt", i.nodeBaseURL(), version)
+	shasumsContent, err := i.fetchTextContent(shasumsURL)
+	if err != nil {
+		return fmt.Errorf("failed to verify Node.js download integrity (could not fetch checksums): %w", err)
+	}
+
+	i.emitDetail("nodejs", "checksum source: %s", shasumsURL)
+
+	if i.nodeSignatureRequired() {
+		if err := i.verifyNodeShasumsSignature(shasumsURL, shasumsContent); err != nil {
+			return err
+		}
+	}
+
+	var expectedHash string
+	if i.nodeMirrorNeedsCrossCheck() {
+		i.emitProgress("nodejs", "installing", "Cross-checking mirror checksums with nodejs.org...", 58)
+		canonicalURL := fmt.Sprintf("%s/v%s/SHASUMS256.txt", nodeDownloadBaseURL, version)
+		i.emitDetail("nodejs", "mirror is not trusted: cross-checking against %s", canonicalURL)
+		canonicalContent, err := i.fetchTextContent(canonicalURL)
+		if err != nil {
+			return fmt.Errorf("failed to fetch canonical Node.js checksums for mirror validation: %w", err)
+		}
+		expectedHash, err = crossCheckMirrorChecksum(shasumsContent, canonicalContent, filename)
+		if err != nil {
+			return err
+		}
+	} else {
+		expectedHash, err = findChecksumInSHASUMS(shasumsContent, filename)
+		if err != nil {
+			return fmt.Errorf("failed to verify Node.js download integrity (checksum not found for %s): %w", filename, err)
+		}
+	}
+
+	if err := i.verifyFileChecksumWithProgress(filePath, expectedHash, "nodejs", 58, 65); err != nil {
+		return fmt.Errorf("Node.js download integrity check failed: %w", err)
+	}
+	return nil
+}
+
+// verifyNodeShasumsSignature checks SHASUMS256.txt.asc over shasumsContent
+// against the Node.js release keys embedded in the binary.
+func (i *Installer) verifyNodeShasumsSignature(shasumsURL, shasumsContent string) error {
+	i.emitProgress("nodejs", "installing", "Verifying Node.js release signature...", 57)
+	signature, err := i.fetchTextContent(shasumsURL + ".asc")
+	if err != nil {
+		return fmt.Errorf("failed to fetch Node.js checksum signature: %w", err)
+	}
+	signer, err := nodekeys.Verify([]byte(shasumsContent), []byte(signature))
+	if err != nil {
+		return fmt.Errorf("Node.js checksum signature verification failed: %w", err)
+	}
+	i.emitDetail("nodejs", "SHASUMS256.txt signed by release key %s", signer)
+	return nil
+}
+
+// crossCheckMirrorChecksum returns the checksum for filename after confirming
+// that the mirror's SHASUMS agrees with the canonical nodejs.org SHASUMS.
+func crossCheckMirrorChecksum(mirrorContent, canonicalContent, filename string) (string, error) {
+	mirrorHash, err := findChecksumInSHASUMS(mirrorContent, filename)
+	if err != nil {
+		return "", fmt.Errorf("mirror SHASUMS is missing %s: %w", filename, err)
+	}
+	canonicalHash, err := findChecksumInSHASUMS(canonicalContent, filename)
+	if err != nil {
+		return "", fmt.Errorf("canonical SHASUMS is missing %s: %w", filename, err)
+	}
+	if !strings.EqualFold(mirrorHash, canonicalHash) {
+		return "", fmt.Errorf("mirror checksum for %s does not match nodejs.org (mirror %s, canonical %s); the mirror may be stale or compromised",
+			filename, mirrorHash, canonicalHash)
+	}
+	return canonicalHash, nil
+}
+
+// SetNodeMirror configures an alternative base URL (equivalent to
+// https://nodejs.org/dist) for Node.js downloads. Unless trustMirror is set,
+// the mirror's checksums are validated against nodejs.org before use.
+// An empty baseURL restores the default.
+func (i *Installer) SetNodeMirror(baseURL string, trustMirror bool) error {
+	baseURL, err := normalizeMirrorURL(baseURL)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.nodeMirror = baseURL
+	i.trustMirror = trustMirror
+	return nil
+}
+
+// ValidateNodeMirror checks a Node.js mirror base URL; see SetNodeMirror.
+func ValidateNodeMirror(baseURL string) error {
+	_, err := normalizeMirrorURL(baseURL)
+	return err
+}
+
+// SetNodeFallbackMirrors configures additional Node.js mirror base URLs that
+// are tried in order when downloading from the primary source fails. Files
+// served by a fallback mirror are still verified against the primary
+// source's checksums.
+func (i *Installer) SetNodeFallbackMirrors(baseURLs []string) error {
+	mirrors, err := normalizeFallbackMirrors(baseURLs)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.nodeFallbackMirrors = mirrors
+	return nil
+}
+
+// ValidateNodeFallbackMirrors checks Node.js fallback mirror base URLs; see
+// SetNodeFallbackMirrors.
+func ValidateNodeFallbackMirrors(baseURLs []string) error {
+	_, err := normalizeFallbackMirrors(baseURLs)
+	return err
+}
+
+// normalizeFallbackMirrors normalizes each fallback mirror with
+// normalizeMirrorURL, dropping empty ones.
+func normalizeFallbackMirrors(baseURLs []string) ([]string, error) {
+	mirrors := make([]string, 0, len(baseURLs))
+	for _, baseURL := range baseURLs {
+		normalized, err := normalizeMirrorURL(baseURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fallback mirror %q: %w", baseURL, err)
+		}
+		if normalized != "" {
+			mirrors = append(mirrors, normalized)
+		}
+	}
+	return mirrors, nil
+}
+
+// normalizeMirrorURL validates a mirror base URL and strips trailing slashes.
+// An empty URL is returned unchanged.
+func normalizeMirrorURL(baseURL string) (string, error) {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return "", nil
+	}
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid mirror URL: %w", err)
+	}
+	if parsedURL.Scheme != "https" || parsedURL.Host == "" {
+		return "", fmt.Errorf("mirror URL must be an absolute HTTPS URL")
+	}
+	return baseURL, nil
+}
+
+// nodeDownloadSources returns the primary Node.js base URL followed by any
+// fallback mirrors.
+func (i *Installer) nodeDownloadSources() []string {
+	sources := []string{i.nodeBaseURL()}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, mirror := range i.nodeFallbackMirrors {
+		if mirror != sources[0] {
+			sources = append(sources, mirror)
+		}
+	}
+	return sources
+}
+
+// downloadNodeFile downloads a Node.js release file, trying each fallback
+// mirror in turn once retries against the previous source are exhausted.
+func (i *Installer) downloadNodeFile(version, filename, destPath string) error {
+	sources := i.nodeDownloadSources()
+
+	var lastErr error
+	for idx, baseURL := range sources {
+		downloadURL := fmt.Sprintf("%s/v%s/%s", baseURL, version, filename)
+		err := i.downloadFileWithRetry(downloadURL, destPath, "nodejs")
+		if err == nil {
+			if idx > 0 {
+				i.emitProgress("nodejs", "installing", fmt.Sprintf("Downloaded Node.js from mirror %s", baseURL), i.progressFloor("nodejs", downloadEndPct))
+			}
+			return nil
+		}
+		if i.stopRequested(err) {
+			return err
+		}
+
+		lastErr = err
+		if idx < len(sources)-1 {
+			i.emitProgress("nodejs", "installing",
+				fmt.Sprintf("Download from %s failed, trying mirror %s...", baseURL, sources[idx+1]), i.progressFloor("nodejs", downloadStartPct))
+		}
+	}
+	return lastErr
+}
+
+// nodeBaseURL returns the base URL used for Node.js downloads.
+func (i *Installer) nodeBaseURL() string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.nodeMirror != "" {
+		return i.nodeMirror
+	}
+	return nodeDownloadBaseURL
+}
+
+// nodeMirrorNeedsCrossCheck reports whether a custom, untrusted mirror is configured.
+func (i *Installer) nodeMirrorNeedsCrossCheck() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.nodeMirror != "" && i.nodeMirror != nodeDownloadBaseURL && !i.trustMirror
+}
+
+// SetPortableMode forces Node.js to be installed from the zip archive into a
+// per-user directory instead of via the MSI, even when running elevated.
+func (i *Installer) SetPortableMode(enabled bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.portable = enabled
+}
+
+// SetVerifyNodeSignature requires SHASUMS256.txt to carry a valid signature
+// from one of the Node.js release keys embedded in the binary before its
+// checksums are trusted. No keyserver is contacted.
+func (i *Installer) SetVerifyNodeSignature(enabled bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.verifyNodeSignature = enabled
+}
+
+// nodeSignatureRequired reports whether SHASUMS signatures are verified.
+func (i *Installer) nodeSignatureRequired() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.verifyNodeSignature
+}
+
+// usePortableNode reports whether Node.js should be installed from the zip archive.
+func (i *Installer) usePortableNode() bool {
+	return i.portableRequested() || (runtime.GOOS == "windows" && !pathutil.IsElevated())
+}
+
+// portableRequested reports whether portable mode was explicitly enabled.
+func (i *Installer) portableRequested() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.portable
+}
+
+// nodeDownloadStrategy returns the direct-download strategy that will be used for Node.js.
+func (i *Installer) nodeDownloadStrategy() string {
+	if i.usePortableNode() {
+		return StrategyZip
+	}
+	return StrategyMSI
+}
+
+// nodeArch returns the Node.js distribution architecture for the current platform.
+func nodeArch() string {
+	switch runtime.GOARCH {
+	case "386":
+		return "x86"
+	case "arm64":
+		return "arm64"
+	default:
+		return "x64"
+	}
+}
+
+// nodeMSIFilename returns the file name of the Node.js MSI installer.
+func nodeMSIFilename() string {
+	return fmt.Sprintf("node-v%s-%s.msi", nodeLTSVersion, nodeArch())
+}
+
+// nodeZipFilename returns the file name of the Node.js Windows zip archive.
+func nodeZipFilename() string {
+	return fmt.Sprintf("node-v%s-win-%s.zip", nodeLTSVersion, nodeArch())
+}
+
+// nodeDownloadURL returns the download URL of a Node.js release file.
+func (i *Installer) nodeDownloadURL(filename string) string {
+	return fmt.Sprintf("%s/v%s/%s", i.nodeBaseURL(), nodeLTSVersion, filename)
+}
+
+// portableNodeDir returns the per-user directory for the zip-based Node.js install.
+func portableNodeDir() string {
+	return filepath.Join(getLocalAppDataPath(), "Programs", "nodejs")
+}
+
 // verifyNode checks that node is accessible after installation.
 func (i *Installer) verifyNode() error {
 	return i.verifyExecutable("node", "nodejs", "--version", []string{
 		`C:\Program Files\nodejs\node.exe`,
 		`C:\Program Files (x86)\nodejs\node.exe`,
+		filepath.Join(portableNodeDir(), "node.exe"),
+		filepath.Join(detector.ScoopShimsDir(), "node.exe"),
 	})
 }
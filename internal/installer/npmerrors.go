@@ -0,0 +1,143 @@
+package installer
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"claude-code-installer/internal/httputil"
+	"claude-code-installer/internal/logging"
+)
+
+// ErrorDetail carries the full output of a failed command, which is too long
+// for progress messages and error events but is what diagnoses the failure.
+type ErrorDetail struct {
+	Step    string `json:"step"`
+	Command string `json:"command"`
+	// Cause is the most likely cause parsed from the output.
+	Cause  string `json:"cause"`
+	Output string `json:"output"`
+	// LogPath is the tool's own debug log, such as npm's, if it named one.
+	LogPath string `json:"logPath,omitempty"`
+}
+
+// SetErrorDetailHandler configures the callback that receives the full
+// output of failed package manager commands.
+func (i *Installer) SetErrorDetailHandler(onErrorDetail func(ErrorDetail)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.onErrorDetail = onErrorDetail
+}
+
+// npmErrorCodePattern matches the error code line of npm 6-9 ("npm ERR! code
+// EACCES") and npm 10+ ("npm error code EACCES").
+var npmErrorCodePattern = regexp.MustCompile(`(?m)^npm (?:ERR!|error) code (\S+)`)
+
+// npmDebugLogPattern matches where npm says its debug log was written; npm 6
+// prints the path on the next line.
+var npmDebugLogPattern = regexp.MustCompile(`(?m)A complete log of this run can be found in:\s*(?:npm ERR!\s+)?(\S.*?)\s*$`)
+
+// npmNetworkCodes are the Node.js error codes for failing to reach the registry.
+var npmNetworkCodes = []string{"ENOTFOUND", "EAI_AGAIN", "ETIMEDOUT", "ECONNRESET", "ECONNREFUSED"}
+
+// npmCauses maps npm error codes to user-facing causes, in the order they are
+// searched for when npm printed no code line.
+var npmCauses = []struct {
+	codes []string
+	cause string
+}{
+	{[]string{"EACCES"}, "npm does not have permission to write to its global folder (EACCES). Don't use sudo; set a per-user npm prefix or fix the folder's ownership."},
+	{[]string{"EPERM", "EBUSY"}, "A file npm needs to replace is locked (%s). Close Claude Code sessions and editors, or pause antivirus scanning, then try again."},
+	{[]string{"EBADENGINE"}, "The installed Node.js or npm is older than Claude Code requires (EBADENGINE). Upgrade Node.js and try again."},
+	{[]string{"ENOSPC"}, "There is not enough free disk space to install Claude Code (ENOSPC)."},
+	{[]string{"SELF_SIGNED_CERT_IN_CHAIN", "UNABLE_TO_GET_ISSUER_CERT_LOCALLY", "CERT_HAS_EXPIRED", "UNABLE_TO_VERIFY_LEAF_SIGNATURE"}, "npm does not trust the registry's certificate (%s), usually because a proxy inspects HTTPS traffic. Set NODE_EXTRA_CA_CERTS to your organization's CA certificate."},
+	{[]string{"E401", "E403", "ENEEDAUTH"}, "The registry rejected npm's credentials (%s). Check the auth token in your .npmrc."},
+	{[]string{"E404"}, "The Claude Code package was not found in the configured registry (E404). Check the registry setting in your .npmrc."},
+	{npmNetworkCodes, "npm could not reach the package registry (%s). Check your internet connection or proxy settings."},
+}
+
+// diagnoseNpmError returns the most likely cause of a failed npm command
+// from its output.
+func diagnoseNpmError(output string) string {
+	if match := npmErrorCodePattern.FindStringSubmatch(output); match != nil {
+		code := match[1]
+		for _, entry := range npmCauses {
+			for _, c := range entry.codes {
+				if c == code {
+					return npmCause(entry.cause, code)
+				}
+			}
+		}
+		return fmt.Sprintf("npm failed with error code %s.", code)
+	}
+
+	// pnpm and Yarn print the underlying Node.js error codes without npm's
+	// code line
+	for _, entry := range npmCauses {
+		for _, code := range entry.codes {
+			if strings.Contains(output, code) {
+				return npmCause(entry.cause, code)
+			}
+		}
+	}
+	return "The package manager failed; see the full output for details."
+}
+
+// isNpmNetworkError reports whether a failed package manager command's output
+// shows it could not reach the registry.
+func isNpmNetworkError(output string) bool {
+	if match := npmErrorCodePattern.FindStringSubmatch(output); match != nil {
+		return slices.Contains(npmNetworkCodes, match[1])
+	}
+	for _, code := range npmNetworkCodes {
+		if strings.Contains(output, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// npmCause fills the matched code into cause when it has a placeholder.
+func npmCause(cause, code string) string {
+	if strings.Contains(cause, "%s") {
+		return fmt.Sprintf(cause, code)
+	}
+	return cause
+}
+
+// npmDebugLog returns the debug log path npm printed, or "".
+func npmDebugLog(output string) string {
+	if match := npmDebugLogPattern.FindStringSubmatch(output); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// packageManagerFailure reports a failed package manager command: the full
+// output goes to the install log and the error detail handler. It returns the
+// diagnosed cause for progress messages, and err prefixed with it.
+func (i *Installer) packageManagerFailure(stepName, name string, args []string, output string, err error) (string, error) {
+	detail := ErrorDetail{
+		Step:    stepName,
+		Command: httputil.RedactCredentials(strings.Join(append([]string{name}, args...), " ")),
+		Cause:   diagnoseNpmError(output),
+		Output:  httputil.RedactCredentials(output),
+		LogPath: npmDebugLog(output),
+	}
+
+	i.mu.Lock()
+	if i.logger != nil {
+		_ = i.logger.Log(logging.LevelError, stepName, fmt.Sprintf("%s failed:\n%s", detail.Command, detail.Output))
+	}
+	handler := i.onErrorDetail
+	i.mu.Unlock()
+	if handler != nil {
+		handler(detail)
+	}
+	if detail.LogPath != "" {
+		i.emitDetail(stepName, "npm debug log: %s", detail.LogPath)
+	}
+
+	return detail.Cause, fmt.Errorf("%s %w", detail.Cause, err)
+}
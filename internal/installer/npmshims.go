@@ -0,0 +1,102 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"claude-code-installer/internal/detector"
+	"claude-code-installer/internal/pathutil"
+)
+
+// maxShimSize bounds how much of a bin shim is read to identify its package.
+const maxShimSize = 64 * 1024
+
+// claudeShimNames lists the bin shims npm creates for the claude command.
+func claudeShimNames() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"claude", "claude.cmd", "claude.ps1"}
+	}
+	return []string{"claude"}
+}
+
+// ensureNpmBinOnPath adds the directory npm installed Claude Code's shims to
+// PATH when it is missing: userBinDir when global installs are redirected
+// there, otherwise npm's global bin directory on Windows, which is only on
+// PATH already when npm uses its default prefix of %APPDATA%\npm.
+func (i *Installer) ensureNpmBinOnPath(stepName, npmPath string, prefixArgs []string, userBinDir string) {
+	if userBinDir == "" && runtime.GOOS == "windows" {
+		if binDir, err := detector.NpmGlobalBinDirFor(npmPath, prefixArgs); err == nil && !pathutil.PathContains(os.Getenv("PATH"), binDir) {
+			userBinDir = binDir
+		}
+	}
+	if userBinDir != "" {
+		i.exposeNpmBinDir(stepName, userBinDir)
+	}
+}
+
+// removeStaleClaudeShims deletes claude shims left in npm's global bin
+// directory after the package itself is gone, which otherwise make claude
+// look installed and can make npm refuse to overwrite them. It does nothing
+// while the package is installed and returns the removed paths.
+func (i *Installer) removeStaleClaudeShims(npmPath string, prefixArgs []string) ([]string, error) {
+	if i.isClaudePackageInstalled(npmPath, prefixArgs) {
+		return nil, nil
+	}
+	binDir, err := detector.NpmGlobalBinDirFor(npmPath, prefixArgs)
+	if err != nil {
+		return nil, err
+	}
+	return removeClaudeShims(binDir)
+}
+
+// removeClaudeShims deletes the claude shims in binDir that point at the
+// Claude Code package, leaving any unrelated "claude" command alone.
+func removeClaudeShims(binDir string) ([]string, error) {
+	var removed []string
+	for _, name := range claudeShimNames() {
+		path := filepath.Join(binDir, name)
+		if !isClaudeShim(path) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove stale shim %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// isClaudeShim reports whether path is an npm bin shim (a symlink or a
+// generated script) that launches the Claude Code package.
+func isClaudeShim(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+
+	var target string
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err = os.Readlink(path)
+		if err != nil {
+			return false
+		}
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return false
+		}
+		defer f.Close()
+		data, err := io.ReadAll(io.LimitReader(f, maxShimSize))
+		if err != nil {
+			return false
+		}
+		target = string(data)
+	}
+
+	// Windows shims reference node_modules\@anthropic-ai\claude-code\...
+	return strings.Contains(strings.ReplaceAll(target, `\`, "/"), claudeCodePackage+"/")
+}
@@ -0,0 +1,176 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// SetPackageManager selects the Node.js package manager that installs and
+// updates Claude Code globally: "npm" (the default), "pnpm" or "yarn" (Yarn
+// 1.x, the last version with global installs). An empty name restores npm.
+// The manager is checked for when an install or update starts.
+func (i *Installer) SetPackageManager(pm string) error {
+	if err := ValidatePackageManager(pm); err != nil {
+		return err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.packageManager = pm
+	return nil
+}
+
+// ValidatePackageManager checks that pm names a supported package manager;
+// see SetPackageManager.
+func ValidatePackageManager(pm string) error {
+	switch pm {
+	case "", StrategyNPM, StrategyPNPM, StrategyYarn:
+		return nil
+	default:
+		return fmt.Errorf("unknown package manager %q (use npm, pnpm or yarn)", pm)
+	}
+}
+
+// claudePackageManager returns the package manager selected for Claude Code.
+func (i *Installer) claudePackageManager() string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.packageManager == "" {
+		return StrategyNPM
+	}
+	return i.packageManager
+}
+
+// findPackageManager locates pm, returning an error that names it when it is
+// not installed. Only Yarn 1.x is accepted, since later versions removed
+// `yarn global`.
+func (i *Installer) findPackageManager(pm string) (string, error) {
+	if pm == StrategyNPM {
+		return i.findNpm()
+	}
+
+	path, err := exec.LookPath(pm)
+	if err != nil && runtime.GOOS == "windows" {
+		// Installed with npm -g, enabled through Corepack, or (pnpm) by its
+		// standalone installer
+		candidates := []string{
+			filepath.Join(getAppDataPath(), "npm", pm+".cmd"),
+			filepath.Join(`C:\Program Files\nodejs`, pm+".cmd"),
+		}
+		if pm == StrategyPNPM {
+			candidates = append(candidates, filepath.Join(getLocalAppDataPath(), "pnpm", "pnpm.exe"))
+		}
+		for _, candidate := range candidates {
+			if _, statErr := os.Stat(candidate); statErr == nil {
+				path, err = candidate, nil
+				break
+			}
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s is selected for Claude Code but was not found; install %s or choose npm", pm, pm)
+	}
+
+	if pm == StrategyYarn {
+		version, err := i.runCommand(path, "--version")
+		if err != nil {
+			return "", fmt.Errorf("failed to check the yarn version: %w", err)
+		}
+		if !strings.HasPrefix(strings.TrimSpace(version), "1.") {
+			return "", fmt.Errorf("yarn %s has no global installs; use Yarn 1.x, pnpm or npm", strings.TrimSpace(version))
+		}
+	}
+	return path, nil
+}
+
+// globalAddArgs returns the arguments that install spec globally with pm.
+func globalAddArgs(pm, spec string) []string {
+	switch pm {
+	case StrategyPNPM:
+		return []string{"add", "-g", spec}
+	case StrategyYarn:
+		return []string{"global", "add", spec}
+	}
+	return []string{"install", "-g", spec}
+}
+
+// globalRemoveArgs returns the arguments that uninstall pkg globally with pm.
+func globalRemoveArgs(pm, pkg string) []string {
+	switch pm {
+	case StrategyPNPM:
+		return []string{"remove", "-g", pkg}
+	case StrategyYarn:
+		return []string{"global", "remove", pkg}
+	}
+	return []string{"uninstall", "-g", pkg}
+}
+
+// globalPackageDir returns the directory pm installs global packages into:
+// `pnpm root -g`, or the node_modules of `yarn global dir`.
+func (i *Installer) globalPackageDir(pm, pmPath string) (string, error) {
+	if pm == StrategyYarn {
+		dir, err := i.runCommand(pmPath, "global", "dir")
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(strings.TrimSpace(dir), "node_modules"), nil
+	}
+	root, err := i.runCommand(pmPath, "root", "-g")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(root), nil
+}
+
+// isClaudeManagedBy reports whether pm's global packages include Claude Code.
+func (i *Installer) isClaudeManagedBy(pm, pmPath string) bool {
+	if pm == StrategyNPM {
+		prefixArgs, _ := npmGlobalArgs()
+		return i.isClaudePackageInstalled(pmPath, prefixArgs)
+	}
+	dir, err := i.globalPackageDir(pm, pmPath)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(dir, filepath.FromSlash(claudeCodePackage), "package.json"))
+	return err == nil
+}
+
+// installClaudeWithPackageManager installs Claude Code globally with pnpm or
+// Yarn; npm installs go through installClaudeCode's npm path, which also
+// cleans up stale shims and reports fine-grained progress.
+func (i *Installer) installClaudeWithPackageManager(stepName, pm, pmPath string) error {
+	i.setStrategy(stepName, pm)
+	i.emitProgress(stepName, "installing", fmt.Sprintf("Installing Claude Code via %s...", pm), 20)
+
+	args := globalAddArgs(pm, claudeCodePackage)
+	if output, err := i.runCommand(pmPath, args...); err != nil {
+		cause, err := i.packageManagerFailure(stepName, pmPath, args, output, err)
+		if isNpmNetworkError(output) {
+			err = i.annotateOutage(stepName, serviceNpm, err)
+		}
+		err = packageManagerHint(pm, err)
+		i.emitProgress(stepName, "error", "Failed to install Claude Code: "+cause, 0)
+		return fmt.Errorf("failed to install Claude Code: %w", err)
+	}
+
+	if pm == StrategyYarn {
+		// Yarn's global bin directory is often not on PATH
+		if binDir, err := i.runCommand(pmPath, "global", "bin"); err == nil && strings.TrimSpace(binDir) != "" {
+			i.exposeNpmBinDir(stepName, strings.TrimSpace(binDir))
+		}
+	}
+	return nil
+}
+
+// packageManagerHint adds the fix for well-known package manager setup
+// errors to err.
+func packageManagerHint(pm string, err error) error {
+	if pm == StrategyPNPM && strings.Contains(err.Error(), "ERR_PNPM_NO_GLOBAL_BIN_DIR") {
+		return fmt.Errorf("%w (run \"pnpm setup\" and restart the installer)", err)
+	}
+	return err
+}
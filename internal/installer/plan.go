@@ -0,0 +1,250 @@
+package installer
+
+import (
+	"fmt"
+	"runtime"
+
+	"claude-code-installer/internal/detector"
+	"claude-code-installer/internal/pathutil"
+)
+
+// ComponentPlan describes what InstallAll would do for a single component.
+type ComponentPlan struct {
+	Component        string `json:"component"`
+	Installed        bool   `json:"installed"`
+	InstalledVersion string `json:"installedVersion,omitempty"`
+	// Strategy is the first strategy that will be tried; empty when installed.
+	Strategy string `json:"strategy,omitempty"`
+	// Version and URL identify what will be fetched when known up front.
+	Version string `json:"version,omitempty"`
+	URL     string `json:"url,omitempty"`
+	// EstimatedSize is the expected download size in bytes, or 0 when unknown.
+	EstimatedSize     int64 `json:"estimatedSize"`
+	RequiresElevation bool  `json:"requiresElevation"`
+	// Error is set when part of the plan could not be resolved.
+	Error string `json:"error,omitempty"`
+}
+
+// InstallPlan is the resolved plan for InstallAll.
+type InstallPlan struct {
+	Components []ComponentPlan `json:"components"`
+	// TotalDownloadSize is the sum of known component download sizes in bytes.
+	TotalDownloadSize int64 `json:"totalDownloadSize"`
+	// EstimatedSteps is the number of components that will be installed.
+	EstimatedSteps int `json:"estimatedSteps"`
+	// PathWarning is set when the user PATH cannot be modified, so installed
+	// components may need to be added to PATH by hand.
+	PathWarning string `json:"pathWarning,omitempty"`
+}
+
+// PlanInstallAll resolves, without installing anything, what InstallAll would
+// do for each component: whether it is already installed, the strategy that
+// will be tried first, the version and URL to fetch, the estimated download
+// size, and whether administrator rights are needed. Resolution failures are
+// reported per component; an error is returned only if planning is cancelled.
+func (i *Installer) PlanInstallAll() (*InstallPlan, error) {
+	plan := &InstallPlan{
+		Components: []ComponentPlan{
+			i.planNodeJS(),
+			i.planGit(),
+			i.planClaudeCode(),
+		},
+	}
+	if err := i.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("planning cancelled: %w", err)
+	}
+
+	plan.computeTotals()
+	if plan.EstimatedSteps > 0 {
+		if err := pathutil.CheckUserPathWritable(); err != nil {
+			plan.PathWarning = userPathWarning(err)
+		}
+	}
+	return plan, nil
+}
+
+// userPathWarning explains what to do when the user PATH cannot be modified.
+func userPathWarning(err error) string {
+	if runtime.GOOS == "windows" {
+		return fmt.Sprintf("The user PATH cannot be modified (%v). Run the installer as administrator so the system PATH is used, or add the installed programs to PATH manually afterwards.", err)
+	}
+	return fmt.Sprintf("Your shell profile cannot be modified (%v). Add the installed programs to PATH manually afterwards.", err)
+}
+
+// ForComponents returns the plan restricted to components, in their order.
+// Components missing from the plan are ignored.
+func (p *InstallPlan) ForComponents(components []string) *InstallPlan {
+	result := &InstallPlan{PathWarning: p.PathWarning}
+	for _, name := range components {
+		for _, component := range p.Components {
+			if component.Component == name {
+				result.Components = append(result.Components, component)
+			}
+		}
+	}
+	result.computeTotals()
+	return result
+}
+
+// computeTotals fills in the download size and step count from the components.
+func (p *InstallPlan) computeTotals() {
+	p.TotalDownloadSize, p.EstimatedSteps = 0, 0
+	for _, component := range p.Components {
+		p.TotalDownloadSize += component.EstimatedSize
+		if !component.Installed {
+			p.EstimatedSteps++
+		}
+	}
+}
+
+// planNodeJS resolves the Node.js part of the install plan.
+func (i *Installer) planNodeJS() ComponentPlan {
+	plan := ComponentPlan{Component: "nodejs"}
+	if status := detector.CheckNodeJS(); status.Installed {
+		plan.Installed = true
+		plan.InstalledVersion = status.Version
+		return plan
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		plan.Version = nodeLTSVersion
+		switch i.firstAvailableBackend() {
+		case StrategyWinget:
+			plan.Strategy = StrategyWinget
+			// winget installs the machine-wide MSI
+			plan.RequiresElevation = true
+			return plan
+		case StrategyScoop:
+			// Scoop installs per-user
+			plan.Strategy = StrategyScoop
+			return plan
+		case "":
+			plan.Error = errNoBackendAvailable.Error()
+			return plan
+		}
+		plan.Strategy = i.nodeDownloadStrategy()
+		filename := nodeMSIFilename()
+		if plan.Strategy == StrategyZip {
+			filename = nodeZipFilename()
+		}
+		plan.RequiresElevation = plan.Strategy == StrategyMSI
+		plan.URL = i.nodeDownloadURL(filename)
+		i.planDownloadSize(&plan)
+	default:
+		planPackageManager(&plan)
+	}
+	return plan
+}
+
+// planGit resolves the Git part of the install plan.
+func (i *Installer) planGit() ComponentPlan {
+	plan := ComponentPlan{Component: "git"}
+	if status := detector.CheckGit(); status.Installed {
+		plan.Installed = true
+		plan.InstalledVersion = status.Version
+		return plan
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		backend := i.firstAvailableBackend()
+		switch backend {
+		case StrategyScoop:
+			// Scoop installs per-user
+			plan.Strategy = StrategyScoop
+			return plan
+		case "":
+			plan.Error = errNoBackendAvailable.Error()
+			return plan
+		}
+		// Both winget and the Git for Windows installer write to Program Files
+		plan.RequiresElevation = true
+		if backend == StrategyWinget {
+			plan.Strategy = StrategyWinget
+			return plan
+		}
+		plan.Strategy = StrategyDownload
+		// The same cached release the install resolves its download from
+		release, err := i.getLatestGitRelease()
+		if err != nil {
+			plan.Error = err.Error()
+			return plan
+		}
+		plan.Version = gitVersionFromTag(release.TagName)
+		asset, err := i.gitInstallerAsset(release)
+		if err != nil {
+			plan.Error = err.Error()
+			return plan
+		}
+		plan.URL = asset.BrowserDownloadURL
+		plan.EstimatedSize = asset.Size
+	case "darwin":
+		if !isBrewAvailable() {
+			plan.Strategy = StrategyXcodeCLT
+			return plan
+		}
+		plan.Strategy = StrategyBrew
+	default:
+		planPackageManager(&plan)
+	}
+	return plan
+}
+
+// planClaudeCode resolves the Claude Code part of the install plan.
+func (i *Installer) planClaudeCode() ComponentPlan {
+	plan := ComponentPlan{Component: "claudecode"}
+	if status := detector.CheckClaudeCode(); status.Installed {
+		plan.Installed = true
+		plan.InstalledVersion = status.Version
+		return plan
+	}
+
+	plan.Strategy = i.claudePackageManager()
+
+	// npm may only become available once Node.js is installed
+	npmPath, err := i.findNpm()
+	if err != nil {
+		return plan
+	}
+	if version, err := i.latestClaudeVersion(npmPath); err == nil {
+		plan.Version = version
+	} else {
+		plan.Error = err.Error()
+	}
+	return plan
+}
+
+// planDownloadSize fills in the estimated size of plan.URL.
+func (i *Installer) planDownloadSize(plan *ComponentPlan) {
+	size, err := cachedMetadata(releaseMetadata, "size:"+plan.URL, func() (int64, error) {
+		return i.fetchContentLength(plan.URL)
+	})
+	if err != nil {
+		plan.Error = err.Error()
+		return
+	}
+	if size > 0 {
+		plan.EstimatedSize = size
+	}
+}
+
+// planPackageManager fills in the strategy for platforms that install through
+// a system package manager.
+func planPackageManager(plan *ComponentPlan) {
+	switch runtime.GOOS {
+	case "darwin":
+		plan.Strategy = StrategyBrew
+	case "linux":
+		pm, err := findLinuxPackageManager()
+		if err != nil {
+			plan.Error = err.Error()
+			return
+		}
+		plan.Strategy = pm.strategy
+		// Distribution package managers always run as root
+		plan.RequiresElevation = true
+	default:
+		plan.Error = fmt.Sprintf("automatic installation is not supported on %s", runtime.GOOS)
+	}
+}
@@ -0,0 +1,226 @@
+package installer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrPreviousInstallRunning is returned when an installer, msiexec or npm
+// started by an earlier session of the app is still running, so starting
+// another install would conflict with it.
+var ErrPreviousInstallRunning = errors.New("an installation started by a previous session is still running")
+
+// maxRecordedProcessAge bounds how long a recorded process is considered; an
+// older entry is assumed to belong to a process whose PID has been reused.
+const maxRecordedProcessAge = 6 * time.Hour
+
+// RunningProcess is a child process recorded in the process file while it runs.
+type RunningProcess struct {
+	PID int `json:"pid"`
+	// Name is the executable's file name, such as "msiexec.exe" or "npm.cmd".
+	Name string `json:"name"`
+	// Image and Created are the full path of the running image and the
+	// process creation time, recorded on Windows to recognize a reused PID.
+	Image   string    `json:"image,omitempty"`
+	Created time.Time `json:"created,omitempty"`
+	// SessionPID is the PID of the app that started the process.
+	SessionPID int       `json:"sessionPid"`
+	Started    time.Time `json:"started"`
+}
+
+// processFileMu serializes updates to the process file between installers.
+var processFileMu sync.Mutex
+
+// DefaultProcessFilePath returns the process file location in the user's
+// configuration directory.
+func DefaultProcessFilePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, "claude-code-installer", "processes.json"), nil
+}
+
+// SetProcessFile configures a file where the installer records the child
+// processes it runs, so a later session can tell whether an installer left
+// running by a crash or force-close is still going. Entries are removed as
+// the processes exit, and the file once none remain. An empty path disables
+// recording and the check.
+func (i *Installer) SetProcessFile(path string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.processFile = path
+}
+
+// PreviousSessionProcesses returns the processes recorded in the process file
+// at path by another session of the app that are still running. Entries for
+// processes that have exited are removed from the file.
+func PreviousSessionProcesses(path string) ([]RunningProcess, error) {
+	processFileMu.Lock()
+	defer processFileMu.Unlock()
+
+	entries, err := readProcessFile(path)
+	if err != nil {
+		return nil, err
+	}
+	session := os.Getpid()
+	var kept, running []RunningProcess
+	for _, entry := range entries {
+		switch {
+		case entry.SessionPID == session:
+			kept = append(kept, entry)
+		case time.Since(entry.Started) < maxRecordedProcessAge && processRunning(entry):
+			kept = append(kept, entry)
+			running = append(running, entry)
+		}
+	}
+	if len(kept) != len(entries) {
+		if err := writeProcessFile(path, kept); err != nil {
+			return running, err
+		}
+	}
+	return running, nil
+}
+
+// readProcessFile returns the entries in the process file, or none when it
+// does not exist.
+func readProcessFile(path string) ([]RunningProcess, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process file: %w", err)
+	}
+	var entries []RunningProcess
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse process file: %w", err)
+	}
+	return entries, nil
+}
+
+// writeProcessFile replaces the process file with entries, removing it when
+// there are none.
+func writeProcessFile(path string, entries []RunningProcess) error {
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove process file: %w", err)
+		}
+		return nil
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode process file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create process file directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write process file: %w", err)
+	}
+	return nil
+}
+
+// recordedProcess reports whether a command is worth recording in the
+// process file: msiexec, a downloaded installer (saved as *-installer.exe),
+// or a package manager installing packages. Quick queries such as
+// `npm root -g` exit long before a later session could find them running.
+func recordedProcess(name string, args []string) bool {
+	// Either separator, so Windows paths are recognized on every platform
+	base := strings.ToLower(name[strings.LastIndexAny(name, `/\`)+1:])
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	switch base {
+	case "msiexec":
+		return true
+	case "npm", "pnpm":
+		return len(args) > 0 && (args[0] == "install" || args[0] == "i" || args[0] == "add")
+	case "yarn":
+		return len(args) > 1 && args[0] == "global" && args[1] == "add"
+	}
+	return strings.HasSuffix(base, "-installer")
+}
+
+// runProcess starts cmd, records it in the process file while it runs if it
+// is an installation (see recordedProcess), and waits for it to exit.
+// Recording is best effort and never fails the command.
+func (i *Installer) runProcess(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	path := i.processFile
+	i.mu.Unlock()
+	if path == "" || !recordedProcess(cmd.Path, cmd.Args[1:]) {
+		return cmd.Wait()
+	}
+
+	entry := RunningProcess{
+		PID:        cmd.Process.Pid,
+		Name:       filepath.Base(cmd.Path),
+		SessionPID: os.Getpid(),
+		Started:    time.Now(),
+	}
+	entry.Image, entry.Created, _ = processIdentity(entry.PID)
+	updateProcessFile(path, func(entries []RunningProcess) []RunningProcess {
+		return append(entries, entry)
+	})
+	defer updateProcessFile(path, func(entries []RunningProcess) []RunningProcess {
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.PID != entry.PID || e.SessionPID != entry.SessionPID {
+				kept = append(kept, e)
+			}
+		}
+		return kept
+	})
+	return cmd.Wait()
+}
+
+// updateProcessFile rewrites the process file with the entries returned by
+// update. Failures are ignored: a missing entry only weakens the check made
+// by a later session.
+func updateProcessFile(path string, update func([]RunningProcess) []RunningProcess) {
+	processFileMu.Lock()
+	defer processFileMu.Unlock()
+
+	entries, err := readProcessFile(path)
+	if err != nil {
+		// An unreadable file is replaced rather than left to block every install
+		entries = nil
+	}
+	_ = writeProcessFile(path, update(entries))
+}
+
+// ensureNoPreviousInstall refuses to start an operation while a process
+// recorded by a previous session is still running.
+func (i *Installer) ensureNoPreviousInstall(stepName string) error {
+	i.mu.Lock()
+	path := i.processFile
+	i.mu.Unlock()
+	if path == "" {
+		return nil
+	}
+
+	running, err := PreviousSessionProcesses(path)
+	if len(running) == 0 {
+		if err != nil {
+			i.emitDetail(stepName, "could not check for a previous installation: %s", summarizeError(err))
+		}
+		return nil
+	}
+
+	names := make([]string, len(running))
+	for idx, p := range running {
+		names[idx] = fmt.Sprintf("%s (PID %d)", p.Name, p.PID)
+	}
+	i.emitProgress(stepName, "error", "An installation started before the installer was last closed is still running. Wait for it to finish and try again.", 0)
+	return fmt.Errorf("%w: %s", ErrPreviousInstallRunning, strings.Join(names, ", "))
+}
@@ -0,0 +1,110 @@
+package installer
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	// progressChannelBuffer is the capacity of the channel returned by
+	// NewInstallerWithChannel.
+	progressChannelBuffer = 64
+	// maxPendingProgress bounds events queued behind a slow consumer.
+	// Intermediate "installing" events beyond it are dropped.
+	maxPendingProgress = 1024
+)
+
+// NewInstallerWithChannel creates an Installer that delivers progress on a
+// buffered channel instead of a callback, so a slow consumer never holds up
+// downloads or installs. When the consumer falls behind, consecutive
+// "installing" events for the same step are coalesced into the latest one;
+// events with any other status are always delivered, in order.
+//
+// The channel is closed once ctx is done, after queued events that still fit
+// in its buffer have been sent.
+func NewInstallerWithChannel(ctx context.Context) (*Installer, <-chan InstallProgress) {
+	out := make(chan InstallProgress, progressChannelBuffer)
+	queue := &progressQueue{notify: make(chan struct{}, 1)}
+	go queue.forward(ctx, out)
+	return NewInstaller(ctx, queue.push), out
+}
+
+// progressQueue decouples progress producers from a channel consumer.
+type progressQueue struct {
+	mu      sync.Mutex
+	pending []InstallProgress
+	// notify wakes the forwarder; it holds at most one signal.
+	notify chan struct{}
+}
+
+// push queues progress without blocking.
+func (q *progressQueue) push(progress InstallProgress) {
+	q.mu.Lock()
+	q.pending = coalesceProgress(q.pending, progress)
+	q.mu.Unlock()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// coalesceProgress appends progress to pending, replacing the last event
+// instead when both are "installing" events for the same step. Intermediate
+// events are dropped once pending reaches maxPendingProgress.
+func coalesceProgress(pending []InstallProgress, progress InstallProgress) []InstallProgress {
+	if progress.Status == "installing" {
+		if n := len(pending); n > 0 && pending[n-1].Status == "installing" && pending[n-1].Step == progress.Step {
+			pending[n-1] = progress
+			return pending
+		}
+		if len(pending) >= maxPendingProgress {
+			return pending
+		}
+	}
+	return append(pending, progress)
+}
+
+// take removes and returns all queued events.
+func (q *progressQueue) take() []InstallProgress {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pending := q.pending
+	q.pending = nil
+	return pending
+}
+
+// forward sends queued events to out until ctx is done, then closes out.
+func (q *progressQueue) forward(ctx context.Context, out chan<- InstallProgress) {
+	defer close(out)
+
+	// flush sends what fits in the buffer without waiting for the consumer
+	flush := func(events []InstallProgress) {
+		for _, progress := range events {
+			select {
+			case out <- progress:
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			flush(q.take())
+			return
+		case <-q.notify:
+		}
+
+		events := q.take()
+		for idx, progress := range events {
+			select {
+			case out <- progress:
+			case <-ctx.Done():
+				flush(append(events[idx:], q.take()...))
+				return
+			}
+		}
+	}
+}
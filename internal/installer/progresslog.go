@@ -0,0 +1,84 @@
+package installer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxReplayGap caps the pause between replayed events so long downloads or
+// installer runs do not stall a replay.
+const maxReplayGap = 2 * time.Second
+
+// RecordedProgress is a progress event as written by a ProgressRecorder.
+type RecordedProgress struct {
+	Time time.Time `json:"time"`
+	InstallProgress
+}
+
+// ProgressRecorder writes progress events as newline-delimited JSON with
+// timestamps, so an install's event stream can be captured on a user's
+// machine and replayed with ReplayProgress.
+type ProgressRecorder struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewProgressRecorder creates a recorder that writes to w.
+func NewProgressRecorder(w io.Writer) *ProgressRecorder {
+	return &ProgressRecorder{w: w}
+}
+
+// Record writes a single progress event.
+func (r *ProgressRecorder) Record(progress InstallProgress) error {
+	data, err := json.Marshal(RecordedProgress{Time: time.Now(), InstallProgress: progress})
+	if err != nil {
+		return fmt.Errorf("failed to encode progress event: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write progress event: %w", err)
+	}
+	return nil
+}
+
+// ReplayProgress reads events recorded by a ProgressRecorder from file and
+// passes them to emit in order, keeping the original spacing between events
+// (capped at maxReplayGap).
+func ReplayProgress(file string, emit func(InstallProgress)) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open progress log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	var previous time.Time
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event RecordedProgress
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("invalid progress event on line %d: %w", line, err)
+		}
+
+		if !previous.IsZero() {
+			if gap := event.Time.Sub(previous); gap > 0 {
+				time.Sleep(min(gap, maxReplayGap))
+			}
+		}
+		previous = event.Time
+		emit(event.InstallProgress)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read progress log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Registry signature outcomes reported in SignatureResult.Status.
+const (
+	// SignatureVerified means the registry signature of the package is valid.
+	SignatureVerified = "verified"
+	// SignatureInvalid means the package's registry signature does not match.
+	SignatureInvalid = "invalid"
+	// SignatureMissing means the registry published no signature for the package.
+	SignatureMissing = "missing"
+	// SignatureUnavailable means the check could not run, for example
+	// because npm is too old to support `npm audit signatures`.
+	SignatureUnavailable = "unavailable"
+)
+
+// SignatureResult is the outcome of checking the installed Claude Code
+// package's npm registry signature.
+type SignatureResult struct {
+	Package string `json:"package"`
+	Version string `json:"version"`
+	Status  string `json:"status"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// auditSignaturesReport is the JSON printed by `npm audit signatures --json`.
+type auditSignaturesReport struct {
+	Invalid []auditSignaturesEntry `json:"invalid"`
+	Missing []auditSignaturesEntry `json:"missing"`
+}
+
+type auditSignaturesEntry struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// registrySignatures caches registry signature audits per npm and version.
+// A published version's tarball and signature never change, so the result
+// is reused for as long as the process runs.
+var registrySignatures = newMetadataCache(24 * time.Hour)
+
+// VerifyClaudeSignature checks that the installed @anthropic-ai/claude-code
+// package is the one the npm registry signed.
+//
+// The integrity npm recorded for the installed package in the global
+// node_modules lockfile must match the integrity the registry publishes for
+// that version, and the registry signature over that integrity is checked
+// with `npm audit signatures`. A check that cannot run is reported as
+// SignatureUnavailable rather than an error; an error is returned only when
+// Claude Code or npm is missing.
+func (i *Installer) VerifyClaudeSignature() (*SignatureResult, error) {
+	stepName := "claudecode"
+
+	output, err := i.getInstalledClaudeVersion()
+	if err != nil {
+		return nil, fmt.Errorf("Claude Code is not installed: %w", err)
+	}
+	version := claudeVersionNumber(output)
+
+	npmPath, err := i.findNpm()
+	if err != nil {
+		return nil, fmt.Errorf("npm is not available: %w", err)
+	}
+
+	result := &SignatureResult{Package: claudeCodePackage, Version: version}
+	unavailable := func(format string, args ...interface{}) (*SignatureResult, error) {
+		result.Status = SignatureUnavailable
+		result.Detail = fmt.Sprintf(format, args...)
+		i.emitDetail(stepName, "registry signature check unavailable: %s", result.Detail)
+		return result, nil
+	}
+
+	i.emitDetail(stepName, "checking npm registry signature of %s@%s", claudeCodePackage, version)
+
+	prefixArgs, _ := npmGlobalArgs()
+	installed, err := i.installedClaudeIntegrity(npmPath, prefixArgs, version)
+	if err != nil {
+		return unavailable("%v", err)
+	}
+	published, err := i.publishedIntegrity(npmPath, version)
+	if err != nil {
+		return unavailable("%v", err)
+	}
+	if installed != published {
+		result.Status = SignatureInvalid
+		result.Detail = "the installed package does not match the tarball the registry published for this version"
+		i.emitDetail(stepName, "registry signature of %s@%s: %s (%s)", claudeCodePackage, version, result.Status, result.Detail)
+		return result, nil
+	}
+
+	status, err := cachedMetadata(registrySignatures, npmPath+":"+version, func() (string, error) {
+		return i.auditRegistrySignature(npmPath, version)
+	})
+	if err != nil {
+		return unavailable("%v", err)
+	}
+
+	result.Status = status
+	i.emitDetail(stepName, "registry signature of %s@%s: %s", claudeCodePackage, version, result.Status)
+	return result, nil
+}
+
+// installedClaudeIntegrity returns the integrity npm recorded for the
+// globally installed Claude Code package in node_modules/.package-lock.json.
+func (i *Installer) installedClaudeIntegrity(npmPath string, prefixArgs []string, version string) (string, error) {
+	root, err := i.runCommand(npmPath, append([]string{"root", "-g"}, prefixArgs...)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the global node_modules: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(strings.TrimSpace(root), ".package-lock.json"))
+	if err != nil {
+		return "", fmt.Errorf("npm recorded no integrity for the installed package (npm 7 or newer is required): %v", err)
+	}
+	return parseHiddenLockfileIntegrity(data, claudeCodePackage, version)
+}
+
+// hiddenLockfile is the part of node_modules/.package-lock.json that records
+// what npm installed.
+type hiddenLockfile struct {
+	Packages map[string]struct {
+		Version   string `json:"version"`
+		Integrity string `json:"integrity"`
+	} `json:"packages"`
+}
+
+// parseHiddenLockfileIntegrity returns the integrity of pkg at version in a
+// global node_modules/.package-lock.json.
+func parseHiddenLockfileIntegrity(data []byte, pkg, version string) (string, error) {
+	var lockfile hiddenLockfile
+	if err := json.Unmarshal(data, &lockfile); err != nil {
+		return "", fmt.Errorf("failed to parse node_modules/.package-lock.json: %w", err)
+	}
+	entry, ok := lockfile.Packages["node_modules/"+pkg]
+	if !ok || entry.Integrity == "" {
+		return "", fmt.Errorf("npm recorded no integrity for %s", pkg)
+	}
+	if entry.Version != version {
+		return "", fmt.Errorf("npm recorded %s@%s, but %s is installed", pkg, entry.Version, version)
+	}
+	return entry.Integrity, nil
+}
+
+// publishedIntegrity returns the integrity the registry publishes for
+// Claude Code at version.
+func (i *Installer) publishedIntegrity(npmPath, version string) (string, error) {
+	output, err := i.runCommand(npmPath, "view", claudeCodePackage+"@"+version, "version", "dist", "--json")
+	if err != nil {
+		return "", fmt.Errorf("failed to look up %s@%s: %v", claudeCodePackage, version, err)
+	}
+	info, err := parseNpmViewDist(output)
+	if err != nil {
+		return "", err
+	}
+	if info.Dist.Integrity == "" {
+		return "", fmt.Errorf("the registry published no integrity for %s@%s", claudeCodePackage, version)
+	}
+	return info.Dist.Integrity, nil
+}
+
+// auditRegistrySignature checks the registry signature of Claude Code at
+// version with `npm audit signatures`. npm cannot audit global packages, so
+// the version is installed into a throwaway project (without running install
+// scripts) and audited there; the signature covers the version's integrity,
+// which the caller has matched against the installed package. An error means
+// the check could not run.
+func (i *Installer) auditRegistrySignature(npmPath, version string) (string, error) {
+	dir, err := os.MkdirTemp("", "claude-signature-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := fmt.Sprintf(`{"name":"claude-signature-check","private":true,"dependencies":{%q:%q}}`,
+		claudeCodePackage, version)
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write package.json: %v", err)
+	}
+
+	if _, err := i.runCommand(npmPath, "install", "--prefix", dir,
+		"--ignore-scripts", "--no-audit", "--no-fund", "--loglevel", "error"); err != nil {
+		return "", fmt.Errorf("failed to fetch %s@%s: %v", claudeCodePackage, version, err)
+	}
+
+	// npm exits non-zero when any signature is invalid or missing, so the
+	// report is parsed regardless of the exit status
+	auditOutput, auditErr := i.runCommand(npmPath, "audit", "signatures", "--prefix", dir, "--json")
+	report, parseErr := parseAuditSignatures(auditOutput)
+	if parseErr != nil {
+		if auditErr != nil {
+			return "", fmt.Errorf("npm audit signatures failed (npm 8.15 or newer is required): %v", auditErr)
+		}
+		return "", parseErr
+	}
+	return signatureStatus(report, claudeCodePackage), nil
+}
+
+// parseAuditSignatures extracts the JSON report from `npm audit signatures
+// --json` output, skipping any warnings npm printed around it.
+func parseAuditSignatures(output string) (*auditSignaturesReport, error) {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("npm audit signatures produced no JSON report")
+	}
+
+	data := []byte(output[start : end+1])
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse npm audit signatures report: %w", err)
+	}
+	// npm versions without the signatures subcommand run a regular audit,
+	// whose report must not be mistaken for a clean signature check
+	_, hasInvalid := keys["invalid"]
+	_, hasMissing := keys["missing"]
+	if !hasInvalid && !hasMissing {
+		return nil, fmt.Errorf("npm did not produce a signature report")
+	}
+
+	var report auditSignaturesReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse npm audit signatures report: %w", err)
+	}
+	return &report, nil
+}
+
+// signatureStatus returns the status of pkg in report. Problems with other
+// packages in the tree do not affect the result.
+func signatureStatus(report *auditSignaturesReport, pkg string) string {
+	for _, entry := range report.Invalid {
+		if entry.Name == pkg {
+			return SignatureInvalid
+		}
+	}
+	for _, entry := range report.Missing {
+		if entry.Name == pkg {
+			return SignatureMissing
+		}
+	}
+	return SignatureVerified
+}
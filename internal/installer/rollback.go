@@ -0,0 +1,148 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+
+	"claude-code-installer/internal/history"
+	"claude-code-installer/internal/version"
+)
+
+// ErrNoRollbackVersion is returned by RollbackClaudeCode when no earlier
+// Claude Code version has been recorded.
+var ErrNoRollbackVersion = errors.New("no previous Claude Code version recorded")
+
+// SetVersionHistoryPath records the version replaced by each Claude Code
+// update in the history file at path, which RollbackClaudeCode reads. An
+// empty path disables recording.
+func (i *Installer) SetVersionHistoryPath(path string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.versionHistoryPath = path
+}
+
+// historyPath returns the configured version history path.
+func (i *Installer) historyPath() string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.versionHistoryPath
+}
+
+// ClaudeVersionHistory returns the recorded Claude Code version changes,
+// oldest first.
+func (i *Installer) ClaudeVersionHistory() ([]history.VersionEntry, error) {
+	path := i.historyPath()
+	if path == "" {
+		return nil, nil
+	}
+	return history.LoadVersions(path, ComponentClaudeCode)
+}
+
+// installedClaudeVersion returns the installed Claude Code version number,
+// or "" when it cannot be determined.
+func (i *Installer) installedClaudeVersion() string {
+	output, err := i.getInstalledClaudeVersion()
+	if err != nil {
+		return ""
+	}
+	return claudeVersionNumber(output)
+}
+
+// recordClaudeVersion appends a Claude Code version change to the version
+// history. Failures are reported as details only: losing the record must not
+// fail an update that already succeeded.
+func (i *Installer) recordClaudeVersion(stepName, previous, installed string, rollback bool) {
+	path := i.historyPath()
+	if path == "" || previous == "" || previous == installed {
+		return
+	}
+	// Only record versions a later rollback can safely reinstall
+	if _, err := version.Validate(previous); err != nil {
+		i.emitDetail(stepName, "not recording version change: previous %v", err)
+		return
+	}
+	err := history.AppendVersion(path, history.VersionEntry{
+		Component: ComponentClaudeCode,
+		Previous:  previous,
+		Installed: installed,
+		Rollback:  rollback,
+	})
+	if err != nil {
+		i.emitDetail(stepName, "failed to record version history: %v", err)
+		return
+	}
+	i.emitDetail(stepName, "recorded Claude Code version change %s -> %s", previous, installed)
+}
+
+// RollbackClaudeCode reinstalls the Claude Code version that the most recent
+// update replaced, using npm, and verifies that it is the version now
+// installed. Calling it again rolls back further through the recorded
+// updates.
+func (i *Installer) RollbackClaudeCode() error {
+	return i.withTelemetry("claudeCodeRollback", i.rollbackClaudeCode)
+}
+
+// rollbackTarget returns the version to roll back to from current. The
+// history file is user-writable, so the version is validated: a tag, URL or
+// path from it must never reach npm as a package spec.
+func (i *Installer) rollbackTarget(current string) (string, error) {
+	entries, err := i.ClaudeVersionHistory()
+	if err != nil {
+		return "", fmt.Errorf("failed to read version history: %w", err)
+	}
+	target, ok := history.RollbackTarget(entries, current)
+	if !ok {
+		return "", ErrNoRollbackVersion
+	}
+	target, err = version.Validate(target)
+	if err != nil {
+		return "", fmt.Errorf("version history is corrupt: %w", err)
+	}
+	return target, nil
+}
+
+// rollbackClaudeCode performs the rollback; see RollbackClaudeCode.
+func (i *Installer) rollbackClaudeCode() error {
+	stepName := "claudeCodeRollback"
+
+	if err := i.ensureNoPreviousInstall(stepName); err != nil {
+		return err
+	}
+	if err := i.ensureClaudeNotRunning(stepName); err != nil {
+		return err
+	}
+
+	current := i.installedClaudeVersion()
+	target, err := i.rollbackTarget(current)
+	if err != nil {
+		if !errors.Is(err, ErrNoRollbackVersion) {
+			i.emitProgress(stepName, "error", fmt.Sprintf("Failed to roll back Claude Code: %v", err), 0)
+		}
+		return err
+	}
+
+	npmPath, err := i.findNpm()
+	if err != nil {
+		return fmt.Errorf("npm is required to roll back Claude Code: %w", err)
+	}
+	prefixArgs, _ := npmGlobalArgs()
+
+	i.setStrategy(stepName, StrategyNPM)
+	i.emitProgress(stepName, "installing", fmt.Sprintf("Reinstalling Claude Code %s...", target), 20)
+	args := append([]string{"install", "-g", claudeCodePackage + "@" + target}, prefixArgs...)
+	if _, err := i.runCommand(npmPath, args...); err != nil {
+		i.emitProgress(stepName, "error", fmt.Sprintf("Failed to roll back Claude Code: %v", err), 0)
+		return fmt.Errorf("failed to roll back Claude Code to %s: %w", target, err)
+	}
+
+	i.emitProgress(stepName, "installing", "Verifying rollback...", 80)
+	installed := i.installedClaudeVersion()
+	if installed != target {
+		i.emitProgress(stepName, "error", "Rollback completed but verification failed", 0)
+		return fmt.Errorf("rollback verification failed: expected Claude Code %s, found %q", target, installed)
+	}
+
+	i.recordClaudeVersion(stepName, current, installed, true)
+	i.emitProgress(stepName, "completed", fmt.Sprintf("Claude Code rolled back to %s", target), 100)
+	return nil
+}
@@ -0,0 +1,53 @@
+package installer
+
+import (
+	"fmt"
+
+	"claude-code-installer/internal/detector"
+	"claude-code-installer/internal/pathutil"
+)
+
+const (
+	// scoopNodePackage is the Scoop package for the Node.js LTS release.
+	scoopNodePackage = "nodejs-lts"
+	// scoopGitPackage is the Scoop package for Git.
+	scoopGitPackage = "git"
+)
+
+// findScoop locates the Scoop executable shim.
+func findScoop() (string, error) {
+	if scoopPath := detector.FindScoop(); scoopPath != "" {
+		return scoopPath, nil
+	}
+	return "", fmt.Errorf("scoop not found")
+}
+
+// isScoopAvailable checks if Scoop is available on the system.
+func isScoopAvailable() bool {
+	_, err := findScoop()
+	return err == nil
+}
+
+// installViaScoop installs a Scoop package and makes Scoop's shims directory
+// visible on the user PATH and to this process.
+func (i *Installer) installViaScoop(stepName, pkg string) error {
+	scoopPath, err := findScoop()
+	if err != nil {
+		return err
+	}
+
+	if _, err := i.runCommand(scoopPath, "install", pkg); err != nil {
+		return fmt.Errorf("scoop install %s failed: %w", pkg, err)
+	}
+
+	shims := detector.ScoopShimsDir()
+	if shims == "" {
+		return nil
+	}
+	if err := pathutil.AddToPath(shims); err != nil {
+		// Non-fatal: Scoop normally adds its shims itself
+		i.emitDetail(stepName, "could not add %s to PATH: %s", shims, summarizeError(err))
+	}
+	prependToProcessPath(shims)
+	return nil
+}
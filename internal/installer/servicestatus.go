@@ -0,0 +1,151 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"claude-code-installer/internal/httputil"
+)
+
+// ErrServiceOutage is wrapped around a failure when the upstream service's
+// status page reports a major outage at the time of the failure.
+var ErrServiceOutage = errors.New("upstream service outage")
+
+// statusCheckTimeout bounds the status page query so it doesn't noticeably
+// delay reporting the original failure.
+const statusCheckTimeout = 5 * time.Second
+
+// Upstream services with a public Statuspage status endpoint.
+const (
+	serviceGitHub = "GitHub"
+	serviceNpm    = "npm"
+)
+
+// serviceStatusURLs maps each upstream service to its status endpoint.
+var serviceStatusURLs = map[string]string{
+	serviceGitHub: "https://www.githubstatus.com/api/v2/status.json",
+	serviceNpm:    "https://status.npmjs.org/api/v2/status.json",
+}
+
+// ServiceStatus is the overall status an upstream service reports.
+type ServiceStatus struct {
+	Service string
+	// Indicator is "none", "minor", "major" or "critical".
+	Indicator   string
+	Description string
+}
+
+// Outage reports whether the service considers itself majorly degraded.
+func (s ServiceStatus) Outage() bool {
+	return s.Indicator == "major" || s.Indicator == "critical"
+}
+
+// SetServiceStatusCheck enables querying the GitHub and npm status pages when
+// downloads or npm installs from them keep failing to connect, so an upstream
+// outage is reported as ErrServiceOutage instead of a generic network failure.
+func (i *Installer) SetServiceStatusCheck(enabled bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.checkServiceStatus = enabled
+}
+
+// serviceForURL returns the upstream service rawURL belongs to, or "" when it
+// has no known status page.
+func serviceForURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := parsed.Hostname()
+	for _, trusted := range httputil.GitHubTrustedHosts() {
+		if host == trusted {
+			return serviceGitHub
+		}
+	}
+	if host == "registry.npmjs.org" {
+		return serviceNpm
+	}
+	return ""
+}
+
+// isNetworkFailure reports whether err is a network-class failure, the only
+// kind an upstream outage explains.
+func isNetworkFailure(err error) bool {
+	return errorCode(err) == ErrorCodeNetwork
+}
+
+// annotateOutage checks service's status page after err, a network failure
+// talking to it that retries did not resolve. During a major outage err is wrapped in
+// ErrServiceOutage; lesser incidents are only noted as a detail. The original
+// error is returned when the check is disabled or the status is unavailable.
+func (i *Installer) annotateOutage(stepName, service string, err error) error {
+	i.mu.Lock()
+	enabled := i.checkServiceStatus
+	i.mu.Unlock()
+	if err == nil || !enabled || service == "" || i.ctx.Err() != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(i.ctx, statusCheckTimeout)
+	defer cancel()
+	client := &http.Client{
+		CheckRedirect: httputil.NewTrustedCheckRedirect(httputil.StatusPageTrustedHosts()),
+		Transport:     i.transport(),
+	}
+	status, statusErr := fetchServiceStatus(ctx, client, service, serviceStatusURLs[service])
+	if statusErr != nil {
+		i.emitDetail(stepName, "could not check %s status: %v", service, statusErr)
+		return err
+	}
+
+	i.emitDetail(stepName, "%s status: %s (%s)", service, status.Description, status.Indicator)
+	if !status.Outage() {
+		return err
+	}
+	return fmt.Errorf("%w: %s is reporting a %s outage (%s); try again later: %w",
+		ErrServiceOutage, service, status.Indicator, status.Description, err)
+}
+
+// fetchServiceStatus queries a Statuspage v2 status.json endpoint.
+func fetchServiceStatus(ctx context.Context, client *http.Client, service, statusURL string) (ServiceStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", statusURL, nil)
+	if err != nil {
+		return ServiceStatus{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "claude-code-installer")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ServiceStatus{}, fmt.Errorf("failed to fetch %s: %w", statusURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ServiceStatus{}, fmt.Errorf("HTTP %d fetching %s", resp.StatusCode, statusURL)
+	}
+
+	var page struct {
+		Status struct {
+			Indicator   string `json:"indicator"`
+			Description string `json:"description"`
+		} `json:"status"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxTextResponseSize)).Decode(&page); err != nil {
+		return ServiceStatus{}, fmt.Errorf("failed to parse status page: %w", err)
+	}
+	if page.Status.Indicator == "" {
+		return ServiceStatus{}, fmt.Errorf("status page %s has no status indicator", statusURL)
+	}
+
+	return ServiceStatus{
+		Service:     service,
+		Indicator:   page.Status.Indicator,
+		Description: page.Status.Description,
+	}, nil
+}
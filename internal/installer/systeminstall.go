@@ -0,0 +1,78 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"claude-code-installer/internal/pathutil"
+)
+
+// requireElevationForSystemInstall fails when command is installed for all
+// users under Program Files and the process is not elevated. Replacing such
+// an install needs administrator rights, so the download strategies check
+// this before fetching an installer that would only fail at the install step.
+func (i *Installer) requireElevationForSystemInstall(stepName, label, command string) error {
+	if runtime.GOOS != "windows" || pathutil.IsElevated() {
+		return nil
+	}
+	path := systemInstallPath(command)
+	if path == "" {
+		return nil
+	}
+	i.emitDetail(stepName, "existing %s at %s is a machine-wide install; process is not elevated", label, path)
+	return fmt.Errorf("%s is installed for all users in %s and replacing it requires administrator rights: %w",
+		label, filepath.Dir(path), pathutil.ErrRequiresElevation)
+}
+
+// warnSystemInstallElevation emits a warning when an existing install of
+// command cannot be replaced without elevation; see requireElevationForSystemInstall.
+func (i *Installer) warnSystemInstallElevation(stepName, label, command string, percentage float64) {
+	if err := i.requireElevationForSystemInstall(stepName, label, command); err != nil {
+		i.emitProgress(stepName, "installing",
+			fmt.Sprintf("Warning: %s is installed for all users; run the installer as administrator to reinstall it", label), percentage)
+	}
+}
+
+// systemInstallPath returns the path of command when it resolves to a
+// machine-wide location such as Program Files, or "" otherwise.
+func systemInstallPath(command string) string {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return ""
+	}
+	if !isUnderAnyDir(path, programFilesDirs()) {
+		return ""
+	}
+	return path
+}
+
+// programFilesDirs returns the machine-wide program directories.
+func programFilesDirs() []string {
+	dirs := []string{`C:\Program Files`, `C:\Program Files (x86)`}
+	for _, name := range []string{"ProgramFiles", "ProgramFiles(x86)", "ProgramW6432"} {
+		if dir := os.Getenv(name); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// isUnderAnyDir reports whether path lies inside one of dirs. Windows paths
+// are case-insensitive, and either separator is accepted.
+func isUnderAnyDir(path string, dirs []string) bool {
+	normalize := func(p string) string {
+		return strings.ToLower(strings.ReplaceAll(p, "/", `\`))
+	}
+	path = normalize(path)
+	for _, dir := range dirs {
+		dir = strings.TrimRight(normalize(dir), `\`)
+		if dir != "" && strings.HasPrefix(path, dir+`\`) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package installer
+
+import "time"
+
+// TelemetryEvent is an anonymized summary of one finished step. It never
+// includes paths, usernames, messages or error text, only the component, how
+// it ran and a classified outcome.
+type TelemetryEvent struct {
+	// Component is the step name, such as "nodejs" or "claudeCodeUpdate".
+	Component string `json:"component"`
+	// Strategy is the installation method last used for the step, if any.
+	Strategy string        `json:"strategy,omitempty"`
+	Success  bool          `json:"success"`
+	Duration time.Duration `json:"duration"`
+	// ErrorCode classifies the failure; it is empty on success.
+	ErrorCode ErrorCode `json:"errorCode,omitempty"`
+}
+
+// TelemetryHook receives a TelemetryEvent at the end of each install,
+// update, rollback or repair step.
+type TelemetryHook func(event TelemetryEvent)
+
+// SetTelemetryHook opts in to telemetry by delivering an anonymized event to
+// hook at the end of each step, for distributors that collect aggregate
+// install success metrics with their users' consent. Telemetry is off by
+// default and hook is its only sink: the package itself never sends events
+// anywhere. A nil hook turns telemetry off again.
+func (i *Installer) SetTelemetryHook(hook TelemetryHook) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.telemetryHook = hook
+}
+
+// withTelemetry runs step and reports its outcome to the telemetry hook, if
+// one is set.
+func (i *Installer) withTelemetry(stepName string, step func() error) error {
+	started := time.Now()
+	err := step()
+
+	i.mu.Lock()
+	hook := i.telemetryHook
+	strategy := i.strategies[stepName]
+	i.mu.Unlock()
+	if hook == nil {
+		return err
+	}
+
+	event := TelemetryEvent{
+		Component: stepName,
+		Strategy:  strategy,
+		Success:   err == nil,
+		Duration:  time.Since(started),
+	}
+	if err != nil {
+		event.ErrorCode = ClassifyError(stepName, err).Code
+	}
+	hook(event)
+	return err
+}
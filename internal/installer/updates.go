@@ -0,0 +1,82 @@
+package installer
+
+import (
+	"claude-code-installer/internal/detector"
+	"claude-code-installer/internal/updater"
+)
+
+// ComponentUpdate describes the installed and latest versions of a component.
+type ComponentUpdate struct {
+	Component       string `json:"component"`
+	Current         string `json:"current"`
+	Latest          string `json:"latest"`
+	UpdateAvailable bool   `json:"updateAvailable"`
+	// Error is set when the component's versions could not be determined.
+	Error string `json:"error,omitempty"`
+}
+
+// CheckAllUpdates compares installed and latest versions for every managed
+// component. Node.js is compared against the LTS version this installer ships,
+// Git against the latest Git for Windows release, and Claude Code against npm.
+// Failures are reported per component rather than failing the whole check.
+func (i *Installer) CheckAllUpdates() []ComponentUpdate {
+	return []ComponentUpdate{
+		i.checkNodeUpdate(),
+		i.checkGitUpdate(),
+		i.checkClaudeCodeUpdate(),
+	}
+}
+
+// checkNodeUpdate compares the installed Node.js against the bundled LTS version.
+func (i *Installer) checkNodeUpdate() ComponentUpdate {
+	update := ComponentUpdate{Component: "nodejs", Latest: nodeLTSVersion}
+
+	status := detector.CheckNodeJS()
+	if !status.Installed {
+		update.Error = "Node.js is not installed"
+		return update
+	}
+
+	update.Current = status.Version
+	update.UpdateAvailable = updater.CompareVersions(update.Current, update.Latest) < 0
+	return update
+}
+
+// checkGitUpdate compares the installed Git against the latest GitHub release.
+func (i *Installer) checkGitUpdate() ComponentUpdate {
+	update := ComponentUpdate{Component: "git"}
+
+	status := detector.CheckGit()
+	update.Current = status.Version
+
+	release, err := i.getLatestGitRelease()
+	if err != nil {
+		update.Error = err.Error()
+		return update
+	}
+	update.Latest = gitVersionFromTag(release.TagName)
+
+	if !status.Installed {
+		update.Error = "Git is not installed"
+		return update
+	}
+
+	update.UpdateAvailable = updater.CompareVersions(update.Current, update.Latest) < 0
+	return update
+}
+
+// checkClaudeCodeUpdate compares the installed Claude Code against npm.
+func (i *Installer) checkClaudeCodeUpdate() ComponentUpdate {
+	update := ComponentUpdate{Component: "claudecode"}
+
+	info, err := i.CheckUpdate()
+	if err != nil {
+		update.Error = err.Error()
+		return update
+	}
+
+	update.Current = info.CurrentVersion
+	update.Latest = info.LatestVersion
+	update.UpdateAvailable = updater.CompareVersions(update.Current, update.Latest) < 0
+	return update
+}
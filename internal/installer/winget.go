@@ -0,0 +1,111 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// wingetIdleTimeout is how long winget may run without producing any output
+// before it is assumed to be blocked on an interactive prompt.
+const wingetIdleTimeout = 2 * time.Minute
+
+// errWingetStalled is returned when winget stops producing output, which
+// older winget versions do while waiting for a source agreement prompt
+// that --accept-source-agreements does not answer.
+var errWingetStalled = errors.New("winget stopped responding (it may be waiting for an agreement prompt)")
+
+// wingetNoPackageMarker is printed by winget when a package ID is missing
+// from its source index, which usually means the cached index is stale.
+const wingetNoPackageMarker = "no package found matching input criteria"
+
+// runWinget runs winget with args, killing it if it produces no output for
+// wingetIdleTimeout. Sources are refreshed once per installer beforehand so
+// first-use agreement prompts are settled before installing; the outcome is
+// reported as a detail for stepName.
+func (i *Installer) runWinget(stepName string, args ...string) error {
+	i.wingetSourcesOnce.Do(func() {
+		// Best effort: a failed refresh surfaces again on the install itself
+		if err := i.updateWingetSources(); err != nil {
+			i.emitDetail(stepName, "winget sources ready: no (%s), continuing with cached sources", summarizeError(err))
+		} else {
+			i.emitDetail(stepName, "winget sources ready: yes")
+		}
+	})
+
+	output, err := i.runWingetWithIdleTimeout(args...)
+	if err == nil || !isWingetNoPackageFound(output) {
+		return err
+	}
+
+	// A stale index is the usual cause; refresh it and retry once before
+	// the caller falls back to another backend
+	i.emitDetail(stepName, "winget found no matching package: refreshing sources and retrying")
+	if refreshErr := i.updateWingetSources(); refreshErr != nil {
+		i.emitDetail(stepName, "winget source refresh failed: %s", summarizeError(refreshErr))
+		return err
+	}
+	_, err = i.runWingetWithIdleTimeout(args...)
+	return err
+}
+
+// updateWingetSources refreshes winget's source index.
+func (i *Installer) updateWingetSources() error {
+	_, err := i.runWingetWithIdleTimeout("source", "update")
+	return err
+}
+
+// isWingetNoPackageFound reports whether winget output says the requested
+// package is not in the source index.
+func isWingetNoPackageFound(output string) bool {
+	return strings.Contains(strings.ToLower(output), wingetNoPackageMarker)
+}
+
+// runWingetWithIdleTimeout runs winget, cancelling it when it goes quiet, and
+// returns its combined output.
+func (i *Installer) runWingetWithIdleTimeout(args ...string) (string, error) {
+	if err := i.checkpoint(); err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithCancel(i.ctx)
+	defer cancel()
+
+	activity := make(chan struct{}, 1)
+	stalled := make(chan struct{})
+	go func() {
+		timer := time.NewTimer(wingetIdleTimeout)
+		defer timer.Stop()
+		for {
+			select {
+			case <-activity:
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(wingetIdleTimeout)
+			case <-timer.C:
+				close(stalled)
+				cancel()
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	onActivity := func() {
+		select {
+		case activity <- struct{}{}:
+		default:
+		}
+	}
+
+	output, err := i.streamCommand(ctx, nil, onActivity, "winget", args...)
+	select {
+	case <-stalled:
+		return output, fmt.Errorf("%w after %s without output", errWingetStalled, wingetIdleTimeout)
+	default:
+	}
+	return output, err
+}
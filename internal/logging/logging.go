@@ -0,0 +1,207 @@
+// Package logging writes installer activity to a size-rotated JSON lines log
+// file so installs on managed machines can be diagnosed after the fact.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxSize is the size in bytes at which the log file is rotated.
+	DefaultMaxSize = 5 * 1024 * 1024 // 5 MB
+	// DefaultMaxFiles is how many log files, including the active one, are kept.
+	DefaultMaxFiles = 5
+
+	// appConfigDirName is the per-user configuration directory for the installer.
+	appConfigDirName = "claude-code-installer"
+	// logFileName is the name of the active log file.
+	logFileName = "install.log"
+)
+
+// Levels recorded in log lines.
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Line is a single structured log entry.
+type Line struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Step    string    `json:"step,omitempty"`
+	Message string    `json:"message"`
+}
+
+// DefaultPath returns the log file location in the user's config directory,
+// %APPDATA%\claude-code-installer\logs\install.log on Windows.
+func DefaultPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, appConfigDirName, "logs", logFileName), nil
+}
+
+// RotatingLogger appends lines to a log file, renaming it to path.1 (and
+// older files to path.2, ...) once it reaches the size threshold. It is safe
+// for concurrent use.
+type RotatingLogger struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+// Open opens or creates the log file at path. Rotation happens once the file
+// reaches maxSize bytes, keeping at most maxFiles files including the active one.
+func Open(path string, maxSize int64, maxFiles int) (*RotatingLogger, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("maximum log size must be positive, got %d", maxSize)
+	}
+	if maxFiles < 1 {
+		return nil, fmt.Errorf("at least one log file must be kept, got %d", maxFiles)
+	}
+	// Logs may contain paths and usernames, so keep them private to the user
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	l := &RotatingLogger{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := l.openFile(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// OpenDefault opens the log file at DefaultPath with the default limits.
+func OpenDefault() (*RotatingLogger, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Open(path, DefaultMaxSize, DefaultMaxFiles)
+}
+
+// Path returns the active log file's path.
+func (l *RotatingLogger) Path() string {
+	return l.path
+}
+
+// Log writes a line. Errors are returned for callers that care, but logging
+// is best effort and most callers ignore them.
+func (l *RotatingLogger) Log(level, step, message string) error {
+	data, err := json.Marshal(Line{Time: time.Now(), Level: level, Step: step, Message: message})
+	if err != nil {
+		return fmt.Errorf("failed to encode log line: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return fmt.Errorf("log file is closed")
+	}
+	var rotateErr error
+	if l.size > 0 && l.size+int64(len(data)) > l.maxSize {
+		// A failed rotation leaves the active file open, so the line is
+		// still written and rotation is retried on the next one
+		if rotateErr = l.rotate(); l.file == nil {
+			return rotateErr
+		}
+	}
+
+	n, err := l.file.Write(data)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write log line: %w", err)
+	}
+	return rotateErr
+}
+
+// Files returns the existing log files, newest first.
+func (l *RotatingLogger) Files() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var files []string
+	for n := 0; n < l.maxFiles; n++ {
+		if _, err := os.Stat(l.rotatedPath(n)); err == nil {
+			files = append(files, l.rotatedPath(n))
+		}
+	}
+	return files
+}
+
+// Close closes the log file. Further writes fail.
+func (l *RotatingLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// openFile opens the active log file for appending; the caller holds mu or
+// has exclusive access.
+func (l *RotatingLogger) openFile() error {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to inspect log file: %w", err)
+	}
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// rotate shifts path.N to path.N+1, dropping the oldest file, moves the
+// active file to path.1 and reopens an empty active file. When a file can't
+// be moved, for example because another process holds it open on Windows,
+// the active file is reopened as it is and the error returned. The caller
+// holds mu.
+func (l *RotatingLogger) rotate() error {
+	closeErr := l.file.Close()
+	l.file = nil
+
+	var rotateErr error
+	if closeErr != nil {
+		rotateErr = fmt.Errorf("failed to close log file: %w", closeErr)
+	} else {
+		// Remove the oldest file, then shift the rest up by one
+		_ = os.Remove(l.rotatedPath(l.maxFiles - 1))
+		for n := l.maxFiles - 2; n >= 0; n-- {
+			if err := os.Rename(l.rotatedPath(n), l.rotatedPath(n+1)); err != nil && !os.IsNotExist(err) {
+				rotateErr = fmt.Errorf("failed to rotate log file: %w", err)
+				break
+			}
+		}
+	}
+
+	if err := l.openFile(); err != nil {
+		return err
+	}
+	return rotateErr
+}
+
+// rotatedPath returns the path of the nth log file; 0 is the active file.
+func (l *RotatingLogger) rotatedPath(n int) string {
+	if n == 0 {
+		return l.path
+	}
+	return fmt.Sprintf("%s.%d", l.path, n)
+}
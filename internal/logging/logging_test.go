@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRotatingLogger_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", logFileName)
+	l, err := Open(path, 200, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer l.Close()
+
+	message := strings.Repeat("x", 60)
+	for n := 0; n < 20; n++ {
+		if err := l.Log(LevelInfo, "nodejs", message); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	files := l.Files()
+	if len(files) != 3 {
+		t.Fatalf("got %d files, want 3: %v", len(files), files)
+	}
+	if files[0] != path || files[2] != path+".2" {
+		t.Errorf("unexpected files: %v", files)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("more files kept than configured")
+	}
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > 200 {
+			t.Errorf("%s is %d bytes, over the 200 byte limit", f, info.Size())
+		}
+	}
+}
+
+func TestRotatingLogger_FailedRotateKeepsLogging(t *testing.T) {
+	path := filepath.Join(t.TempDir(), logFileName)
+	l, err := Open(path, 100, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer l.Close()
+
+	// A non-empty directory where the rotated file goes makes rotation fail
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocked"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	message := strings.Repeat("x", 60)
+	if err := l.Log(LevelInfo, "nodejs", message); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := l.Log(LevelInfo, "nodejs", message); err == nil {
+		t.Error("expected the failed rotation to be reported")
+	}
+	_ = l.Log(LevelInfo, "nodejs", message)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), "\n"); got != 3 {
+		t.Errorf("got %d lines in the active file, want all 3 kept after failed rotations", got)
+	}
+}
+
+func TestRotatingLogger_ConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), logFileName)
+	l, err := Open(path, DefaultMaxSize, DefaultMaxFiles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := 0; m < 50; m++ {
+				_ = l.Log(LevelDebug, "git", "concurrent line")
+			}
+		}()
+	}
+	wg.Wait()
+	if err := l.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line Line
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("interleaved or malformed line %q: %v", scanner.Text(), err)
+		}
+		count++
+	}
+	if count != 500 {
+		t.Errorf("got %d lines, want 500", count)
+	}
+
+	if err := l.Log(LevelInfo, "", "after close"); err == nil {
+		t.Error("expected error writing to a closed logger")
+	}
+}
+
+func TestOpen_InvalidLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), logFileName)
+	if _, err := Open(path, 0, 1); err == nil {
+		t.Error("expected error for zero size")
+	}
+	if _, err := Open(path, 100, 0); err == nil {
+		t.Error("expected error for zero files")
+	}
+}
@@ -0,0 +1,155 @@
+// Package nodekeys verifies Node.js release checksum signatures against the
+// releasers' public keys embedded in the binary, so SHASUMS256.txt.asc can be
+// checked offline without a keyserver.
+package nodekeys
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// keysFS holds the armored release keys; see keys/README.md for how to update them.
+//
+//go:embed keys
+var keysFS embed.FS
+
+// ErrUnknownSigningKey is returned when a signature was made by a key that is
+// not among the embedded release keys.
+var ErrUnknownSigningKey = errors.New("signing key is not a known Node.js release key")
+
+// ErrNoReleaseKeys is returned when no release keys are embedded, so no
+// signature can be verified; run scripts/update-node-keys.sh to add them.
+var ErrNoReleaseKeys = errors.New("no Node.js release keys are embedded")
+
+// ErrBadSignature is returned when a signature by a known key does not match
+// the signed content.
+var ErrBadSignature = errors.New("signature does not match the signed content")
+
+var (
+	embeddedOnce    sync.Once
+	embeddedKeyring openpgp.EntityList
+	embeddedErr     error
+)
+
+// Keyring returns the embedded release keys. It returns ErrNoReleaseKeys
+// when there are none.
+func Keyring() (openpgp.EntityList, error) {
+	embeddedOnce.Do(func() {
+		embeddedKeyring, embeddedErr = loadKeyring(keysFS, "keys")
+		if embeddedErr == nil && len(embeddedKeyring) == 0 {
+			embeddedErr = ErrNoReleaseKeys
+		}
+	})
+	return embeddedKeyring, embeddedErr
+}
+
+// Fingerprints returns the uppercase hex fingerprints of the embedded keys.
+func Fingerprints() ([]string, error) {
+	keyring, err := Keyring()
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := make([]string, 0, len(keyring))
+	for _, entity := range keyring {
+		fingerprints = append(fingerprints, fingerprint(entity))
+	}
+	sort.Strings(fingerprints)
+	return fingerprints, nil
+}
+
+// Verify checks an ASCII-armored detached signature (SHASUMS256.txt.asc)
+// over signed (SHASUMS256.txt) against the embedded release keys and returns
+// the fingerprint of the signing key.
+func Verify(signed, armoredSignature []byte) (string, error) {
+	keyring, err := Keyring()
+	if err != nil {
+		return "", err
+	}
+	return verify(keyring, signed, armoredSignature)
+}
+
+// verify checks armoredSignature over signed against keyring.
+func verify(keyring openpgp.EntityList, signed, armoredSignature []byte) (string, error) {
+	issuer, err := signatureIssuer(armoredSignature)
+	if err != nil {
+		return "", err
+	}
+	if len(keyring.KeysById(issuer)) == 0 {
+		return "", fmt.Errorf("%w: key ID %016X (update the embedded keys with scripts/update-node-keys.sh if this is a new releaser)",
+			ErrUnknownSigningKey, issuer)
+	}
+
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(signed), bytes.NewReader(armoredSignature), nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrBadSignature, err)
+	}
+	return fingerprint(signer), nil
+}
+
+// signatureIssuer returns the key ID of the key that made an armored
+// detached signature.
+func signatureIssuer(armoredSignature []byte) (uint64, error) {
+	block, err := armor.Decode(bytes.NewReader(armoredSignature))
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode signature: %w", err)
+	}
+	if block.Type != openpgp.SignatureType {
+		return 0, fmt.Errorf("expected a signature block, got %q", block.Type)
+	}
+	p, err := packet.Read(block.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	sig, ok := p.(*packet.Signature)
+	if !ok {
+		return 0, fmt.Errorf("unexpected packet in signature: %T", p)
+	}
+	if sig.IssuerKeyId == nil {
+		return 0, fmt.Errorf("signature does not name its signing key")
+	}
+	return *sig.IssuerKeyId, nil
+}
+
+// loadKeyring reads every *.asc file in dir. A key that cannot be parsed is
+// an error rather than being skipped, so a broken key file is caught when
+// the keys are updated instead of surfacing as ErrUnknownSigningKey.
+func loadKeyring(fsys fs.FS, dir string) (openpgp.EntityList, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded keys: %w", err)
+	}
+
+	var keyring openpgp.EntityList
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".asc") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read embedded key %s: %w", entry.Name(), err)
+		}
+		keys, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse embedded key %s: %w", entry.Name(), err)
+		}
+		keyring = append(keyring, keys...)
+	}
+	return keyring, nil
+}
+
+// fingerprint formats an entity's primary key fingerprint.
+func fingerprint(entity *openpgp.Entity) string {
+	return fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)
+}
@@ -0,0 +1,99 @@
+package nodekeys
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// newTestKey creates a signing key and its armored public key.
+func newTestKey(t *testing.T, name string) (*openpgp.Entity, []byte) {
+	t.Helper()
+	entity, err := openpgp.NewEntity(name, "", name+"@example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return entity, buf.Bytes()
+}
+
+func sign(t *testing.T, signer *openpgp.Entity, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, signer, bytes.NewReader(content), nil); err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestVerify(t *testing.T) {
+	releaser, releaserKey := newTestKey(t, "releaser")
+	stranger, _ := newTestKey(t, "stranger")
+
+	keyring, err := loadKeyring(fstest.MapFS{
+		"keys/releaser.asc": {Data: releaserKey},
+		"keys/README.md":    {Data: []byte("docs")},
+	}, "keys")
+	if err != nil {
+		t.Fatalf("loadKeyring() error: %v", err)
+	}
+	if len(keyring) != 1 {
+		t.Fatalf("loaded %d keys, want 1", len(keyring))
+	}
+
+	if _, err := loadKeyring(fstest.MapFS{
+		"keys/releaser.asc": {Data: releaserKey},
+		"keys/broken.asc":   {Data: []byte("not a key")},
+	}, "keys"); err == nil {
+		t.Error("expected an error for an unparseable key")
+	}
+
+	shasums := []byte("abc123  node-v22.0.0-win-x64.zip\n")
+
+	fp, err := verify(keyring, shasums, sign(t, releaser, shasums))
+	if err != nil {
+		t.Fatalf("verify() error: %v", err)
+	}
+	if fp != fingerprint(releaser) {
+		t.Errorf("fingerprint = %s, want %s", fp, fingerprint(releaser))
+	}
+
+	_, err = verify(keyring, []byte("tampered\n"), sign(t, releaser, shasums))
+	if !errors.Is(err, ErrBadSignature) {
+		t.Errorf("tampered content: expected ErrBadSignature, got %v", err)
+	}
+
+	_, err = verify(keyring, shasums, sign(t, stranger, shasums))
+	if !errors.Is(err, ErrUnknownSigningKey) {
+		t.Errorf("unknown key: expected ErrUnknownSigningKey, got %v", err)
+	}
+
+	if _, err := verify(keyring, shasums, []byte("garbage")); err == nil {
+		t.Error("expected error for a malformed signature")
+	}
+}
+
+func TestEmbeddedKeyring(t *testing.T) {
+	fingerprints, err := Fingerprints()
+	if errors.Is(err, ErrNoReleaseKeys) {
+		t.Skip("no release keys in keys/; run scripts/update-node-keys.sh and commit them")
+	}
+	if err != nil {
+		t.Fatalf("embedded keys failed to load: %v", err)
+	}
+	if len(fingerprints) == 0 {
+		t.Fatal("embedded keyring is empty")
+	}
+}
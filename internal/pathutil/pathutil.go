@@ -3,7 +3,56 @@
 // and broadcasting environment change notifications.
 package pathutil
 
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// MaxSafeUserPathLength is the longest user PATH registry value that
+	// legacy tools such as setx and older installers handle without silently
+	// truncating it.
+	MaxSafeUserPathLength = 2047
+	// MaxSystemPathLength is the longest machine-wide PATH value, the limit
+	// Windows places on any environment variable.
+	MaxSystemPathLength = 32767
+)
+
+// ErrRequiresElevation is returned when a machine-wide PATH change is attempted
+// from a process that is not running elevated.
+var ErrRequiresElevation = errors.New("modifying the system PATH requires elevation")
+
+// ErrUserPathReadOnly is returned when the user PATH cannot be written, such
+// as when Group Policy restricts HKCU\Environment.
+var ErrUserPathReadOnly = errors.New("the user PATH cannot be modified")
+
+// ErrPathTooLong is returned instead of writing a PATH value longer than the
+// limit for its registry hive.
+var ErrPathTooLong = errors.New("PATH would exceed the safe length limit")
+
 // PathContains checks if a directory is already present in a PATH string.
 func PathContains(pathEnv, dir string) bool {
 	return pathContains(pathEnv, dir)
 }
+
+// appendPathEntry returns pathEnv with dir appended, avoiding an empty entry
+// from a trailing separator.
+func appendPathEntry(pathEnv, dir string) string {
+	sep := string(os.PathListSeparator)
+	if trimmed := strings.TrimRight(pathEnv, sep); trimmed != "" {
+		return trimmed + sep + dir
+	}
+	return dir
+}
+
+// CheckPathLength reports whether appending dir to pathEnv stays within
+// limit, returning an error wrapping ErrPathTooLong if not.
+func CheckPathLength(pathEnv, dir string, limit int) error {
+	if n := len(appendPathEntry(pathEnv, dir)); n > limit {
+		return fmt.Errorf("%w: adding %s would make PATH %d characters (limit %d)",
+			ErrPathTooLong, dir, n, limit)
+	}
+	return nil
+}
@@ -5,7 +5,11 @@ package pathutil
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+
+	"golang.org/x/sys/unix"
 )
 
 // GetUserPath reads the PATH environment variable on non-Windows platforms.
@@ -13,10 +17,75 @@ func GetUserPath() (string, error) {
 	return os.Getenv("PATH"), nil
 }
 
-// AddToPath is a no-op on non-Windows platforms.
-// PATH management via registry is Windows-specific.
+// AddToPath adds a directory to PATH for future login shells by appending an
+// export line to the user's shell profile (~/.zprofile on macOS, ~/.profile
+// elsewhere). The current process environment is not modified.
 func AddToPath(dir string) error {
-	return fmt.Errorf("AddToPath is only supported on Windows")
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("path must be absolute: %s", dir)
+	}
+
+	profile, err := userProfilePath()
+	if err != nil {
+		return err
+	}
+	return addToProfile(profile, dir)
+}
+
+// CheckUserPathWritable reports whether AddToPath will be able to update the
+// user's shell profile, without changing it. The error wraps
+// ErrUserPathReadOnly.
+func CheckUserPathWritable() error {
+	profile, err := userProfilePath()
+	if err != nil {
+		return err
+	}
+	return checkProfileWritable(profile)
+}
+
+// userProfilePath returns the shell profile AddToPath appends to.
+func userProfilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+
+	profile := ".profile"
+	if runtime.GOOS == "darwin" {
+		profile = ".zprofile"
+	}
+	return filepath.Join(home, profile), nil
+}
+
+// checkProfileWritable checks that profilePath can be appended to, or created
+// if it doesn't exist yet.
+func checkProfileWritable(profilePath string) error {
+	f, err := os.OpenFile(profilePath, os.O_WRONLY|os.O_APPEND, 0)
+	if err == nil {
+		return f.Close()
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("%w: %v", ErrUserPathReadOnly, err)
+	}
+	if err := unix.Access(filepath.Dir(profilePath), unix.W_OK); err != nil {
+		return fmt.Errorf("%w: cannot create %s: %v", ErrUserPathReadOnly, profilePath, err)
+	}
+	return nil
+}
+
+// AddToSystemPath is a no-op on non-Windows platforms.
+func AddToSystemPath(dir string) error {
+	return fmt.Errorf("AddToSystemPath is only supported on Windows")
+}
+
+// IsElevated always reports false on non-Windows platforms.
+func IsElevated() bool {
+	return false
+}
+
+// GetSystemPath reads the PATH environment variable on non-Windows platforms.
+func GetSystemPath() (string, error) {
+	return os.Getenv("PATH"), nil
 }
 
 // BroadcastSettingChange is a no-op on non-Windows platforms.
@@ -29,6 +98,42 @@ func RefreshPath() error {
 	return nil
 }
 
+// CurrentPath returns the PATH environment variable on non-Windows platforms.
+func CurrentPath() (string, error) {
+	return os.Getenv("PATH"), nil
+}
+
+// addToProfile appends a PATH export for dir to the given shell profile unless
+// an identical line is already present.
+func addToProfile(profilePath, dir string) error {
+	exportLine := fmt.Sprintf(`export PATH="%s:$PATH"`, dir)
+
+	content, err := os.ReadFile(profilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", profilePath, err)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == exportLine {
+			return nil // Already present, nothing to do
+		}
+	}
+
+	f, err := os.OpenFile(profilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", profilePath, err)
+	}
+
+	_, writeErr := fmt.Fprintf(f, "\n# Added by Claude Code Installer\n%s\n", exportLine)
+	closeErr := f.Close()
+	if writeErr != nil {
+		return fmt.Errorf("failed to update %s: %w", profilePath, writeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to finalize %s: %w", profilePath, closeErr)
+	}
+	return nil
+}
+
 // pathContains checks if a directory is already present in a PATH string.
 func pathContains(pathEnv, dir string) bool {
 	dir = strings.TrimRight(dir, `/\`)
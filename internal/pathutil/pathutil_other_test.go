@@ -0,0 +1,58 @@
+//go:build !windows
+
+package pathutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddToProfile(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), ".profile")
+	if err := os.WriteFile(profile, []byte("# existing\n"), 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if err := addToProfile(profile, "/home/user/.npm-global/bin"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(profile)
+	if err != nil {
+		t.Fatalf("failed to read profile: %v", err)
+	}
+	exportLine := `export PATH="/home/user/.npm-global/bin:$PATH"`
+	if count := strings.Count(string(content), exportLine); count != 1 {
+		t.Errorf("export line appears %d times, want 1:\n%s", count, content)
+	}
+	if !strings.HasPrefix(string(content), "# existing\n") {
+		t.Error("existing profile content was not preserved")
+	}
+}
+
+func TestCheckProfileWritable(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, ".profile")
+	if err := os.WriteFile(existing, nil, 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	if err := checkProfileWritable(existing); err != nil {
+		t.Errorf("existing profile: unexpected error: %v", err)
+	}
+	if err := checkProfileWritable(filepath.Join(dir, ".zprofile")); err != nil {
+		t.Errorf("missing profile in writable home: unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".zprofile")); !os.IsNotExist(err) {
+		t.Errorf("probe created the profile")
+	}
+	err := checkProfileWritable(filepath.Join(dir, "missing", ".profile"))
+	if !errors.Is(err, ErrUserPathReadOnly) {
+		t.Errorf("missing home: error = %v, want ErrUserPathReadOnly", err)
+	}
+}
@@ -0,0 +1,36 @@
+package pathutil
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckPathLength(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	dir := "/opt/tool/bin"
+
+	short := strings.Join([]string{"/usr/bin", "/bin"}, sep)
+	if err := CheckPathLength(short, dir, MaxSafeUserPathLength); err != nil {
+		t.Errorf("unexpected error for short PATH: %v", err)
+	}
+
+	// Exactly at the limit once dir and its separator are appended
+	atLimit := strings.Repeat("a", MaxSafeUserPathLength-len(dir)-1)
+	if err := CheckPathLength(atLimit, dir, MaxSafeUserPathLength); err != nil {
+		t.Errorf("unexpected error at the limit: %v", err)
+	}
+	// A trailing separator does not count towards the result
+	if err := CheckPathLength(atLimit+sep, dir, MaxSafeUserPathLength); err != nil {
+		t.Errorf("unexpected error with trailing separator: %v", err)
+	}
+
+	if err := CheckPathLength(atLimit+"a", dir, MaxSafeUserPathLength); !errors.Is(err, ErrPathTooLong) {
+		t.Fatalf("expected ErrPathTooLong, got %v", err)
+	}
+	// The machine-wide PATH is not held to the user PATH's limit
+	if err := CheckPathLength(atLimit+"a", dir, MaxSystemPathLength); err != nil {
+		t.Errorf("unexpected error under the system limit: %v", err)
+	}
+}
@@ -10,12 +10,15 @@ import (
 	"syscall"
 	"unsafe"
 
+	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/registry"
 )
 
 const (
 	// registryKeyPath is the registry path for user environment variables.
 	registryKeyPath = `Environment`
+	// systemRegistryKeyPath is the registry path for machine-wide environment variables.
+	systemRegistryKeyPath = `SYSTEM\CurrentControlSet\Control\Session Manager\Environment`
 	// hwndBroadcast is the HWND_BROADCAST constant for SendMessageTimeout.
 	hwndBroadcast = 0xFFFF
 	// wmSettingChange is the WM_SETTINGCHANGE message constant.
@@ -24,16 +27,69 @@ const (
 	smtoAbortIfHung = 0x0002
 	// sendMessageTimeoutMs is the timeout for SendMessageTimeout in milliseconds.
 	sendMessageTimeoutMs = 5000
+	// pathWriteAttempts is how many times a PATH update is retried when a
+	// concurrent writer overwrites it.
+	pathWriteAttempts = 3
 )
 
 // GetUserPath reads the user-level PATH from the Windows registry.
 func GetUserPath() (string, error) {
-	key, err := registry.OpenKey(registry.CURRENT_USER, registryKeyPath, registry.QUERY_VALUE)
+	return readRegistryPath(registry.CURRENT_USER, registryKeyPath)
+}
+
+// AddToPath adds a directory to the user-level PATH if it's not already present.
+// It modifies the registry and broadcasts a WM_SETTINGCHANGE message to notify
+// other processes of the environment change.
+func AddToPath(dir string) error {
+	return addToRegistryPath(registry.CURRENT_USER, registryKeyPath, dir)
+}
+
+// CheckUserPathWritable reports whether AddToPath will be able to update the
+// user-level PATH by opening HKCU\Environment for writing, without changing
+// it. The error wraps ErrUserPathReadOnly.
+func CheckUserPathWritable() error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, registryKeyPath, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("%w: cannot open HKCU\\%s for writing: %v", ErrUserPathReadOnly, registryKeyPath, err)
+	}
+	return key.Close()
+}
+
+// AddToSystemPath adds a directory to the machine-wide PATH if it's not already present.
+// Writing HKLM requires an elevated process; ErrRequiresElevation is returned otherwise
+// so callers can fall back to AddToPath.
+func AddToSystemPath(dir string) error {
+	if !IsElevated() {
+		return ErrRequiresElevation
+	}
+	return addToRegistryPath(registry.LOCAL_MACHINE, systemRegistryKeyPath, dir)
+}
+
+// IsElevated reports whether the current process is running with an elevated token.
+func IsElevated() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
+
+// GetSystemPath reads the machine-wide PATH from the Windows registry.
+func GetSystemPath() (string, error) {
+	return readRegistryPath(registry.LOCAL_MACHINE, systemRegistryKeyPath)
+}
+
+// readRegistryPath reads the Path value under the given registry key.
+// A missing Path value is reported as an empty string.
+func readRegistryPath(root registry.Key, keyPath string) (string, error) {
+	key, err := registry.OpenKey(root, keyPath, registry.QUERY_VALUE)
 	if err != nil {
 		return "", fmt.Errorf("failed to open registry key: %w", err)
 	}
 	defer key.Close()
 
+	return readPathValue(key)
+}
+
+// readPathValue reads the Path value of an open registry key.
+// A missing Path value is reported as an empty string.
+func readPathValue(key registry.Key) (string, error) {
 	path, _, err := key.GetStringValue("Path")
 	if err != nil {
 		// If the Path value doesn't exist, return empty string (not an error)
@@ -46,10 +102,14 @@ func GetUserPath() (string, error) {
 	return path, nil
 }
 
-// AddToPath adds a directory to the user-level PATH if it's not already present.
-// It modifies the registry and broadcasts a WM_SETTINGCHANGE message to notify
-// other processes of the environment change.
-func AddToPath(dir string) error {
+// addToRegistryPath appends dir to the Path value under the given registry key
+// and broadcasts the change.
+//
+// The registry offers no compare-and-swap, so the value is re-read right
+// before each write to merge entries added concurrently by other processes,
+// and read back afterwards to confirm dir is present. If another writer
+// clobbers the update, the merge is retried.
+func addToRegistryPath(root registry.Key, keyPath, dir string) error {
 	// Validate that the path is absolute
 	if !filepath.IsAbs(dir) {
 		return fmt.Errorf("path must be absolute: %s", dir)
@@ -60,39 +120,57 @@ func AddToPath(dir string) error {
 		return fmt.Errorf("path does not exist: %w", err)
 	}
 
-	currentPath, err := GetUserPath()
+	key, err := registry.OpenKey(root, keyPath, registry.QUERY_VALUE|registry.SET_VALUE)
 	if err != nil {
-		return fmt.Errorf("failed to get current PATH: %w", err)
+		if root == registry.CURRENT_USER {
+			return fmt.Errorf("%w: failed to open registry key for writing: %v", ErrUserPathReadOnly, err)
+		}
+		return fmt.Errorf("failed to open registry key for writing: %w", err)
 	}
+	defer key.Close()
 
-	// Check if directory is already in PATH
-	if pathContains(currentPath, dir) {
-		return nil // Already present, nothing to do
+	limit, variables := MaxSafeUserPathLength, "User variables"
+	if root == registry.LOCAL_MACHINE {
+		limit, variables = MaxSystemPathLength, "System variables"
 	}
 
-	// Build the new PATH value
-	var newPath string
-	if currentPath == "" {
-		newPath = dir
-	} else {
-		newPath = currentPath + ";" + dir
-	}
+	for attempt := 0; attempt < pathWriteAttempts; attempt++ {
+		currentPath, err := readPathValue(key)
+		if err != nil {
+			return fmt.Errorf("failed to get current PATH: %w", err)
+		}
 
-	// Write the new PATH to the registry
-	key, err := registry.OpenKey(registry.CURRENT_USER, registryKeyPath, registry.SET_VALUE)
-	if err != nil {
-		return fmt.Errorf("failed to open registry key for writing: %w", err)
-	}
-	defer key.Close()
+		// Check if directory is already in PATH
+		if pathContains(currentPath, dir) {
+			if attempt == 0 {
+				return nil // Already present, nothing to do
+			}
+			return BroadcastSettingChange()
+		}
 
-	// Use REG_EXPAND_SZ to support environment variable references in PATH
-	err = key.SetExpandStringValue("Path", newPath)
-	if err != nil {
-		return fmt.Errorf("failed to write Path value: %w", err)
+		// Refuse rather than risk a value that other tools truncate
+		if err := CheckPathLength(currentPath, dir, limit); err != nil {
+			return fmt.Errorf("%w; remove duplicate or unused Path entries under %s in System Properties > Environment Variables", err, variables)
+		}
+		newPath := appendPathEntry(currentPath, dir)
+
+		// Use REG_EXPAND_SZ to support environment variable references in PATH
+		if err := key.SetExpandStringValue("Path", newPath); err != nil {
+			return fmt.Errorf("failed to write Path value: %w", err)
+		}
+
+		// Read back to confirm the write took effect
+		written, err := readPathValue(key)
+		if err != nil {
+			return fmt.Errorf("failed to verify Path value: %w", err)
+		}
+		if pathContains(written, dir) {
+			// Broadcast the change to all windows
+			return BroadcastSettingChange()
+		}
 	}
 
-	// Broadcast the change to all windows
-	return BroadcastSettingChange()
+	return fmt.Errorf("failed to add %s to PATH: the value was overwritten by another process", dir)
 }
 
 // BroadcastSettingChange sends a WM_SETTINGCHANGE message to all top-level windows
@@ -131,35 +209,35 @@ func BroadcastSettingChange() error {
 // by reading the latest value from the registry and combining it with the
 // system PATH.
 func RefreshPath() error {
-	userPath, err := GetUserPath()
+	combinedPath, err := CurrentPath()
 	if err != nil {
-		return fmt.Errorf("failed to get user PATH: %w", err)
+		return err
 	}
 
-	// Read system PATH from registry
-	systemKey, err := registry.OpenKey(
-		registry.LOCAL_MACHINE,
-		`SYSTEM\CurrentControlSet\Control\Session Manager\Environment`,
-		registry.QUERY_VALUE,
-	)
+	// Set the combined PATH in the current process environment
+	return syscall.Setenv("PATH", combinedPath)
+}
+
+// CurrentPath returns the PATH a newly started process would get: the system
+// PATH from the registry followed by the user PATH. Unlike RefreshPath it
+// leaves the current process's environment unchanged.
+func CurrentPath() (string, error) {
+	userPath, err := GetUserPath()
 	if err != nil {
-		return fmt.Errorf("failed to open system registry key: %w", err)
+		return "", fmt.Errorf("failed to get user PATH: %w", err)
 	}
-	defer systemKey.Close()
 
-	systemPath, _, err := systemKey.GetStringValue("Path")
+	// Read system PATH from registry
+	systemPath, err := GetSystemPath()
 	if err != nil {
-		return fmt.Errorf("failed to read system Path: %w", err)
+		return "", fmt.Errorf("failed to read system Path: %w", err)
 	}
 
 	// Combine system and user PATH
-	combinedPath := systemPath
-	if userPath != "" {
-		combinedPath = systemPath + ";" + userPath
+	if userPath == "" {
+		return systemPath, nil
 	}
-
-	// Set the combined PATH in the current process environment
-	return syscall.Setenv("PATH", combinedPath)
+	return systemPath + ";" + userPath, nil
 }
 
 // pathContains checks if a directory is already present in a PATH string.
@@ -0,0 +1,168 @@
+package updater
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SatisfiesRange reports whether version satisfies an npm-style semver range
+// such as ">=18.0.0", "^20.1.0 || >=22", "~18.17.0" or "18.x". Supported
+// syntax is the subset used in package.json "engines" fields: comparison
+// operators, caret and tilde ranges, x-ranges, hyphen ranges and "||".
+func SatisfiesRange(version, rangeExpr string) (bool, error) {
+	version = cleanVersion(version)
+	if version == "" {
+		return false, fmt.Errorf("empty version")
+	}
+
+	for _, alternative := range strings.Split(rangeExpr, "||") {
+		comparators, err := parseComparators(alternative)
+		if err != nil {
+			return false, err
+		}
+		satisfied := true
+		for _, c := range comparators {
+			if !c.matches(version) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// comparator is a single "<op><version>" constraint.
+type comparator struct {
+	op      string
+	version string
+}
+
+func (c comparator) matches(version string) bool {
+	cmp := compareVersions(version, c.version)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp == 0
+	}
+}
+
+// parseComparators expands one "||" alternative into plain comparators.
+func parseComparators(expr string) ([]comparator, error) {
+	// Allow a space between an operator and its version (">= 18")
+	fields := strings.Fields(expr)
+	var tokens []string
+	for idx := 0; idx < len(fields); idx++ {
+		field := fields[idx]
+		if strings.Trim(field, "<>=^~") == "" && field != "" && idx+1 < len(fields) {
+			field += fields[idx+1]
+			idx++
+		}
+		tokens = append(tokens, field)
+	}
+
+	// Hyphen range: "1.2.3 - 2.3.4"
+	if len(tokens) == 3 && tokens[1] == "-" {
+		return []comparator{
+			{op: ">=", version: cleanVersion(tokens[0])},
+			{op: "<=", version: cleanVersion(tokens[2])},
+		}, nil
+	}
+
+	var comparators []comparator
+	for _, token := range tokens {
+		expanded, err := expandComparator(token)
+		if err != nil {
+			return nil, err
+		}
+		comparators = append(comparators, expanded...)
+	}
+	return comparators, nil
+}
+
+// expandComparator converts a single range token into plain comparators.
+func expandComparator(token string) ([]comparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(token, prefix) {
+			op = prefix
+			token = token[len(prefix):]
+			break
+		}
+	}
+
+	version := cleanVersion(token)
+	parts, wildcard := splitRangeVersion(version)
+	if len(parts) == 0 {
+		if op == "" || op == "=" || op == ">=" || op == "<=" || op == "^" || op == "~" {
+			return nil, nil // "*", "x" or "" match everything
+		}
+		return nil, fmt.Errorf("invalid range %q", op+token)
+	}
+	for len(parts) < 3 {
+		parts = append(parts, 0)
+	}
+	lower := fmt.Sprintf("%d.%d.%d", parts[0], parts[1], parts[2])
+
+	switch op {
+	case "^":
+		var upper string
+		switch {
+		case parts[0] > 0:
+			upper = fmt.Sprintf("%d.0.0", parts[0]+1)
+		case parts[1] > 0:
+			upper = fmt.Sprintf("0.%d.0", parts[1]+1)
+		default:
+			upper = fmt.Sprintf("0.0.%d", parts[2]+1)
+		}
+		return []comparator{{">=", lower}, {"<", upper}}, nil
+	case "~":
+		return []comparator{{">=", lower}, {"<", fmt.Sprintf("%d.%d.0", parts[0], parts[1]+1)}}, nil
+	case "", "=":
+		if wildcard > 0 {
+			// "18" or "18.x" matches any 18.*.*; "18.2" any 18.2.*
+			upperParts := append([]int{}, parts[:wildcard]...)
+			upperParts[wildcard-1]++
+			for len(upperParts) < 3 {
+				upperParts = append(upperParts, 0)
+			}
+			upper := fmt.Sprintf("%d.%d.%d", upperParts[0], upperParts[1], upperParts[2])
+			return []comparator{{">=", lower}, {"<", upper}}, nil
+		}
+		return []comparator{{"=", lower}}, nil
+	default:
+		return []comparator{{op, lower}}, nil
+	}
+}
+
+// splitRangeVersion parses the numeric parts of a range version, stopping at
+// an "x" or "*" wildcard. wildcard is the number of specified parts when the
+// version is partial (e.g. 1 for "18" or "18.x"), and 0 for a full version.
+func splitRangeVersion(version string) (parts []int, wildcard int) {
+	// Drop prerelease and build metadata
+	if idx := strings.IndexAny(version, "-+"); idx != -1 {
+		version = version[:idx]
+	}
+	if version == "" {
+		return nil, 0
+	}
+	for _, part := range strings.Split(version, ".") {
+		if part == "x" || part == "X" || part == "*" || part == "" {
+			return parts, len(parts)
+		}
+		parts = append(parts, parseVersionParts(part)[0])
+	}
+	if len(parts) < 3 {
+		return parts, len(parts)
+	}
+	return parts, 0
+}
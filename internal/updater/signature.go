@@ -0,0 +1,104 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultPublisher is the Authenticode subject of the certificate release
+// builds are signed with. Release builds set it at link time from that
+// certificate:
+//
+//	-ldflags "-X claude-code-installer/internal/updater.DefaultPublisher=<subject>"
+//
+// Unsigned and development builds leave it empty and refuse every update
+// until SetExpectedSigner configures a publisher.
+var DefaultPublisher string
+
+// ErrUntrustedSignature is returned when a downloaded update is unsigned, its
+// signature does not verify, or it is signed by someone other than the
+// expected publisher.
+var ErrUntrustedSignature = errors.New("update is not signed by the expected publisher")
+
+// Signer identifies the certificate an update is Authenticode-signed with.
+type Signer struct {
+	// Subject is the signing certificate's display name.
+	Subject string `json:"subject"`
+	// Thumbprint is the certificate's SHA-1 thumbprint in upper-case hex.
+	Thumbprint string `json:"thumbprint"`
+}
+
+// SetExpectedSigner configures who downloaded updates must be signed by. The
+// signing certificate's subject must equal subject and, when thumbprints is
+// non-empty, its SHA-1 thumbprint must be one of them, which pins the
+// certificate rather than trusting any certificate issued to the publisher.
+func (uc *UpdateChecker) SetExpectedSigner(subject string, thumbprints []string) error {
+	subject, normalized, err := NormalizeExpectedSigner(subject, thumbprints)
+	if err != nil {
+		return err
+	}
+	uc.signerSubject = subject
+	uc.signerThumbprints = normalized
+	return nil
+}
+
+// NormalizeExpectedSigner validates an expected signer for SetExpectedSigner
+// and returns the subject trimmed and the thumbprints in upper-case hex.
+func NormalizeExpectedSigner(subject string, thumbprints []string) (string, []string, error) {
+	subject = strings.TrimSpace(subject)
+	if subject == "" {
+		return "", nil, fmt.Errorf("expected signer subject must not be empty")
+	}
+	normalized := make([]string, 0, len(thumbprints))
+	for _, thumbprint := range thumbprints {
+		t := normalizeThumbprint(thumbprint)
+		if len(t) != 40 || strings.Trim(t, "0123456789ABCDEF") != "" {
+			return "", nil, fmt.Errorf("invalid SHA-1 thumbprint %q", thumbprint)
+		}
+		normalized = append(normalized, t)
+	}
+	return subject, normalized, nil
+}
+
+// VerifyUpdateSignature checks that the file at path has a valid Authenticode
+// signature from the expected publisher (see SetExpectedSigner) and returns
+// the signer. Verification is only available on Windows, where updates are
+// applied.
+func (uc *UpdateChecker) VerifyUpdateSignature(path string) (*Signer, error) {
+	signer, err := readAuthenticodeSigner(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUntrustedSignature, err)
+	}
+	if err := checkSigner(signer, uc.signerSubject, uc.signerThumbprints); err != nil {
+		return &signer, err
+	}
+	return &signer, nil
+}
+
+// checkSigner compares signer against the expected subject and, if any are
+// given, thumbprints.
+func checkSigner(signer Signer, subject string, thumbprints []string) error {
+	if subject == "" {
+		return fmt.Errorf("%w: no publisher is configured for this build", ErrUntrustedSignature)
+	}
+	if signer.Subject != subject {
+		return fmt.Errorf("%w: signed by %q, want %q", ErrUntrustedSignature, signer.Subject, subject)
+	}
+	if len(thumbprints) == 0 {
+		return nil
+	}
+	got := normalizeThumbprint(signer.Thumbprint)
+	for _, thumbprint := range thumbprints {
+		if got == thumbprint {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: certificate %s is not a pinned %s certificate", ErrUntrustedSignature, got, subject)
+}
+
+// normalizeThumbprint upper-cases a thumbprint and drops the spaces and
+// colons certificate viewers separate its bytes with.
+func normalizeThumbprint(thumbprint string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", ":", "").Replace(strings.TrimSpace(thumbprint)))
+}
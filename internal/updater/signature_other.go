@@ -0,0 +1,10 @@
+//go:build !windows
+
+package updater
+
+import "errors"
+
+// readAuthenticodeSigner is unsupported; Authenticode is a Windows mechanism.
+func readAuthenticodeSigner(path string) (Signer, error) {
+	return Signer{}, errors.New("Authenticode verification is only supported on Windows")
+}
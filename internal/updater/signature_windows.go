@@ -0,0 +1,98 @@
+//go:build windows
+
+package updater
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// cmsgSignerCertInfoParam is CMSG_SIGNER_CERT_INFO_PARAM, which returns the
+// issuer and serial number identifying the signer's certificate.
+const cmsgSignerCertInfoParam = 7
+
+var (
+	crypt32              = windows.NewLazySystemDLL("crypt32.dll")
+	procCryptMsgGetParam = crypt32.NewProc("CryptMsgGetParam")
+	procCryptMsgClose    = crypt32.NewProc("CryptMsgClose")
+)
+
+// readAuthenticodeSigner verifies the Authenticode signature of the file at
+// path with WinVerifyTrust, including certificate revocation, and returns the
+// certificate it was signed with.
+func readAuthenticodeSigner(path string) (Signer, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return Signer{}, err
+	}
+	if err := winVerifyTrust(pathPtr); err != nil {
+		return Signer{}, err
+	}
+
+	var encoding, contentType, formatType uint32
+	var store, msg windows.Handle
+	err = windows.CryptQueryObject(windows.CERT_QUERY_OBJECT_FILE, unsafe.Pointer(pathPtr),
+		windows.CERT_QUERY_CONTENT_FLAG_PKCS7_SIGNED_EMBED, windows.CERT_QUERY_FORMAT_FLAG_BINARY, 0,
+		&encoding, &contentType, &formatType, &store, &msg, nil)
+	if err != nil {
+		return Signer{}, fmt.Errorf("failed to read signature: %w", err)
+	}
+	defer windows.CertCloseStore(store, 0)
+	defer procCryptMsgClose.Call(uintptr(msg))
+
+	// The signer is looked up by the issuer and serial number the signature
+	// names, not by enumerating the store, which may hold any certificate
+	var size uint32
+	if r, _, err := procCryptMsgGetParam.Call(uintptr(msg), cmsgSignerCertInfoParam, 0, 0, uintptr(unsafe.Pointer(&size))); r == 0 {
+		return Signer{}, fmt.Errorf("failed to read signer: %w", err)
+	}
+	certInfo := make([]byte, size)
+	if r, _, err := procCryptMsgGetParam.Call(uintptr(msg), cmsgSignerCertInfoParam, 0,
+		uintptr(unsafe.Pointer(&certInfo[0])), uintptr(unsafe.Pointer(&size))); r == 0 {
+		return Signer{}, fmt.Errorf("failed to read signer: %w", err)
+	}
+
+	cert, err := windows.CertFindCertificateInStore(store, windows.X509_ASN_ENCODING|windows.PKCS_7_ASN_ENCODING,
+		0, windows.CERT_FIND_SUBJECT_CERT, unsafe.Pointer(&certInfo[0]), nil)
+	if err != nil {
+		return Signer{}, fmt.Errorf("failed to find signing certificate: %w", err)
+	}
+	defer windows.CertFreeCertificateContext(cert)
+
+	name := make([]uint16, 256)
+	n := windows.CertGetNameString(cert, windows.CERT_NAME_SIMPLE_DISPLAY_TYPE, 0, nil, &name[0], uint32(len(name)))
+	thumbprint := sha1.Sum(unsafe.Slice(cert.EncodedCert, cert.Length))
+	return Signer{
+		Subject:    windows.UTF16ToString(name[:n]),
+		Thumbprint: strings.ToUpper(hex.EncodeToString(thumbprint[:])),
+	}, nil
+}
+
+// winVerifyTrust checks that the file's embedded signature is valid and
+// chains to a trusted root, without showing any UI.
+func winVerifyTrust(path *uint16) error {
+	fileInfo := &windows.WinTrustFileInfo{
+		Size:     uint32(unsafe.Sizeof(windows.WinTrustFileInfo{})),
+		FilePath: path,
+	}
+	data := &windows.WinTrustData{
+		Size:                            uint32(unsafe.Sizeof(windows.WinTrustData{})),
+		UIChoice:                        windows.WTD_UI_NONE,
+		RevocationChecks:                windows.WTD_REVOKE_WHOLECHAIN,
+		UnionChoice:                     windows.WTD_CHOICE_FILE,
+		StateAction:                     windows.WTD_STATEACTION_VERIFY,
+		FileOrCatalogOrBlobOrSgnrOrCert: unsafe.Pointer(fileInfo),
+	}
+	verifyErr := windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, data)
+	data.StateAction = windows.WTD_STATEACTION_CLOSE
+	windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, data)
+	if verifyErr != nil {
+		return fmt.Errorf("signature is not valid: %w", verifyErr)
+	}
+	return nil
+}
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"os"
 	"strings"
 	"time"
 
+	"claude-code-installer/internal/downloader"
 	"claude-code-installer/internal/httputil"
+	"claude-code-installer/internal/version"
 )
 
 const (
@@ -23,6 +27,8 @@ const (
 	updateCheckTimeout = 15 * time.Second
 	// maxAPIResponseSize is the maximum size of API responses to prevent memory exhaustion.
 	maxAPIResponseSize = 1 * 1024 * 1024 // 1MB
+	// updateDownloadTimeout is the timeout for downloading an update asset.
+	updateDownloadTimeout = 10 * time.Minute
 )
 
 // UpdateInfo contains information about available updates.
@@ -55,6 +61,14 @@ type GitHubAsset struct {
 type UpdateChecker struct {
 	ctx        context.Context
 	httpClient *http.Client
+	// minTLSVersion is the lowest TLS version accepted; 0 uses TLS 1.2.
+	minTLSVersion uint16
+	// preferIPv4 makes update checks and downloads connect over IPv4 only.
+	preferIPv4 bool
+	// signerSubject and signerThumbprints identify who downloaded updates
+	// must be signed by; see SetExpectedSigner.
+	signerSubject     string
+	signerThumbprints []string
 }
 
 // NewUpdateChecker creates a new UpdateChecker instance with context support.
@@ -64,10 +78,30 @@ func NewUpdateChecker(ctx context.Context) *UpdateChecker {
 		httpClient: &http.Client{
 			Timeout:       updateCheckTimeout,
 			CheckRedirect: httputil.NewTrustedCheckRedirect(httputil.GitHubTrustedHosts()),
+			Transport:     httputil.DefaultTransport(),
 		},
+		signerSubject: DefaultPublisher,
 	}
 }
 
+// SetMinTLSVersion configures the lowest TLS version, a crypto/tls VersionTLS
+// constant, accepted for update checks and downloads. The default is TLS 1.2.
+func (uc *UpdateChecker) SetMinTLSVersion(v uint16) error {
+	if err := httputil.ValidateMinTLSVersion(v); err != nil {
+		return err
+	}
+	uc.minTLSVersion = v
+	uc.httpClient.Transport = httputil.TransportFor(v, uc.preferIPv4)
+	return nil
+}
+
+// SetPreferIPv4 makes update checks and downloads connect over IPv4 only, for
+// networks where hosts resolve to IPv6 addresses but IPv6 traffic is dropped.
+func (uc *UpdateChecker) SetPreferIPv4(prefer bool) {
+	uc.preferIPv4 = prefer
+	uc.httpClient.Transport = httputil.TransportFor(uc.minTLSVersion, prefer)
+}
+
 // CheckForUpdate checks if a newer version of the application is available.
 func (uc *UpdateChecker) CheckForUpdate(currentVersion string) (*UpdateInfo, error) {
 	latestVersion, downloadURL, err := uc.GetLatestRelease()
@@ -117,19 +151,105 @@ func (uc *UpdateChecker) GetLatestRelease() (string, string, error) {
 		return "", "", fmt.Errorf("failed to parse release response: %w", err)
 	}
 
-	version := cleanVersion(release.TagName)
+	latest, err := version.Validate(release.TagName)
+	if err != nil {
+		return "", "", fmt.Errorf("latest release has an invalid version tag: %w", err)
+	}
 
 	// Find Windows installer asset
 	downloadURL := release.HTMLURL
-	for _, asset := range release.Assets {
+	if asset, ok := selectWindowsAsset(release.Assets); ok {
+		downloadURL = asset.BrowserDownloadURL
+	}
+
+	return latest, downloadURL, nil
+}
+
+// windowsInstallerContentTypes are the content types GitHub reports for
+// Windows executables and MSI packages.
+var windowsInstallerContentTypes = map[string]bool{
+	"application/x-msdownload":                      true,
+	"application/x-msi":                             true,
+	"application/x-ms-installer":                    true,
+	"application/x-dosexec":                         true,
+	"application/vnd.microsoft.portable-executable": true,
+}
+
+// IsWindowsInstallerContentType reports whether contentType, as reported for
+// a GitHub release asset, identifies a Windows executable or MSI package.
+// Generic types such as application/octet-stream do not match.
+func IsWindowsInstallerContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return windowsInstallerContentTypes[strings.ToLower(strings.TrimSpace(mediaType))]
+}
+
+// selectWindowsAsset picks the Windows installer among assets, preferring
+// an installer content type and falling back to file name heuristics for
+// assets uploaded with a generic content type.
+func selectWindowsAsset(assets []GitHubAsset) (GitHubAsset, bool) {
+	for _, asset := range assets {
+		if IsWindowsInstallerContentType(asset.ContentType) {
+			return asset, true
+		}
+	}
+	for _, asset := range assets {
 		name := strings.ToLower(asset.Name)
 		if strings.Contains(name, "windows") || strings.HasSuffix(name, ".exe") || strings.HasSuffix(name, ".msi") {
-			downloadURL = asset.BrowserDownloadURL
-			break
+			return asset, true
+		}
+	}
+	return GitHubAsset{}, false
+}
+
+// DownloadUpdate downloads the update asset at downloadURL to destPath.
+// Only HTTPS URLs on trusted GitHub hosts are accepted. If expectedSHA256 is
+// non-empty, the downloaded file is verified against it. The file must also
+// be Authenticode-signed by the expected publisher (see SetExpectedSigner);
+// otherwise it is deleted and an error wrapping ErrUntrustedSignature is
+// returned, so a compromised release or intercepted download is never run.
+func (uc *UpdateChecker) DownloadUpdate(downloadURL, destPath, expectedSHA256 string, onProgress func(bytesRead, totalSize int64)) (*downloader.Result, error) {
+	if err := validateDownloadURL(downloadURL); err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Timeout:       updateDownloadTimeout,
+		CheckRedirect: httputil.NewTrustedCheckRedirect(httputil.GitHubTrustedHosts()),
+		Transport:     httputil.TransportFor(uc.minTLSVersion, uc.preferIPv4),
+	}
+
+	d := downloader.New(client, onProgress)
+	d.ExpectedMagic = downloader.MagicForFilename(destPath)
+	result, err := d.Download(uc.ctx, downloadURL, destPath, expectedSHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download update: %w", err)
+	}
+	if _, err := uc.VerifyUpdateSignature(destPath); err != nil {
+		os.Remove(destPath)
+		return nil, fmt.Errorf("refusing update: %w", err)
+	}
+	return result, nil
+}
+
+// validateDownloadURL ensures an update download URL uses HTTPS and a trusted GitHub host.
+func validateDownloadURL(rawURL string) error {
+	parsedURL, err := neturl.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid download URL: %w", err)
+	}
+
+	if parsedURL.Scheme != "https" {
+		return fmt.Errorf("download URL must use HTTPS")
+	}
+
+	host := parsedURL.Hostname()
+	for _, trusted := range httputil.GitHubTrustedHosts() {
+		if host == trusted {
+			return nil
 		}
 	}
 
-	return version, downloadURL, nil
+	return fmt.Errorf("download URL host %q is not trusted", host)
 }
 
 // cleanVersion removes common prefixes from version strings.
@@ -140,6 +260,46 @@ func cleanVersion(version string) string {
 	return version
 }
 
+// CompareVersions compares two version strings after removing "v" prefixes.
+// Returns -1 if a < b, 0 if a == b, 1 if a > b.
+func CompareVersions(a, b string) int {
+	return compareVersions(cleanVersion(a), cleanVersion(b))
+}
+
+// Update types returned by ClassifyUpdate.
+const (
+	UpdateNone  = "none"
+	UpdatePatch = "patch"
+	UpdateMinor = "minor"
+	UpdateMajor = "major"
+)
+
+// ClassifyUpdate reports how large the step from current to latest is: one of
+// UpdateMajor, UpdateMinor, UpdatePatch, or UpdateNone when latest is not newer.
+func ClassifyUpdate(current, latest string) string {
+	current, latest = cleanVersion(current), cleanVersion(latest)
+	if compareVersions(current, latest) >= 0 {
+		return UpdateNone
+	}
+
+	currentParts := parseVersionParts(current)
+	latestParts := parseVersionParts(latest)
+	part := func(parts []int, idx int) int {
+		if idx < len(parts) {
+			return parts[idx]
+		}
+		return 0
+	}
+	switch {
+	case part(latestParts, 0) != part(currentParts, 0):
+		return UpdateMajor
+	case part(latestParts, 1) != part(currentParts, 1):
+		return UpdateMinor
+	default:
+		return UpdatePatch
+	}
+}
+
 // compareVersions compares two semantic version strings.
 // Returns -1 if a < b, 0 if a == b, 1 if a > b.
 func compareVersions(a, b string) int {
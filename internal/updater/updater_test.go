@@ -1,7 +1,12 @@
 package updater
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"testing"
+
+	"claude-code-installer/internal/httputil"
 )
 
 func TestCleanVersion(t *testing.T) {
@@ -81,3 +86,182 @@ func TestCompareVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestCompareVersions_Exported(t *testing.T) {
+	if CompareVersions("v1.0.0", "1.0.1") != -1 {
+		t.Error("expected v1.0.0 < 1.0.1")
+	}
+	if CompareVersions("1.0.3 (Claude Code)", "1.0.3") != 0 {
+		t.Error("expected trailing text after the patch version to be ignored")
+	}
+}
+
+func TestSatisfiesRange(t *testing.T) {
+	tests := []struct {
+		version string
+		rng     string
+		want    bool
+	}{
+		{"18.0.0", ">=18.0.0", true},
+		{"v16.20.2", ">=18.0.0", false},
+		{"18.19.0", ">= 18", true},
+		{"20.1.0", "^18.17.0 || >=20", true},
+		{"19.0.0", "^18.17.0 || >=20", false},
+		{"18.18.2", "~18.17.0", false},
+		{"18.17.9", "~18.17.0", true},
+		{"18.5.0", "18.x", true},
+		{"19.0.0", "18", false},
+		{"0.2.5", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"16.5.0", "14.0.0 - 16.5.0", true},
+		{"22.13.1", "*", true},
+		{"22.13.1", ">=18 <22", false},
+	}
+
+	for _, tt := range tests {
+		got, err := SatisfiesRange(tt.version, tt.rng)
+		if err != nil {
+			t.Errorf("SatisfiesRange(%q, %q) unexpected error: %v", tt.version, tt.rng, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("SatisfiesRange(%q, %q) = %v, want %v", tt.version, tt.rng, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyUpdate(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            string
+	}{
+		{"1.0.17", "1.0.18", UpdatePatch},
+		{"1.0.17", "1.1.0", UpdateMinor},
+		{"v1.9.9", "2.0.0", UpdateMajor},
+		{"1.0", "1.0.1", UpdatePatch},
+		{"1.0.18", "1.0.18", UpdateNone},
+		{"2.0.0", "1.9.9", UpdateNone},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyUpdate(tt.current, tt.latest); got != tt.want {
+			t.Errorf("ClassifyUpdate(%q, %q) = %q, want %q", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestSelectWindowsAsset(t *testing.T) {
+	tests := []struct {
+		name   string
+		assets []GitHubAsset
+		want   string
+		wantOK bool
+	}{
+		{
+			name: "content type preferred over name",
+			assets: []GitHubAsset{
+				{Name: "installer-windows.zip", ContentType: "application/zip", BrowserDownloadURL: "zip"},
+				{Name: "setup", ContentType: "application/x-msdownload", BrowserDownloadURL: "exe"},
+			},
+			want:   "exe",
+			wantOK: true,
+		},
+		{
+			name: "content type with parameters",
+			assets: []GitHubAsset{
+				{Name: "setup", ContentType: "Application/X-MSI; charset=binary", BrowserDownloadURL: "msi"},
+			},
+			want:   "msi",
+			wantOK: true,
+		},
+		{
+			name: "generic content type falls back to name",
+			assets: []GitHubAsset{
+				{Name: "checksums.txt", ContentType: "text/plain", BrowserDownloadURL: "txt"},
+				{Name: "installer.exe", ContentType: "application/octet-stream", BrowserDownloadURL: "exe"},
+			},
+			want:   "exe",
+			wantOK: true,
+		},
+		{
+			name:   "no installer",
+			assets: []GitHubAsset{{Name: "source.tar.gz", ContentType: "application/gzip"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := selectWindowsAsset(tt.assets)
+			if ok != tt.wantOK || got.BrowserDownloadURL != tt.want {
+				t.Errorf("selectWindowsAsset() = %q, %v, want %q, %v", got.BrowserDownloadURL, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckSigner(t *testing.T) {
+	const thumbprint = "0123456789ABCDEF0123456789ABCDEF01234567"
+	const publisher = "Example Ltd"
+	signer := Signer{Subject: publisher, Thumbprint: thumbprint}
+
+	tests := []struct {
+		name        string
+		signer      Signer
+		thumbprints []string
+		wantErr     bool
+	}{
+		{"publisher without pinning", signer, nil, false},
+		{"pinned thumbprint", signer, []string{thumbprint}, false},
+		{"other publisher", Signer{Subject: "Evil Corp", Thumbprint: thumbprint}, nil, true},
+		{"unpinned certificate", signer, []string{"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSigner(tt.signer, publisher, tt.thumbprints)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkSigner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrUntrustedSignature) {
+				t.Errorf("checkSigner() error = %v, want ErrUntrustedSignature", err)
+			}
+		})
+	}
+}
+
+func TestCheckSignerWithoutPublisher(t *testing.T) {
+	// Builds without a publisher refuse every update rather than trusting any signer
+	if err := checkSigner(Signer{Subject: "Example Ltd"}, "", nil); !errors.Is(err, ErrUntrustedSignature) {
+		t.Errorf("checkSigner() error = %v, want ErrUntrustedSignature", err)
+	}
+}
+
+func TestSetExpectedSigner(t *testing.T) {
+	uc := NewUpdateChecker(context.Background())
+	if err := uc.SetExpectedSigner("Example Ltd", []string{"01 23 45 67 89 ab cd ef 01 23 45 67 89 ab cd ef 01 23 45 67"}); err != nil {
+		t.Fatalf("SetExpectedSigner() error = %v", err)
+	}
+	if uc.signerThumbprints[0] != "0123456789ABCDEF0123456789ABCDEF01234567" {
+		t.Errorf("thumbprint = %q, want normalized upper-case hex", uc.signerThumbprints[0])
+	}
+	if err := uc.SetExpectedSigner("", nil); err == nil {
+		t.Error("SetExpectedSigner(\"\") = nil, want error")
+	}
+	if err := uc.SetExpectedSigner("Example Ltd", []string{"not-a-thumbprint"}); err == nil {
+		t.Error("SetExpectedSigner() accepted an invalid thumbprint")
+	}
+}
+
+func TestSetPreferIPv4(t *testing.T) {
+	uc := NewUpdateChecker(context.Background())
+	uc.SetPreferIPv4(true)
+	if err := uc.SetMinTLSVersion(tls.VersionTLS13); err != nil {
+		t.Fatalf("SetMinTLSVersion() error = %v", err)
+	}
+	// Each setting keeps the other
+	if uc.httpClient.Transport != httputil.TransportFor(tls.VersionTLS13, true) {
+		t.Error("transport does not honor both the TLS minimum and the IPv4 preference")
+	}
+	uc.SetPreferIPv4(false)
+	if uc.httpClient.Transport != httputil.TransportFor(tls.VersionTLS13, false) {
+		t.Error("transport still prefers IPv4 after SetPreferIPv4(false)")
+	}
+}
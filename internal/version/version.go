@@ -0,0 +1,46 @@
+// Package version validates version strings supplied by users before they are
+// passed to package managers or used to build download URLs.
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxLength caps the accepted length of a version string.
+const maxLength = 64
+
+// shellMetacharacters are rejected outright so a version can never alter the
+// meaning of a command line it is interpolated into.
+const shellMetacharacters = "&|;<>()$`\\\"' \t\r\n*?[]{}!~^%=,"
+
+// semverPattern matches MAJOR[.MINOR[.PATCH]] with an optional pre-release and
+// build suffix, such as "20", "20.11", "20.11.1" or "1.0.0-beta.2+build.5".
+var semverPattern = regexp.MustCompile(`^(0|[1-9][0-9]*)(\.(0|[1-9][0-9]*)){0,2}(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
+// Validate checks that s is a plain version number safe to pass to an install
+// command and returns it normalized: surrounding whitespace and a leading "v"
+// are removed. Inputs containing shell metacharacters or path separators, or
+// that are not shaped like a semantic version, are rejected.
+func Validate(s string) (string, error) {
+	normalized := strings.TrimSpace(s)
+	normalized = strings.TrimPrefix(strings.TrimPrefix(normalized, "v"), "V")
+
+	if normalized == "" {
+		return "", fmt.Errorf("version must not be empty")
+	}
+	if len(normalized) > maxLength {
+		return "", fmt.Errorf("version is too long (%d characters, maximum %d)", len(normalized), maxLength)
+	}
+	if strings.ContainsAny(normalized, `/\`) || strings.Contains(normalized, "..") {
+		return "", fmt.Errorf("invalid version %q: must not contain path separators", s)
+	}
+	if strings.ContainsAny(normalized, shellMetacharacters) {
+		return "", fmt.Errorf("invalid version %q: contains disallowed characters", s)
+	}
+	if !semverPattern.MatchString(normalized) {
+		return "", fmt.Errorf("invalid version %q: expected a version such as 20.11.1", s)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,45 @@
+package version
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"20.11.1", "20.11.1", false},
+		{"v20.11.1", "20.11.1", false},
+		{" V2.47.1 ", "2.47.1", false},
+		{"20", "20", false},
+		{"20.11", "20.11", false},
+		{"1.0.0-beta.2", "1.0.0-beta.2", false},
+		{"1.0.0-rc.1+build.5", "1.0.0-rc.1+build.5", false},
+		{"", "", true},
+		{"v", "", true},
+		{"latest", "", true},
+		{"1.2.3.4", "", true},
+		{"01.2.3", "", true},
+		{"1.2.3; rm -rf /", "", true},
+		{"1.2.3 && calc", "", true},
+		{"$(whoami)", "", true},
+		{"`id`", "", true},
+		{"../../etc/passwd", "", true},
+		{`..\..\Windows`, "", true},
+		{"1.2.3|more", "", true},
+		{"1.2.3\n", "1.2.3", false},
+		{"1.2.3-", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Validate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Validate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"embed"
 	"log"
+	"os"
+
+	"claude-code-installer/internal/elevation"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -14,6 +17,11 @@ import (
 var assets embed.FS
 
 func main() {
+	// Privileged sub-operations are run by relaunching this executable elevated
+	if handled, exitCode := elevation.HandleCommandLine(os.Args[1:]); handled {
+		os.Exit(exitCode)
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 
@@ -29,6 +37,7 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 26, G: 26, B: 46, A: 1},
 		OnStartup:        app.startup,
+		OnShutdown:       app.shutdown,
 		Frameless:        false,
 		StartHidden:      false,
 		Bind: []interface{}{
@@ -0,0 +1,16 @@
+#!/usr/bin/env sh
+# Refreshes the embedded Node.js release signing keys from the
+# nodejs/release-keys repository. Review the resulting diff before committing.
+set -eu
+
+repo="https://github.com/nodejs/release-keys"
+dest="$(dirname "$0")/../internal/nodekeys/keys"
+work="$(mktemp -d)"
+trap 'rm -rf "$work"' EXIT
+
+git clone --depth 1 "$repo" "$work/release-keys"
+
+find "$dest" -name '*.asc' -delete
+cp "$work"/release-keys/keys/*.asc "$dest"/
+
+echo "Updated $(ls "$dest"/*.asc | wc -l) keys in $dest"